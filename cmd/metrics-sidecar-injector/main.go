@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/injector"
+	"metrics-sidecar/pkg/logger"
+)
+
+func main() {
+	// 加载配置前先按MESSAGE_LANG和系统语言环境选择语言，使配置错误也使用该语言
+	i18n.SetLanguage(i18n.Detect(os.Getenv("MESSAGE_LANG")))
+
+	// 加载注入器配置，配置无效时一次性列出所有问题后退出
+	cfg, err := injector.LoadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("main.config_failed", err))
+		os.Exit(1)
+	}
+
+	// 按配置的消息语言和日志设置初始化日志系统
+	i18n.SetLanguage(cfg.Log.MessageLanguage())
+	logger.Setup(cfg.Log)
+	log := logger.GetLogger("main")
+
+	// 设置HTTP路由
+	mux := http.NewServeMux()
+	mux.Handle("/mutate", injector.NewWebhookHandler(injector.NewInjector(cfg)))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+
+	server := &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	// 创建信号监听通道
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	// 在独立的goroutine中启动HTTPS服务器
+	go func() {
+		log.WithField("port", cfg.Port).WithField("image", cfg.SidecarImage).Info(i18n.T("main.injector_started"))
+		if err := server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile); err != nil && err != http.ErrServerClosed {
+			logger.Fatal(err, i18n.T("main.https_failed"))
+		}
+	}()
+
+	// 等待终止信号
+	<-stop
+	logger.ShutdownInfo(i18n.T("main.shutting_down"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Fatal(err, i18n.T("main.shutdown_failed"))
+	}
+
+	logger.ShutdownInfo(i18n.T("main.stopped"))
+}
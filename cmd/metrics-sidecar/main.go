@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
@@ -12,16 +13,27 @@ import (
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"metrics-sidecar/pkg/agentcheck"
 	"metrics-sidecar/pkg/config"
+	"metrics-sidecar/pkg/endpointslice"
+	"metrics-sidecar/pkg/grpchealth"
 	"metrics-sidecar/pkg/handlers"
+	"metrics-sidecar/pkg/i18n"
 	"metrics-sidecar/pkg/k8s"
+	"metrics-sidecar/pkg/loadreport"
 	"metrics-sidecar/pkg/logger"
 	"metrics-sidecar/pkg/metrics"
+	"metrics-sidecar/pkg/podstatus"
+	"metrics-sidecar/pkg/policy"
+	"metrics-sidecar/pkg/reload"
+	"metrics-sidecar/pkg/statsd"
+	"metrics-sidecar/pkg/telemetry"
 )
 
 // 自定义日志格式的HTTP服务器
 type loggingHandler struct {
-	handler http.Handler
+	handler    http.Handler
+	debugPaths map[string]bool // 访问日志只在Debug级别输出的路径
 }
 
 func (h loggingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -31,15 +43,24 @@ func (h loggingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.handler.ServeHTTP(w, r)
 
 	// 记录请求完成后的日志
-	logger.HTTPRequestCompleted(r.Method, r.URL.Path, r.RemoteAddr, time.Since(start))
+	level := logrus.InfoLevel
+	if h.debugPaths[r.URL.Path] {
+		level = logrus.DebugLevel
+	}
+	logger.HTTPRequestCompleted(level, r.Method, r.URL.Path, r.RemoteAddr, time.Since(start))
 }
 
-// setupHTTPServer 配置HTTP服务器和路由
-func setupHTTPServer(healthHandler http.Handler, metricsHandler http.Handler, port string) *http.Server {
+// setupHTTPServer 配置HTTP服务器和路由，routes为基础接口之外的其他路由。
+// stateChangesOnly为true时探针请求的访问日志降为Debug
+func setupHTTPServer(healthHandler, metricsHandler, weightHandler http.Handler, routes map[string]http.Handler, port string, stateChangesOnly bool) *http.Server {
 	// 设置HTTP路由
 	mux := http.NewServeMux()
 	mux.Handle("/healthz", healthHandler)
 	mux.Handle("/metrics", metricsHandler)
+	mux.Handle("/weight", weightHandler)
+	for pattern, handler := range routes {
+		mux.Handle(pattern, handler)
+	}
 
 	// 添加首页路由，提供基本信息
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -49,11 +70,14 @@ func setupHTTPServer(healthHandler http.Handler, metricsHandler http.Handler, po
 		}
 		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("指标采集服务正在运行\n\n可用接口:\n- /healthz: 健康检查\n- /metrics: 资源指标"))
+		w.Write([]byte(i18n.T("main.index")))
 	})
 
 	// 创建带日志的HTTP服务器
 	loggedRouter := loggingHandler{handler: mux}
+	if stateChangesOnly {
+		loggedRouter.debugPaths = map[string]bool{"/healthz": true}
+	}
 
 	// 创建HTTP服务器
 	server := &http.Server{
@@ -72,21 +96,39 @@ func checkMetricsAPIAvailability(k8sClient *k8s.Client) error {
 	defer cancel()
 
 	log := logger.GetLogger("metrics-api")
-	log.Info("正在检查metrics.k8s.io API是否可用...")
+	log.Info(i18n.T("main.metrics_api_checking"))
 
 	// 尝试列出所有节点的指标，验证API是否可用
 	_, err := k8sClient.MetricsClient.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{Limit: 1})
 	if err != nil {
-		return fmt.Errorf("metrics.k8s.io API不可用: %v", err)
+		return i18n.Errorf("main.metrics_api_unavailable", err)
 	}
 
-	log.Info("metrics.k8s.io API检查通过")
+	log.Info(i18n.T("main.metrics_api_available"))
 	return nil
 }
 
+var (
+	// 命令行参数，只在此处注册，配置热加载不会重复解析
+	kubeconfigFlag = flag.String("kubeconfig", "", "(optional) absolute path to the kubeconfig file, ignored when running in cluster")
+	configFileFlag = flag.String("config", "", "(optional) path to the YAML/JSON config file, overrides CONFIG_FILE")
+)
+
 func main() {
-	// 创建配置
-	cfg := config.NewConfig()
+	flag.Parse()
+
+	// 加载配置前先按MESSAGE_LANG和系统语言环境选择语言，使配置错误也使用该语言
+	i18n.SetLanguage(i18n.Detect(os.Getenv("MESSAGE_LANG")))
+
+	// 创建配置，配置无效时一次性列出所有问题后退出
+	cfg, err := config.NewConfig(*configFileFlag, *kubeconfigFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("main.config_failed", err))
+		os.Exit(1)
+	}
+
+	// 日志和响应中的消息按配置的语言输出，原因代码不变
+	i18n.SetLanguage(cfg.MessageLanguage())
 
 	// 初始化日志系统
 	logger.Setup(cfg)
@@ -94,36 +136,218 @@ func main() {
 	// 显示启动信息
 	logger.StartupInfo(cfg)
 
+	// OpenTelemetry资源属性，追踪和OTLP指标共用
+	otelResource := telemetry.NewResource(telemetry.ResourceAttributes{
+		Namespace:  cfg.Namespace,
+		Deployment: cfg.DeploymentName,
+		Pod:        cfg.PodName,
+		Container:  cfg.ContainerName,
+	})
+
+	// 通过OTLP导出/healthz和/metrics请求的追踪
+	shutdownTracing := func(context.Context) error { return nil }
+	if cfg.TracingEnabled {
+		shutdownTracing, err = telemetry.SetupTracing(context.Background(), cfg.OTLPEndpoint, cfg.TracingSampleRatio, otelResource)
+		if err != nil {
+			logger.Fatal(err, i18n.T("main.tracing_failed"))
+		}
+	}
+
+	// sidecar自身的指标，包括Kubernetes API调用的耗时、结果和限流等待时间
+	registry := telemetry.NewRegistry()
+	kubeClientMetrics := telemetry.NewKubeClientMetrics(registry)
+	kubeClientMetrics.RegisterClientGoHooks()
+
 	// 创建K8s客户端
 	log := logger.GetLogger("main")
-	log.Info("正在创建Kubernetes客户端...")
-	k8sClient, err := k8s.NewClient(cfg)
+	log.Info(i18n.T("main.creating_client"))
+	k8sClient, err := k8s.NewClient(cfg, kubeClientMetrics.WrapTransport)
 	if err != nil {
 		// 严重错误：在初始化时无法获取容器资源限制会导致panic
-		logger.Fatal(err, "致命错误")
+		logger.Fatal(err, i18n.T("main.fatal"))
 	}
-	log.Info("Kubernetes客户端创建成功")
+	log.Info(i18n.T("main.client_created"))
 
 	// 检查metrics.k8s.io API是否可用
 	if err := checkMetricsAPIAvailability(k8sClient); err != nil {
-		logrus.WithError(err).Warn("警告")
-		logger.Fatal(nil, "metrics-server未安装或不可用，程序无法继续运行")
+		logrus.WithError(err).Warn(i18n.T("main.warning"))
+		logger.Fatal(nil, i18n.T("main.metrics_server_missing"))
 	}
 
 	// 创建度量指标收集器
-	log.Info("正在创建指标收集器...")
+	log.Info(i18n.T("main.creating_collector"))
 	metricsCollector := metrics.NewMetricsCollector(k8sClient.MetricsClient, cfg)
-	log.Info("指标收集器创建成功")
+	log.Info(i18n.T("main.collector_created"))
 
 	// 创建HTTP处理器
-	log.Info("正在创建HTTP处理器...")
+	log.Info(i18n.T("main.creating_handlers"))
 	healthHandler := handlers.NewHealthHandler(k8sClient, metricsCollector, cfg)
 	metricsHandler := handlers.NewMetricsHandler(k8sClient, metricsCollector, cfg, healthHandler)
-	log.Info("HTTP处理器创建成功")
+	weightHandler := handlers.NewWeightHandler(healthHandler)
+	log.Info(i18n.T("main.handlers_created"))
+
+	// 之后的每条日志都带上最近一次健康检查状态
+	healthHandler.AddListener(func(_, current *handlers.Decision) {
+		logger.SetStatus(current.Status)
+	})
+
+	// 后台任务的上下文，收到终止信号时取消
+	runCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	// 分层配置: 配置文件和环境变量为基础，依次叠加SheddingPolicy和Pod注解，合并结果交给健康检查处理器
+	layers := policy.NewLayered(cfg, func(effective *config.Config) {
+		for _, change := range config.Diff(healthHandler.CurrentConfig(), effective) {
+			log.WithField("change", change).Debug(i18n.T("main.config_updated"))
+		}
+		healthHandler.SetConfig(effective)
+	}, policy.OverlaySheddingPolicy, policy.OverlayAnnotations)
+
+	// 使用配置文件时监听其变化，热更新阈值和策略
+	if cfg.ConfigFile != "" {
+		watcher := reload.NewWatcher(cfg.ConfigFile, layers)
+		go func() {
+			if err := watcher.Run(runCtx); err != nil {
+				log.WithError(err).Error(i18n.T("main.reload_unavailable"))
+			}
+		}()
+	}
+
+	// 监听当前Pod的注解，按工作负载覆盖阈值和策略
+	if cfg.PodAnnotationsEnabled {
+		go policy.NewAnnotationSource(k8sClient, layers).Run(runCtx)
+	}
+
+	// 监听命名空间中的SheddingPolicy，并把判定结果上报到其status
+	if cfg.SheddingPolicyEnabled {
+		source := policy.NewSheddingPolicySource(k8sClient, layers)
+		healthHandler.AddListener(func(_, current *handlers.Decision) {
+			source.ReportStatus(current.Status, current.Shedding())
+		})
+		go source.Run(runCtx)
+	}
+
+	// 在状态转换时于当前Pod上记录Kubernetes事件
+	if cfg.EventsEnabled {
+		if err := k8sClient.StartEventRecorder(runCtx); err != nil {
+			log.WithError(err).Warn(i18n.T("main.events_failed"))
+		} else {
+			defer k8sClient.StopEventRecorder()
+			healthHandler.AddListener(handlers.NewEventListener(k8sClient))
+		}
+	}
+
+	// 将状态写入Pod的状态条件和标签
+	if cfg.PodConditionEnabled || cfg.PodStateLabel != "" {
+		reporter := podstatus.NewReporter(k8sClient, cfg.PodConditionEnabled, cfg.PodStateLabel)
+		healthHandler.AddListener(func(_, current *handlers.Decision) {
+			reporter.Report(current.Status, current.Shedding(), current.Message)
+		})
+		go reporter.Run(runCtx)
+	}
+
+	// 直接在sidecar独占的EndpointSlice中设置当前Pod端点的serving/terminating状态
+	var sliceController *endpointslice.Controller
+	if cfg.EndpointSliceService != "" {
+		sliceController = endpointslice.NewController(k8sClient, cfg.EndpointSliceService, cfg.EndpointSlicePorts)
+		healthHandler.AddListener(func(_, current *handlers.Decision) {
+			sliceController.Report(current.Status != handlers.StatusNotReady && !current.Shedding())
+		})
+		go sliceController.Run(runCtx)
+	}
+
+	// 提供标准的gRPC健康检查服务，Watch推送状态变化
+	var grpcHealth *grpchealth.Server
+	if cfg.GRPCHealthPort != "" {
+		grpcHealth = grpchealth.NewServer(cfg.GRPCHealthPort, cfg.GRPCHealthService, healthHandler)
+		healthHandler.AddListener(grpcHealth.Listener())
+	}
+
+	// 基础接口之外的HTTP路由
+	routes := make(map[string]http.Handler)
+
+	// 决策指标与Kubernetes API调用指标一起以Prometheus格式输出
+	healthHandler.AddListener(handlers.NewDecisionMetrics(registry).Listener())
+	routes["/metrics/prometheus"] = telemetry.Handler(registry)
+
+	// 按间隔通过OTLP推送资源使用和决策指标，供没有Prometheus的集群使用
+	shutdownOTLPMetrics := func(context.Context) error { return nil }
+	if cfg.OTLPMetricsEnabled {
+		provider, err := telemetry.SetupMetrics(context.Background(), cfg.OTLPEndpoint, cfg.OTLPMetricsInterval, otelResource)
+		if err != nil {
+			logger.Fatal(err, i18n.T("main.otlp_metrics_failed"))
+		}
+		otlpMetrics, err := handlers.NewOTLPMetrics(provider.Meter("metrics-sidecar/pkg/handlers"), healthHandler)
+		if err != nil {
+			logger.Fatal(err, i18n.T("main.otlp_instruments_failed"))
+		}
+		healthHandler.AddListener(otlpMetrics.Listener())
+		shutdownOTLPMetrics = provider.Shutdown
+	}
+
+	// 按间隔以DogStatsD格式通过UDP发送资源使用和决策计数，供基于Datadog的监控使用
+	if cfg.StatsDAddress != "" {
+		tags := append(statsd.WorkloadTags(cfg.Namespace, cfg.DeploymentName, cfg.PodName, cfg.ContainerName), cfg.StatsDTags...)
+		emitter := statsd.NewEmitter(cfg.StatsDAddress, cfg.StatsDPrefix, cfg.StatsDFlushInterval, tags)
+		healthHandler.AddListener(emitter.Listener())
+		go func() {
+			if err := emitter.Run(runCtx); err != nil {
+				log.WithError(err).Error(i18n.T("main.statsd_failed"))
+			}
+		}()
+	}
+
+	// 提供ORCA负载报告，OOB流与gRPC健康检查共用端口
+	if cfg.ORCAEnabled {
+		reporter := loadreport.NewReporter()
+		healthHandler.AddListener(reporter.Listener())
+		routes["/load"] = reporter
+		if grpcHealth != nil {
+			grpcHealth.AddService(reporter.Register)
+		} else {
+			log.Warn(i18n.T("main.orca_http_only"))
+		}
+	}
+
+	if grpcHealth != nil {
+		go func() {
+			if err := grpcHealth.Run(runCtx); err != nil {
+				log.WithError(err).Error(i18n.T("main.grpchealth_failed"))
+			}
+		}()
+	}
+
+	// 以上功能不依赖探针请求，需要定期决策
+	if cfg.PodConditionEnabled || cfg.PodStateLabel != "" || cfg.EndpointSliceService != "" ||
+		cfg.GRPCHealthPort != "" || cfg.ORCAEnabled || cfg.OTLPMetricsEnabled || cfg.StatsDAddress != "" {
+		go healthHandler.RunPeriodic(runCtx, cfg.EvaluationInterval)
+	}
+
+	// 通过HAProxy agent-check协议提供状态和权重，供集群外的负载均衡器使用
+	if cfg.AgentCheckPort != "" {
+		go func() {
+			if err := agentcheck.NewServer(cfg.AgentCheckPort, healthHandler).Run(runCtx); err != nil {
+				log.WithError(err).Error(i18n.T("main.agentcheck_failed"))
+			}
+		}()
+	}
+
+	// 在内存中保留最近的决策，通过/debug/decisions查询
+	if cfg.DecisionHistorySize > 0 {
+		history := handlers.NewDecisionHistory(healthHandler, cfg.DecisionHistorySize)
+		healthHandler.AddListener(history.Listener())
+		routes["/debug/decisions"] = history
+	}
+
+	// 通过/debug/loglevel在运行时修改日志级别，不需要重启Pod
+	if cfg.LogLevelEndpoint {
+		routes["/debug/loglevel"] = logger.LevelHandler()
+	}
 
 	// 设置HTTP服务器
-	log.WithField("port", cfg.HttpPort).Info("启动HTTP服务器")
-	server := setupHTTPServer(healthHandler, metricsHandler, cfg.HttpPort)
+	log.WithField("port", cfg.HttpPort).Info(i18n.T("main.http_starting"))
+	server := setupHTTPServer(telemetry.TraceHandler("/healthz", healthHandler),
+		telemetry.TraceHandler("/metrics", metricsHandler), weightHandler, routes, cfg.HttpPort, cfg.LogStateChangesOnly)
 
 	// 创建信号监听通道
 	stop := make(chan os.Signal, 1)
@@ -131,24 +355,40 @@ func main() {
 
 	// 在独立的goroutine中启动服务器
 	go func() {
-		log.Info("指标采集服务已启动，等待请求...")
+		log.Info(i18n.T("main.started"))
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal(err, "HTTP服务器错误")
+			logger.Fatal(err, i18n.T("main.http_failed"))
 		}
 	}()
 
 	// 等待终止信号
 	<-stop
-	logger.ShutdownInfo("收到终止信号，开始优雅关闭...")
+	logger.ShutdownInfo(i18n.T("main.shutting_down"))
+	stopBackground()
 
 	// 创建一个5秒超时的上下文用于优雅关闭
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	// 先将端点标记为terminating，外部Service不再转发新流量
+	if sliceController != nil {
+		if err := sliceController.Terminate(ctx); err != nil {
+			log.WithError(err).Warn(i18n.T("main.endpoint_terminating_failed"))
+		}
+	}
+
 	// 优雅地关闭服务器，等待活跃连接完成
 	if err := server.Shutdown(ctx); err != nil {
-		logger.Fatal(err, "服务器关闭错误")
+		logger.Fatal(err, i18n.T("main.shutdown_failed"))
+	}
+
+	// 导出尚未发送的span，并推送最后一次指标
+	if err := shutdownTracing(ctx); err != nil {
+		log.WithError(err).Warn(i18n.T("main.trace_flush_failed"))
+	}
+	if err := shutdownOTLPMetrics(ctx); err != nil {
+		log.WithError(err).Warn(i18n.T("main.metrics_flush_failed"))
 	}
 
-	logger.ShutdownInfo("服务器已安全关闭")
+	logger.ShutdownInfo(i18n.T("main.stopped"))
 }
@@ -0,0 +1,118 @@
+package agentcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"metrics-sidecar/pkg/handlers"
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/logger"
+)
+
+// 单个连接的处理超时，包括收集指标和写入响应
+const connTimeout = 5 * time.Second
+
+var (
+	// agent-check服务的日志器
+	agentLog = logger.GetLogger("agentcheck")
+)
+
+// DecisionSource 提供决策，通常为*handlers.HealthHandler
+type DecisionSource interface {
+	CachedDecision(ctx context.Context) (*handlers.Decision, error)
+}
+
+// Server 实现HAProxy的agent-check协议：HAProxy定期建立TCP连接，
+// agent写入一行状态后关闭连接。状态与/healthz共享同一个决策
+type Server struct {
+	addr      string
+	decisions DecisionSource
+}
+
+// NewServer 创建agent-check服务
+func NewServer(port string, decisions DecisionSource) *Server {
+	return &Server{
+		addr:      ":" + port,
+		decisions: decisions,
+	}
+}
+
+// Run 监听端口并处理连接，直到ctx结束
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return i18n.Errorf("agentcheck.listen_failed", err)
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve 在已有的监听器上处理连接，直到ctx结束后关闭监听器
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	agentLog.WithField("addr", listener.Addr().String()).Info(i18n.T("agentcheck.started"))
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return err
+			}
+			agentLog.WithError(err).Warn(i18n.T("agentcheck.accept_failed"))
+			continue
+		}
+		go s.handle(ctx, conn)
+	}
+}
+
+// handle 取最近一次决策（过期时重新决策）并写入对应的agent-check响应
+func (s *Server) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(connTimeout))
+
+	ctx, cancel := context.WithTimeout(ctx, connTimeout)
+	defer cancel()
+
+	var response string
+	decision, err := s.decisions.CachedDecision(ctx)
+	if err != nil {
+		agentLog.WithError(err).Error(i18n.T("agentcheck.decision_failed"))
+		response = "ready down#metrics unavailable\n"
+	} else {
+		response = Response(decision)
+	}
+
+	if _, err := conn.Write([]byte(response)); err != nil {
+		agentLog.WithError(err).Warn(i18n.T("agentcheck.write_failed"))
+		return
+	}
+	agentLog.WithFields(logrus.Fields{
+		"remote":   conn.RemoteAddr().String(),
+		"response": response[:len(response)-1],
+	}).Debug(i18n.T("agentcheck.responded"))
+}
+
+// Response 将决策转换为agent-check响应行：
+// 容器未就绪时为down，卸载流量时为drain（不再分配新连接，保留已有连接），
+// 其他状态为up并附带/weight相同的百分比权重。
+// drain会将HAProxy中的服务器置为DRAIN管理状态，只有ready才能解除，因此非卸载状态都带上ready
+func Response(decision *handlers.Decision) string {
+	switch {
+	case decision.Status == handlers.StatusNotReady:
+		return fmt.Sprintf("ready down#%s\n", decision.Status)
+	case decision.Shedding():
+		return "drain\n"
+	default:
+		return fmt.Sprintf("ready up %d%%\n", decision.Weight)
+	}
+}
@@ -0,0 +1,113 @@
+package agentcheck
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"metrics-sidecar/pkg/handlers"
+)
+
+// fakeDecisions 返回固定的决策
+type fakeDecisions struct {
+	mu       sync.Mutex
+	decision *handlers.Decision
+	err      error
+}
+
+func (f *fakeDecisions) CachedDecision(context.Context) (*handlers.Decision, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.decision, f.err
+}
+
+func (f *fakeDecisions) set(decision *handlers.Decision, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.decision, f.err = decision, err
+}
+
+func TestResponse(t *testing.T) {
+	tests := []struct {
+		decision *handlers.Decision
+		expected string
+	}{
+		{&handlers.Decision{Status: handlers.StatusHealthy, Weight: 100}, "ready up 100%\n"},
+		{&handlers.Decision{Status: handlers.StatusResourceOverloadedButKeeping, Weight: 10}, "ready up 10%\n"},
+		{&handlers.Decision{Status: handlers.StatusPodShortage, Weight: 40}, "ready up 40%\n"},
+		{&handlers.Decision{Status: handlers.StatusResourceExhausted}, "drain\n"},
+		{&handlers.Decision{Status: handlers.StatusNotReady}, "ready down#NOT_READY\n"},
+	}
+	for _, tt := range tests {
+		if got := Response(tt.decision); got != tt.expected {
+			t.Errorf("Response(%s) = %q; 期望 %q", tt.decision.Status, got, tt.expected)
+		}
+	}
+}
+
+func TestServeTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	decisions := &fakeDecisions{decision: &handlers.Decision{Status: handlers.StatusHealthy, Weight: 62}}
+	server := NewServer("0", decisions)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, listener) }()
+
+	// 每个连接收到一行响应，随后服务端关闭连接
+	check := func(expected string) {
+		t.Helper()
+		conn, err := net.DialTimeout("tcp", listener.Addr().String(), time.Second)
+		if err != nil {
+			t.Fatalf("连接失败: %v", err)
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		reader := bufio.NewReader(conn)
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("读取响应失败: %v", err)
+		}
+		if line != expected {
+			t.Errorf("响应 = %q; 期望 %q", line, expected)
+		}
+		if _, err := reader.ReadByte(); err != io.EOF {
+			t.Errorf("读取响应后的结果 = %v; 期望服务端关闭连接(EOF)", err)
+		}
+	}
+	check("ready up 62%\n")
+
+	// 卸载流量后恢复时必须带ready，否则HAProxy中的服务器一直处于DRAIN状态
+	decisions.set(&handlers.Decision{Status: handlers.StatusResourceExhausted}, nil)
+	check("drain\n")
+	decisions.set(&handlers.Decision{Status: handlers.StatusHealthy, Weight: 62}, nil)
+	check("ready up 62%\n")
+
+	decisions.set(nil, errors.New("metrics-server不可用"))
+	check("ready down#metrics unavailable\n")
+
+	// ctx结束后停止监听并正常返回
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve()返回错误: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ctx结束后Serve()未返回")
+	}
+	if conn, err := net.DialTimeout("tcp", listener.Addr().String(), time.Second); err == nil {
+		conn.Close()
+		t.Error("关闭后仍能建立连接")
+	}
+}
@@ -1,14 +1,46 @@
 package config
 
 import (
-	"flag"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/probe"
+)
+
+// 资源使用率的计算基准（分母）
+const (
+	BasisLimit           = "limit"            // 容器的resources.limits
+	BasisRequest         = "request"          // 容器的resources.requests
+	BasisNodeAllocatable = "node-allocatable" // Pod所在节点的可分配资源
+	BasisAbsolute        = "absolute"         // 显式配置的绝对值
+)
+
+// 日志格式
+const (
+	LogFormatText   = "text"   // 人类可读的文本，终端中带颜色
+	LogFormatJSON   = "json"   // 每行一个JSON对象
+	LogFormatLogfmt = "logfmt" // 每行一组key=value，不带颜色
+)
+
+// 资源过载判定策略
+const (
+	OverloadPolicyAll = "all" // CPU和内存同时超过阈值才视为过载
+	OverloadPolicyAny = "any" // 任一资源超过阈值即视为过载
 )
 
 // Config 保存应用程序配置
 type Config struct {
+	// 配置文件路径，为空表示未使用配置文件
+	ConfigFile string
+
 	// K8s配置
 	KubeconfigPath  string // kubeconfig文件路径
 	InClusterConfig bool   // 是否使用InCluster配置
@@ -20,13 +52,125 @@ type Config struct {
 	// 资源阈值配置
 	ResourceThresholdMemoryPercent float64 // 内存使用率阈值百分比
 	ResourceThresholdCPUPercent    float64 // CPU使用率阈值百分比
+	ResourceThresholdMemoryMB      int64   // 内存绝对阈值（MB），大于0时替代百分比阈值
+	ResourceThresholdCPUMillicores int64   // CPU绝对阈值（毫核），大于0时替代百分比阈值
+	OverloadPolicy                 string  // 过载判定策略 (all, any)
 	MinimumPodsToKeepPercent       float64 // 最小可用Pod百分比
+	PodAnnotationsEnabled          bool    // 是否读取当前Pod注解中的阈值和策略
+	SheddingPolicyEnabled          bool    // 是否读取命名空间中的SheddingPolicy自定义资源
+
+	// 权重配置，供支持权重的负载均衡器逐步减少流量
+	WeightDrainStartPercent float64 // 资源使用达到阈值的该百分比时开始降低权重
+	WeightMin               int     // 仍在接收流量时的最小权重
+
+	// 资源使用率基准配置
+	CPUBasis         string // CPU使用率的计算基准 (limit, request, node-allocatable, absolute)
+	MemoryBasis      string // 内存使用率的计算基准 (limit, request, node-allocatable, absolute)
+	CPUBasisValue    int64  // absolute基准下的CPU值（毫核）
+	MemoryBasisValue int64  // absolute基准下的内存值（MB）
+
+	// 应用原有的就绪探针，设置后由sidecar代为执行，替代Pod状态中的容器就绪状态
+	AppReadinessProbe *probe.Spec
+
+	// 是否在状态转换时于当前Pod上记录Kubernetes事件
+	EventsEnabled bool
+
+	// Pod状态配置
+	PodConditionEnabled bool          // 是否写入metrics-sidecar.io/NotOverloaded状态条件，配合readinessGates使用
+	PodStateLabel       string        // 写入当前状态的Pod标签名，为空表示不写入
+	EvaluationInterval  time.Duration // 不依赖探针请求的定期决策间隔
+
+	// EndpointSlice直接控制配置，用于没有selector的外部管理Service
+	EndpointSliceService string         // 目标Service名称，为空表示不启用
+	EndpointSlicePorts   []EndpointPort // 写入EndpointSlice的端口
 
 	// HTTP服务配置
 	HttpPort string // HTTP服务端口
 
+	// HAProxy agent-check监听端口，为空表示不启用
+	AgentCheckPort string
+
+	// gRPC健康检查服务配置
+	GRPCHealthPort    string // grpc.health.v1.Health服务端口，为空表示不启用
+	GRPCHealthService string // 除空服务名外还响应的服务名，为空表示只响应空服务名
+
+	// 是否提供ORCA负载报告（gRPC OOB流和endpoint-load-metrics响应头）
+	ORCAEnabled bool
+
+	// 内存中保留的决策历史条数，通过/debug/decisions查询，为0表示不保留
+	DecisionHistorySize int
+
+	// 是否提供/debug/loglevel在运行时修改日志级别，接口没有鉴权，默认关闭
+	LogLevelEndpoint bool
+
+	// OpenTelemetry配置
+	OTLPEndpoint        string        // OTLP/HTTP接收端地址，如http://otel-collector:4318
+	TracingEnabled      bool          // 是否追踪/healthz和/metrics请求
+	TracingSampleRatio  float64       // 追踪的采样比例(0-1)，上游请求已采样时始终采样
+	OTLPMetricsEnabled  bool          // 是否通过OTLP推送资源使用和决策指标
+	OTLPMetricsInterval time.Duration // 推送OTLP指标的间隔
+
+	// StatsD/DogStatsD配置
+	StatsDAddress       string        // UDP接收端地址，如127.0.0.1:8125，为空表示不启用
+	StatsDPrefix        string        // 指标名称前缀
+	StatsDFlushInterval time.Duration // 发送指标的间隔
+	StatsDTags          []string      // 附加到所有指标的标签，如env:prod
+
 	// 日志配置
-	LogLevel string // 日志级别 (debug, info, warn, error)
+	LogLevel           string // 日志级别 (debug, info, warn, error)
+	LogFormat          string // 日志格式 (text, json, logfmt)
+	LogTimestampFormat string // 时间戳格式，Go时间布局或rfc3339、rfc3339nano、none
+	LogCaller          bool   // 是否输出调用者的文件和行号
+
+	// 日志和响应中人类可读消息的语言，LANG格式，如zh、en、en_US.UTF-8，为空时按系统语言环境选择，见MessageLanguage
+	MessageLang string
+
+	// 日志降噪配置
+	LogComponentLevels  map[string]string // 按模块覆盖的日志级别，如health=warn
+	LogStateChangesOnly bool              // 健康检查路径只在状态变化时输出Info日志，其余降为Debug
+	LogSampleBurst      int               // 每个窗口内同一条日志最多输出的次数，0表示不采样
+	LogSampleWindow     time.Duration     // 日志采样的窗口
+}
+
+// EndpointPort EndpointSlice中的一个端口
+type EndpointPort struct {
+	Name     string `json:"name"`
+	Port     int32  `json:"port"`
+	Protocol string `json:"protocol"`
+}
+
+// String 返回"名称:端口/协议"格式的描述
+func (p EndpointPort) String() string {
+	return fmt.Sprintf("%s:%d/%s", p.Name, p.Port, p.Protocol)
+}
+
+// parseEndpointPorts 解析逗号分隔的端口列表，格式为"名称:端口/协议"，
+// 名称和协议可省略，如"http:8080/TCP,9090"
+func parseEndpointPorts(value string) ([]EndpointPort, error) {
+	var ports []EndpointPort
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		port := EndpointPort{Protocol: "TCP"}
+		if name, rest, found := strings.Cut(item, ":"); found {
+			port.Name = name
+			item = rest
+		}
+		if number, protocol, found := strings.Cut(item, "/"); found {
+			port.Protocol = strings.ToUpper(protocol)
+			item = number
+		}
+		number, err := strconv.ParseInt(item, 10, 32)
+		if err != nil {
+			return nil, i18n.Errorf("config.port_not_number", item)
+		}
+		port.Port = int32(number)
+		ports = append(ports, port)
+	}
+	return ports, nil
 }
 
 // 获取环境变量，如果不存在则返回默认值
@@ -37,14 +181,233 @@ func getEnvWithDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
-// 获取环境变量并转换为浮点数
-func getEnvAsFloat(key string, defaultValue float64) float64 {
+// 将Kubernetes数量语法解析为毫核，如"1800m"或"2"
+func parseMilliCores(value string) (int64, error) {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, err
+	}
+	return quantity.MilliValue(), nil
+}
+
+// 将Kubernetes数量语法解析为MB，如"3.5Gi"或"512Mi"
+func parseMegabytes(value string) (int64, error) {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, err
+	}
+	return quantity.Value() / (1024 * 1024), nil
+}
+
+// envLoader 用环境变量覆盖配置，并收集所有解析错误
+type envLoader struct {
+	problems []string
+}
+
+// 读取字符串类型的环境变量
+func (l *envLoader) str(key string, dst *string) {
+	if value, exists := os.LookupEnv(key); exists {
+		*dst = value
+	}
+}
+
+// 读取小写字符串类型的环境变量，用于枚举值
+func (l *envLoader) lower(key string, dst *string) {
+	if value, exists := os.LookupEnv(key); exists {
+		*dst = strings.ToLower(value)
+	}
+}
+
+// 读取浮点数类型的环境变量
+func (l *envLoader) float(key string, dst *float64) {
 	if value, exists := os.LookupEnv(key); exists {
-		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
-			return floatValue
+		floatValue, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			l.problems = append(l.problems, i18n.T("config.env_float", key, value))
+			return
 		}
+		*dst = floatValue
 	}
-	return defaultValue
+}
+
+// 读取整数类型的环境变量
+func (l *envLoader) integer(key string, dst *int) {
+	if value, exists := os.LookupEnv(key); exists {
+		intValue, err := strconv.Atoi(value)
+		if err != nil {
+			l.problems = append(l.problems, i18n.T("config.env_int", key, value))
+			return
+		}
+		*dst = intValue
+	}
+}
+
+// 读取布尔类型的环境变量
+func (l *envLoader) boolean(key string, dst *bool) {
+	if value, exists := os.LookupEnv(key); exists {
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			l.problems = append(l.problems, i18n.T("config.env_bool", key, value))
+			return
+		}
+		*dst = boolValue
+	}
+}
+
+// 读取Kubernetes数量语法的CPU环境变量（毫核）
+func (l *envLoader) milliCores(key string, dst *int64) {
+	if value, exists := os.LookupEnv(key); exists {
+		milliCores, err := parseMilliCores(value)
+		if err != nil {
+			l.problems = append(l.problems, i18n.T("config.env_cpu", key, value, err))
+			return
+		}
+		*dst = milliCores
+	}
+}
+
+// 读取Kubernetes数量语法的内存环境变量（MB）
+func (l *envLoader) megabytes(key string, dst *int64) {
+	if value, exists := os.LookupEnv(key); exists {
+		megabytes, err := parseMegabytes(value)
+		if err != nil {
+			l.problems = append(l.problems, i18n.T("config.env_memory", key, value, err))
+			return
+		}
+		*dst = megabytes
+	}
+}
+
+// 读取时长类型的环境变量，如"10s"
+func (l *envLoader) duration(key string, dst *time.Duration) {
+	if value, exists := os.LookupEnv(key); exists {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			l.problems = append(l.problems, i18n.T("config.env_duration", key, value))
+			return
+		}
+		*dst = d
+	}
+}
+
+// 读取逗号分隔的列表环境变量，忽略空项
+func (l *envLoader) list(key string, dst *[]string) {
+	if value, exists := os.LookupEnv(key); exists {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*dst = items
+	}
+}
+
+// 读取逗号分隔的key=value列表环境变量，如"health=warn,k8s=debug"
+func (l *envLoader) keyValues(key string, dst *map[string]string) {
+	if value, exists := os.LookupEnv(key); exists {
+		pairs := make(map[string]string)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			k, v, found := strings.Cut(item, "=")
+			if !found || strings.TrimSpace(k) == "" {
+				l.problems = append(l.problems, i18n.T("config.env_map", key, item))
+				return
+			}
+			pairs[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+		*dst = pairs
+	}
+}
+
+// 读取EndpointSlice端口列表环境变量
+func (l *envLoader) endpointPorts(key string, dst *[]EndpointPort) {
+	if value, exists := os.LookupEnv(key); exists {
+		ports, err := parseEndpointPorts(value)
+		if err != nil {
+			l.problems = append(l.problems, i18n.T("config.env_invalid", key, value, err))
+			return
+		}
+		*dst = ports
+	}
+}
+
+// 读取JSON格式的探针定义环境变量
+func (l *envLoader) probeSpec(key string, dst **probe.Spec) {
+	if value, exists := os.LookupEnv(key); exists && value != "" {
+		// 与配置文件一样拒绝未知字段，如exec探针
+		decoder := json.NewDecoder(strings.NewReader(value))
+		decoder.DisallowUnknownFields()
+		spec := &probe.Spec{}
+		if err := decoder.Decode(spec); err != nil {
+			l.problems = append(l.problems, i18n.T("config.env_probe", key, err))
+			return
+		}
+		*dst = spec
+	}
+}
+
+// applyEnv 用环境变量覆盖配置，返回解析过程中发现的问题
+func (c *Config) applyEnv() []string {
+	l := &envLoader{}
+	l.str("NAMESPACE", &c.Namespace)
+	l.str("DEPLOYMENT_NAME", &c.DeploymentName)
+	l.str("CONTAINER_NAME", &c.ContainerName)
+	l.str("POD_NAME", &c.PodName)
+	l.float("RESOURCE_THRESHOLD_MEMORY_PERCENT", &c.ResourceThresholdMemoryPercent)
+	l.float("RESOURCE_THRESHOLD_CPU_PERCENT", &c.ResourceThresholdCPUPercent)
+	l.megabytes("RESOURCE_THRESHOLD_MEMORY", &c.ResourceThresholdMemoryMB)
+	l.milliCores("RESOURCE_THRESHOLD_CPU", &c.ResourceThresholdCPUMillicores)
+	l.lower("OVERLOAD_POLICY", &c.OverloadPolicy)
+	l.float("MINIMUM_PODS_TO_KEEP_PERCENT", &c.MinimumPodsToKeepPercent)
+	l.boolean("POD_ANNOTATIONS_ENABLED", &c.PodAnnotationsEnabled)
+	l.boolean("SHEDDING_POLICY_ENABLED", &c.SheddingPolicyEnabled)
+	l.float("WEIGHT_DRAIN_START_PERCENT", &c.WeightDrainStartPercent)
+	l.integer("WEIGHT_MIN", &c.WeightMin)
+	l.lower("RESOURCE_CPU_BASIS", &c.CPUBasis)
+	l.lower("RESOURCE_MEMORY_BASIS", &c.MemoryBasis)
+	l.milliCores("RESOURCE_CPU_BASIS_VALUE", &c.CPUBasisValue)
+	l.megabytes("RESOURCE_MEMORY_BASIS_VALUE", &c.MemoryBasisValue)
+	l.probeSpec("APP_READINESS_PROBE", &c.AppReadinessProbe)
+	l.boolean("EVENTS_ENABLED", &c.EventsEnabled)
+	l.boolean("POD_CONDITION_ENABLED", &c.PodConditionEnabled)
+	l.str("POD_STATE_LABEL", &c.PodStateLabel)
+	l.duration("EVALUATION_INTERVAL", &c.EvaluationInterval)
+	l.str("ENDPOINTSLICE_SERVICE", &c.EndpointSliceService)
+	l.endpointPorts("ENDPOINTSLICE_PORTS", &c.EndpointSlicePorts)
+	l.str("HTTP_PORT", &c.HttpPort)
+	l.str("AGENT_CHECK_PORT", &c.AgentCheckPort)
+	l.str("GRPC_HEALTH_PORT", &c.GRPCHealthPort)
+	l.str("GRPC_HEALTH_SERVICE", &c.GRPCHealthService)
+	l.boolean("ORCA_ENABLED", &c.ORCAEnabled)
+	l.integer("DECISION_HISTORY_SIZE", &c.DecisionHistorySize)
+	l.boolean("LOG_LEVEL_ENDPOINT_ENABLED", &c.LogLevelEndpoint)
+	l.str("OTLP_ENDPOINT", &c.OTLPEndpoint)
+	l.boolean("TRACING_ENABLED", &c.TracingEnabled)
+	l.float("TRACING_SAMPLE_RATIO", &c.TracingSampleRatio)
+	l.boolean("OTLP_METRICS_ENABLED", &c.OTLPMetricsEnabled)
+	l.duration("OTLP_METRICS_INTERVAL", &c.OTLPMetricsInterval)
+	l.str("STATSD_ADDRESS", &c.StatsDAddress)
+	l.str("STATSD_PREFIX", &c.StatsDPrefix)
+	l.duration("STATSD_FLUSH_INTERVAL", &c.StatsDFlushInterval)
+	l.list("STATSD_TAGS", &c.StatsDTags)
+	c.applyLogEnv(l)
+	return l.problems
+}
+
+// applyLogEnv 用日志和消息语言相关的环境变量覆盖配置
+func (c *Config) applyLogEnv(l *envLoader) {
+	l.str("LOG_LEVEL", &c.LogLevel)
+	l.lower("LOG_FORMAT", &c.LogFormat)
+	l.str("LOG_TIMESTAMP_FORMAT", &c.LogTimestampFormat)
+	l.boolean("LOG_CALLER", &c.LogCaller)
+	l.str("MESSAGE_LANG", &c.MessageLang)
+	l.keyValues("LOG_COMPONENT_LEVELS", &c.LogComponentLevels)
+	l.boolean("LOG_STATE_CHANGES_ONLY", &c.LogStateChangesOnly)
+	l.integer("LOG_SAMPLE_BURST", &c.LogSampleBurst)
+	l.duration("LOG_SAMPLE_WINDOW", &c.LogSampleWindow)
 }
 
 // 检测是否在Kubernetes集群内运行
@@ -56,37 +419,91 @@ func isRunningInCluster() bool {
 	return false
 }
 
-// NewConfig 创建配置实例
-func NewConfig() *Config {
-	// 检查是否在集群内运行
-	inCluster := isRunningInCluster()
+// defaultConfig 返回带默认值的配置
+func defaultConfig() *Config {
+	return &Config{
+		InClusterConfig:                isRunningInCluster(),
+		ResourceThresholdMemoryPercent: 80.0,
+		ResourceThresholdCPUPercent:    80.0,
+		OverloadPolicy:                 OverloadPolicyAll,
+		MinimumPodsToKeepPercent:       50.0,
+		PodAnnotationsEnabled:          true,
+		WeightDrainStartPercent:        80.0,
+		WeightMin:                      10,
+		CPUBasis:                       BasisLimit,
+		MemoryBasis:                    BasisLimit,
+		EventsEnabled:                  true,
+		EvaluationInterval:             10 * time.Second,
+		DecisionHistorySize:            500,
+		TracingSampleRatio:             1.0,
+		OTLPMetricsInterval:            30 * time.Second,
+		StatsDPrefix:                   "metrics_sidecar.",
+		StatsDFlushInterval:            10 * time.Second,
+		HttpPort:                       "8333",
+		LogLevel:                       "info",
+		LogFormat:                      LogFormatText,
+		LogTimestampFormat:             "2006-01-02 15:04:05.000",
+		LogCaller:                      true,
+		LogSampleWindow:                time.Minute,
+	}
+}
 
-	var kubeconfig *string
+// Load 按 默认值 -> 配置文件 -> 环境变量 的顺序加载配置并校验，
+// path为空时跳过配置文件。所有问题会汇总在一个ValidationError中返回
+func Load(path string) (*Config, error) {
+	cfg := defaultConfig()
 
-	if inCluster {
-		// 在集群内运行时不需要指定kubeconfig
-		kubeconfig = flag.String("kubeconfig", "", "在集群内运行时忽略此参数")
-	} else if home := os.Getenv("HOME"); home != "" {
-		// 在集群外运行时，默认尝试使用当前目录下的kube-config.yaml
-		defaultPath := filepath.Join(".", "kube-config.yaml")
-		kubeconfig = flag.String("kubeconfig", defaultPath, "(optional) absolute path to the kubeconfig file")
-	} else {
-		kubeconfig = flag.String("kubeconfig", "", "absolute path to the kubeconfig file")
+	var problems []string
+	if path != "" {
+		cfg.ConfigFile = path
+		problems = append(problems, cfg.applyFile(path)...)
 	}
+	problems = append(problems, cfg.applyEnv()...)
+	problems = append(problems, cfg.validate()...)
 
-	flag.Parse()
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
 
-	return &Config{
-		KubeconfigPath:                 *kubeconfig,
-		InClusterConfig:                inCluster,
-		Namespace:                      getEnvWithDefault("NAMESPACE", "default"),
-		DeploymentName:                 getEnvWithDefault("DEPLOYMENT_NAME", "default"),
-		ContainerName:                  getEnvWithDefault("CONTAINER_NAME", "default"),
-		PodName:                        getEnvWithDefault("POD_NAME", "default"),
-		ResourceThresholdMemoryPercent: getEnvAsFloat("RESOURCE_THRESHOLD_MEMORY_PERCENT", 80.0),
-		ResourceThresholdCPUPercent:    getEnvAsFloat("RESOURCE_THRESHOLD_CPU_PERCENT", 80.0),
-		MinimumPodsToKeepPercent:       getEnvAsFloat("MINIMUM_PODS_TO_KEEP_PERCENT", 50.0),
-		HttpPort:                       getEnvWithDefault("HTTP_PORT", "8333"),
-		LogLevel:                       getEnvWithDefault("LOG_LEVEL", "info"),
+// LoadLogConfig 只加载日志和消息语言相关的配置（与sidecar相同的默认值和LOG_*、MESSAGE_LANG环境变量）并校验，
+// 供注入器等不需要其他配置的程序使用
+func LoadLogConfig() (*Config, error) {
+	cfg := defaultConfig()
+	l := &envLoader{}
+	cfg.applyLogEnv(l)
+	if problems := append(l.problems, cfg.validateLog()...); len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// MessageLanguage 返回日志和响应使用的语言: MESSAGE_LANG（messages.lang）优先，
+// 未设置时取LC_ALL、LC_MESSAGES、LANG中第一个非空的值，不支持的语言（如C、fr_FR）使用中文
+func (c *Config) MessageLanguage() i18n.Lang {
+	return i18n.Detect(c.MessageLang)
+}
+
+// NewConfig 按命令行参数指定的配置文件和kubeconfig创建配置实例，参数为空时分别使用CONFIG_FILE环境变量和默认值
+func NewConfig(configFile, kubeconfig string) (*Config, error) {
+	// 命令行参数优先于CONFIG_FILE环境变量
+	path := configFile
+	if path == "" {
+		path = getEnvWithDefault("CONFIG_FILE", "")
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		return nil, err
 	}
+
+	if kubeconfig != "" {
+		cfg.KubeconfigPath = kubeconfig
+	} else if cfg.KubeconfigPath == "" && !cfg.InClusterConfig && os.Getenv("HOME") != "" {
+		// 在集群外运行时，默认尝试使用当前目录下的kube-config.yaml
+		cfg.KubeconfigPath = filepath.Join(".", "kube-config.yaml")
+	}
+
+	return cfg, nil
 }
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"flag"
 	"os"
 	"testing"
+
+	"metrics-sidecar/pkg/i18n"
 )
 
 func TestGetEnvWithDefault(t *testing.T) {
@@ -27,7 +30,7 @@ func TestGetEnvWithDefault(t *testing.T) {
 	}
 }
 
-func TestGetEnvAsFloat(t *testing.T) {
+func TestEnvLoaderFloat(t *testing.T) {
 	// 测试有效浮点数环境变量
 	testKey := "TEST_FLOAT_VAR"
 	testValue := "123.45"
@@ -35,27 +38,102 @@ func TestGetEnvAsFloat(t *testing.T) {
 	os.Setenv(testKey, testValue)
 	defer os.Unsetenv(testKey)
 
-	result := getEnvAsFloat(testKey, 0.0)
-	if result != expectedValue {
-		t.Errorf("getEnvAsFloat(%s, 0.0) = %f; 期望 %f", testKey, result, expectedValue)
+	l := &envLoader{}
+	result := 0.0
+	l.float(testKey, &result)
+	if result != expectedValue || len(l.problems) != 0 {
+		t.Errorf("float(%s) = %f, 问题 %v; 期望 %f且没有问题", testKey, result, l.problems, expectedValue)
 	}
 
-	// 测试无效浮点数环境变量
+	// 测试无效浮点数环境变量：保留原值并记录问题，而不是静默回退
 	invalidKey := "INVALID_FLOAT_VAR"
 	os.Setenv(invalidKey, "not-a-float")
 	defer os.Unsetenv(invalidKey)
 
 	defaultValue := 99.9
-	result = getEnvAsFloat(invalidKey, defaultValue)
+	result = defaultValue
+	l.float(invalidKey, &result)
 	if result != defaultValue {
-		t.Errorf("getEnvAsFloat(%s, %f) = %f; 期望 %f", invalidKey, defaultValue, result, defaultValue)
+		t.Errorf("float(%s) = %f; 期望保留 %f", invalidKey, result, defaultValue)
+	}
+	if len(l.problems) != 1 {
+		t.Errorf("float(%s)记录了%d个问题; 期望 1", invalidKey, len(l.problems))
 	}
 
 	// 测试不存在的环境变量
 	nonExistentKey := "NON_EXISTENT_FLOAT_KEY"
-	result = getEnvAsFloat(nonExistentKey, defaultValue)
-	if result != defaultValue {
-		t.Errorf("getEnvAsFloat(%s, %f) = %f; 期望 %f", nonExistentKey, defaultValue, result, defaultValue)
+	l.float(nonExistentKey, &result)
+	if result != defaultValue || len(l.problems) != 1 {
+		t.Errorf("float(%s) = %f; 期望 %f且不新增问题", nonExistentKey, result, defaultValue)
+	}
+}
+
+func TestEnvLoaderKeyValues(t *testing.T) {
+	t.Setenv("TEST_LEVELS", " health=warn, k8s = debug ,")
+	l := &envLoader{}
+	var result map[string]string
+	l.keyValues("TEST_LEVELS", &result)
+	if len(result) != 2 || result["health"] != "warn" || result["k8s"] != "debug" || len(l.problems) != 0 {
+		t.Errorf("keyValues = %v, 问题 %v; 期望 map[health:warn k8s:debug]且没有问题", result, l.problems)
+	}
+
+	// 缺少等号时保留原值并记录问题
+	t.Setenv("TEST_LEVELS", "health")
+	l.keyValues("TEST_LEVELS", &result)
+	if len(result) != 2 || len(l.problems) != 1 {
+		t.Errorf("keyValues = %v, 问题 %v; 期望保留原值并记录1个问题", result, l.problems)
+	}
+}
+
+func TestMessageLanguage(t *testing.T) {
+	tests := []struct {
+		messageLang, lcAll, lcMessages, lang string
+		expected                             i18n.Lang
+	}{
+		{"", "", "", "", i18n.Chinese},
+		{"", "", "", "en_US.UTF-8", i18n.English},
+		{"", "", "en_US.UTF-8", "zh_CN.UTF-8", i18n.English},
+		{"", "zh_CN.UTF-8", "en_US.UTF-8", "en_US.UTF-8", i18n.Chinese},
+		{"zh", "en_US.UTF-8", "", "", i18n.Chinese},
+		{"", "C", "", "en_US.UTF-8", i18n.Chinese},
+	}
+	for _, test := range tests {
+		t.Setenv("LC_ALL", test.lcAll)
+		t.Setenv("LC_MESSAGES", test.lcMessages)
+		t.Setenv("LANG", test.lang)
+		cfg := &Config{MessageLang: test.messageLang}
+		if got := cfg.MessageLanguage(); got != test.expected {
+			t.Errorf("MESSAGE_LANG=%q LC_ALL=%q LC_MESSAGES=%q LANG=%q时MessageLanguage() = %s; 期望 %s",
+				test.messageLang, test.lcAll, test.lcMessages, test.lang, got, test.expected)
+		}
+	}
+}
+
+func TestLoadLogConfig(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_FORMAT", "JSON")
+	t.Setenv("LOG_SAMPLE_BURST", "5")
+	cfg, err := LoadLogConfig()
+	if err != nil {
+		t.Fatalf("LoadLogConfig()返回错误: %v", err)
+	}
+	if cfg.LogLevel != "debug" || cfg.LogFormat != LogFormatJSON || cfg.LogSampleBurst != 5 {
+		t.Errorf("LoadLogConfig() = {%s %s %d}; 期望 {debug %s 5}",
+			cfg.LogLevel, cfg.LogFormat, cfg.LogSampleBurst, LogFormatJSON)
+	}
+	// 未设置的项使用与sidecar相同的默认值
+	defaults := defaultConfig()
+	if !cfg.LogCaller || cfg.LogTimestampFormat != defaults.LogTimestampFormat || cfg.LogSampleWindow != defaults.LogSampleWindow {
+		t.Errorf("LoadLogConfig()未使用默认值: caller=%v timestamp=%q window=%s",
+			cfg.LogCaller, cfg.LogTimestampFormat, cfg.LogSampleWindow)
+	}
+
+	t.Setenv("LOG_FORMAT", "xml")
+	t.Setenv("LOG_SAMPLE_WINDOW", "abc")
+	_, err = LoadLogConfig()
+	invalid, ok := err.(*ValidationError)
+	if !ok || len(invalid.Problems) != 2 {
+		t.Errorf("无效的LOG_FORMAT和LOG_SAMPLE_WINDOW时LoadLogConfig()错误 = %v; 期望包含2个问题", err)
 	}
 }
 
@@ -84,6 +162,9 @@ func TestNewConfig(t *testing.T) {
 	os.Setenv("RESOURCE_THRESHOLD_MEMORY_PERCENT", "75.5")
 	os.Setenv("RESOURCE_THRESHOLD_CPU_PERCENT", "85.5")
 	os.Setenv("MINIMUM_PODS_TO_KEEP_PERCENT", "40.0")
+	os.Setenv("RESOURCE_THRESHOLD_MEMORY", "3.5Gi")
+	os.Setenv("RESOURCE_THRESHOLD_CPU", "1800m")
+	os.Setenv("OVERLOAD_POLICY", "ANY")
 	os.Setenv("HTTP_PORT", "9090")
 
 	// 测试完成后恢复环境
@@ -111,11 +192,17 @@ func TestNewConfig(t *testing.T) {
 		os.Unsetenv("RESOURCE_THRESHOLD_MEMORY_PERCENT")
 		os.Unsetenv("RESOURCE_THRESHOLD_CPU_PERCENT")
 		os.Unsetenv("MINIMUM_PODS_TO_KEEP_PERCENT")
+		os.Unsetenv("RESOURCE_THRESHOLD_MEMORY")
+		os.Unsetenv("RESOURCE_THRESHOLD_CPU")
+		os.Unsetenv("OVERLOAD_POLICY")
 		os.Unsetenv("HTTP_PORT")
 	}()
 
 	// 由于无法直接修改isRunningInCluster函数，我们将只测试环境变量的解析
-	cfg := NewConfig()
+	cfg, err := NewConfig("", "")
+	if err != nil {
+		t.Fatalf("NewConfig(\"\", \"\")返回错误: %v", err)
+	}
 
 	// 验证配置值
 	if cfg.Namespace != "test-namespace" {
@@ -146,7 +233,112 @@ func TestNewConfig(t *testing.T) {
 		t.Errorf("MinimumPodsToKeepPercent = %f; 期望 40.0", cfg.MinimumPodsToKeepPercent)
 	}
 
+	if cfg.ResourceThresholdMemoryMB != 3584 {
+		t.Errorf("ResourceThresholdMemoryMB = %d; 期望 3584", cfg.ResourceThresholdMemoryMB)
+	}
+
+	if cfg.ResourceThresholdCPUMillicores != 1800 {
+		t.Errorf("ResourceThresholdCPUMillicores = %d; 期望 1800", cfg.ResourceThresholdCPUMillicores)
+	}
+
+	if cfg.OverloadPolicy != OverloadPolicyAny {
+		t.Errorf("OverloadPolicy = %s; 期望 %s", cfg.OverloadPolicy, OverloadPolicyAny)
+	}
+
 	if cfg.HttpPort != "9090" {
 		t.Errorf("HttpPort = %s; 期望 9090", cfg.HttpPort)
 	}
 }
+
+func TestNewConfigArguments(t *testing.T) {
+	t.Setenv("NAMESPACE", "test-namespace")
+	t.Setenv("DEPLOYMENT_NAME", "test-deployment")
+	t.Setenv("POD_NAME", "test-pod")
+	// 容器名称只由配置文件提供，测试结束后由t.Setenv恢复
+	t.Setenv("CONTAINER_NAME", "")
+	os.Unsetenv("CONTAINER_NAME")
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, "env.yaml", "kubernetes:\n  containerName: from-env\n"))
+	path := writeConfigFile(t, "flag.yaml", "kubernetes:\n  containerName: from-flag\n")
+
+	// 命令行参数优先于CONFIG_FILE环境变量
+	cfg, err := NewConfig(path, "/tmp/kubeconfig")
+	if err != nil {
+		t.Fatalf("NewConfig()返回错误: %v", err)
+	}
+	if cfg.ContainerName != "from-flag" || cfg.ConfigFile != path {
+		t.Errorf("ContainerName = %s, ConfigFile = %s; 期望 from-flag, %s", cfg.ContainerName, cfg.ConfigFile, path)
+	}
+	if cfg.KubeconfigPath != "/tmp/kubeconfig" {
+		t.Errorf("KubeconfigPath = %s; 期望 /tmp/kubeconfig", cfg.KubeconfigPath)
+	}
+
+	cfg, err = NewConfig("", "")
+	if err != nil {
+		t.Fatalf("NewConfig(\"\", \"\")返回错误: %v", err)
+	}
+	if cfg.ContainerName != "from-env" {
+		t.Errorf("ContainerName = %s; 期望 from-env", cfg.ContainerName)
+	}
+
+	// 命令行参数由main注册，导入config包不应注册任何参数
+	for _, name := range []string{"config", "kubeconfig"} {
+		if flag.Lookup(name) != nil {
+			t.Errorf("config包不应注册-%s参数", name)
+		}
+	}
+}
+
+func TestEnvLoaderQuantity(t *testing.T) {
+	os.Setenv("TEST_CPU_QUANTITY", "1500m")
+	os.Setenv("TEST_MEM_QUANTITY", "3.5Gi")
+	os.Setenv("TEST_INVALID_QUANTITY", "not-a-quantity")
+	defer os.Unsetenv("TEST_CPU_QUANTITY")
+	defer os.Unsetenv("TEST_MEM_QUANTITY")
+	defer os.Unsetenv("TEST_INVALID_QUANTITY")
+
+	l := &envLoader{}
+	var cpu, mem, invalid int64
+	l.milliCores("TEST_CPU_QUANTITY", &cpu)
+	l.megabytes("TEST_MEM_QUANTITY", &mem)
+	l.megabytes("NON_EXISTENT_QUANTITY_KEY", &mem)
+
+	if cpu != 1500 {
+		t.Errorf("milliCores(TEST_CPU_QUANTITY) = %d; 期望 1500", cpu)
+	}
+	if mem != 3584 {
+		t.Errorf("megabytes(TEST_MEM_QUANTITY) = %d; 期望 3584", mem)
+	}
+	if len(l.problems) != 0 {
+		t.Errorf("有效的数量不应记录问题: %v", l.problems)
+	}
+
+	// 无效值会被记录为问题
+	l.milliCores("TEST_INVALID_QUANTITY", &invalid)
+	if invalid != 0 || len(l.problems) != 1 {
+		t.Errorf("milliCores(TEST_INVALID_QUANTITY) = %d, 问题 %v; 期望 0且记录1个问题", invalid, l.problems)
+	}
+}
+
+func TestParseEndpointPorts(t *testing.T) {
+	ports, err := parseEndpointPorts("http:8080/tcp, 9090, dns:53/UDP")
+	if err != nil {
+		t.Fatalf("parseEndpointPorts返回错误: %v", err)
+	}
+	expected := []EndpointPort{
+		{Name: "http", Port: 8080, Protocol: "TCP"},
+		{Name: "", Port: 9090, Protocol: "TCP"},
+		{Name: "dns", Port: 53, Protocol: "UDP"},
+	}
+	if len(ports) != len(expected) {
+		t.Fatalf("parseEndpointPorts = %v; 期望 %v", ports, expected)
+	}
+	for i := range expected {
+		if ports[i] != expected[i] {
+			t.Errorf("第%d个端口 = %v; 期望 %v", i, ports[i], expected[i])
+		}
+	}
+
+	if _, err := parseEndpointPorts("http:abc"); err == nil {
+		t.Error("parseEndpointPorts(http:abc)未返回错误; 期望返回错误")
+	}
+}
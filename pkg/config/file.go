@@ -0,0 +1,478 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/probe"
+)
+
+// fileConfig 配置文件的结构，支持YAML和JSON两种格式。
+// 所有字段均为可选，未设置的字段保留默认值，环境变量会覆盖文件中的值
+type fileConfig struct {
+	Kubernetes *fileKubernetes `json:"kubernetes"`
+	Thresholds *fileThresholds `json:"thresholds"`
+	Basis      *fileBasis      `json:"basis"`
+	Policy     *filePolicy     `json:"policy"`
+	Weight     *fileWeight     `json:"weight"`
+	// 应用原有的就绪探针，格式与Kubernetes的readinessProbe相同（仅支持httpGet和tcpSocket）
+	AppReadinessProbe *probe.Spec        `json:"appReadinessProbe"`
+	Events            *fileEvents        `json:"events"`
+	PodStatus         *filePodStatus     `json:"podStatus"`
+	EndpointSlice     *fileEndpointSlice `json:"endpointSlice"`
+	HTTP              *fileHTTP          `json:"http"`
+	AgentCheck        *fileAgentCheck    `json:"agentCheck"`
+	GRPCHealth        *fileGRPCHealth    `json:"grpcHealth"`
+	ORCA              *fileORCA          `json:"orca"`
+	Debug             *fileDebug         `json:"debug"`
+	OTLP              *fileOTLP          `json:"otlp"`
+	Tracing           *fileTracing       `json:"tracing"`
+	OTLPMetrics       *fileOTLPMetrics   `json:"otlpMetrics"`
+	StatsD            *fileStatsD        `json:"statsd"`
+	Log               *fileLog           `json:"log"`
+	Messages          *fileMessages      `json:"messages"`
+}
+
+// fileKubernetes 监控目标配置
+type fileKubernetes struct {
+	Kubeconfig     string `json:"kubeconfig"`
+	Namespace      string `json:"namespace"`
+	DeploymentName string `json:"deploymentName"`
+	ContainerName  string `json:"containerName"`
+	PodName        string `json:"podName"`
+}
+
+// fileThresholds 资源阈值配置，cpu和memory为Kubernetes数量语法的绝对阈值
+type fileThresholds struct {
+	MemoryPercent *float64 `json:"memoryPercent"`
+	CPUPercent    *float64 `json:"cpuPercent"`
+	Memory        string   `json:"memory"`
+	CPU           string   `json:"cpu"`
+}
+
+// fileBasis 资源使用率计算基准配置
+type fileBasis struct {
+	CPU    *fileResourceBasis `json:"cpu"`
+	Memory *fileResourceBasis `json:"memory"`
+}
+
+// fileResourceBasis 单个资源的计算基准，value仅在type为absolute时使用
+type fileResourceBasis struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// filePolicy 过载判定与随机退避策略
+type filePolicy struct {
+	Overload                 string   `json:"overload"`
+	MinimumPodsToKeepPercent *float64 `json:"minimumPodsToKeepPercent"`
+	PodAnnotations           *bool    `json:"podAnnotations"`
+	SheddingPolicies         *bool    `json:"sheddingPolicies"`
+}
+
+// fileWeight 权重配置
+type fileWeight struct {
+	DrainStartPercent *float64 `json:"drainStartPercent"`
+	Min               *int     `json:"min"`
+}
+
+// fileEvents Kubernetes事件配置
+type fileEvents struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// filePodStatus 将状态写入Pod的配置，evaluationInterval为Go时长语法，如"10s"
+type filePodStatus struct {
+	Condition          *bool  `json:"condition"`
+	StateLabel         string `json:"stateLabel"`
+	EvaluationInterval string `json:"evaluationInterval"`
+}
+
+// fileEndpointSlice EndpointSlice直接控制配置
+type fileEndpointSlice struct {
+	Service string         `json:"service"`
+	Ports   []EndpointPort `json:"ports"`
+}
+
+// fileHTTP HTTP服务配置
+type fileHTTP struct {
+	Port *int `json:"port"`
+}
+
+// fileAgentCheck HAProxy agent-check配置
+type fileAgentCheck struct {
+	Port *int `json:"port"`
+}
+
+// fileORCA ORCA负载报告配置
+type fileORCA struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// fileDebug 调试接口配置
+type fileDebug struct {
+	DecisionHistorySize *int  `json:"decisionHistorySize"`
+	LogLevelEndpoint    *bool `json:"logLevelEndpoint"`
+}
+
+// fileOTLP OpenTelemetry接收端配置
+type fileOTLP struct {
+	Endpoint string `json:"endpoint"`
+}
+
+// fileTracing 请求追踪配置
+type fileTracing struct {
+	Enabled     *bool    `json:"enabled"`
+	SampleRatio *float64 `json:"sampleRatio"`
+}
+
+// fileOTLPMetrics OTLP指标推送配置
+type fileOTLPMetrics struct {
+	Enabled  *bool  `json:"enabled"`
+	Interval string `json:"interval"`
+}
+
+// fileStatsD StatsD/DogStatsD配置
+type fileStatsD struct {
+	Address       string   `json:"address"`
+	Prefix        *string  `json:"prefix"` // 允许设置为空字符串以去掉前缀
+	FlushInterval string   `json:"flushInterval"`
+	Tags          []string `json:"tags"`
+}
+
+// fileGRPCHealth gRPC健康检查服务配置
+type fileGRPCHealth struct {
+	Port    *int   `json:"port"`
+	Service string `json:"service"`
+}
+
+// fileLog 日志配置
+type fileLog struct {
+	Level           string `json:"level"`
+	Format          string `json:"format"`
+	TimestampFormat string `json:"timestampFormat"`
+	Caller          *bool  `json:"caller"`
+
+	Levels           map[string]string `json:"levels"`
+	StateChangesOnly *bool             `json:"stateChangesOnly"`
+	Sampling         *fileLogSampling  `json:"sampling"`
+}
+
+// fileMessages 人类可读消息配置
+type fileMessages struct {
+	Lang string `json:"lang"`
+}
+
+// fileLogSampling 重复日志采样配置
+type fileLogSampling struct {
+	Burst  *int   `json:"burst"`
+	Window string `json:"window"`
+}
+
+// applyFile 读取配置文件并覆盖到配置上，返回发现的所有问题
+func (c *Config) applyFile(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []string{i18n.T("config.read_file_failed", err)}
+	}
+
+	// YAML是JSON的超集，统一转换为JSON后再解析
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return []string{i18n.T("config.parse_file_failed", path, err)}
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(jsonData, &raw); err != nil {
+		return []string{i18n.T("config.file_not_object", path, err)}
+	}
+
+	problems := unknownKeys(raw, reflect.TypeOf(fileConfig{}), "")
+
+	var file fileConfig
+	if err := json.Unmarshal(jsonData, &file); err != nil {
+		problems = append(problems, i18n.T("config.file_type_error", err))
+	}
+
+	return append(problems, c.applyFileConfig(&file)...)
+}
+
+// unknownKeys 递归检查配置文件中未在结构体中定义的字段
+func unknownKeys(raw map[string]interface{}, t reflect.Type, prefix string) []string {
+	known := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		known[name] = fieldType
+	}
+
+	// 按字段名排序，保证错误信息顺序稳定
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		fieldType, ok := known[key]
+		if !ok {
+			problems = append(problems, i18n.T("config.unknown_field", prefix, key))
+			continue
+		}
+		problems = append(problems, unknownNestedKeys(raw[key], fieldType, prefix+key)...)
+	}
+	return problems
+}
+
+// unknownNestedKeys 检查对象或对象数组类型的字段值，数组元素的路径如endpointSlice.ports[0].name
+func unknownNestedKeys(value interface{}, t reflect.Type, path string) []string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if t.Kind() == reflect.Struct {
+			return unknownKeys(v, t, path+".")
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice {
+			var problems []string
+			for i, item := range v {
+				problems = append(problems, unknownNestedKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+			}
+			return problems
+		}
+	}
+	return nil
+}
+
+// applyFileConfig 将配置文件中已设置的字段覆盖到配置上
+func (c *Config) applyFileConfig(file *fileConfig) []string {
+	var problems []string
+
+	if k := file.Kubernetes; k != nil {
+		setString(&c.KubeconfigPath, k.Kubeconfig)
+		setString(&c.Namespace, k.Namespace)
+		setString(&c.DeploymentName, k.DeploymentName)
+		setString(&c.ContainerName, k.ContainerName)
+		setString(&c.PodName, k.PodName)
+	}
+
+	if t := file.Thresholds; t != nil {
+		setFloat(&c.ResourceThresholdMemoryPercent, t.MemoryPercent)
+		setFloat(&c.ResourceThresholdCPUPercent, t.CPUPercent)
+		problems = appendProblem(problems, setQuantity(&c.ResourceThresholdMemoryMB, t.Memory, parseMegabytes, "thresholds.memory"))
+		problems = appendProblem(problems, setQuantity(&c.ResourceThresholdCPUMillicores, t.CPU, parseMilliCores, "thresholds.cpu"))
+	}
+
+	if b := file.Basis; b != nil {
+		if b.CPU != nil {
+			setString(&c.CPUBasis, strings.ToLower(b.CPU.Type))
+			problems = appendProblem(problems, setQuantity(&c.CPUBasisValue, b.CPU.Value, parseMilliCores, "basis.cpu.value"))
+		}
+		if b.Memory != nil {
+			setString(&c.MemoryBasis, strings.ToLower(b.Memory.Type))
+			problems = appendProblem(problems, setQuantity(&c.MemoryBasisValue, b.Memory.Value, parseMegabytes, "basis.memory.value"))
+		}
+	}
+
+	if p := file.Policy; p != nil {
+		setString(&c.OverloadPolicy, strings.ToLower(p.Overload))
+		setFloat(&c.MinimumPodsToKeepPercent, p.MinimumPodsToKeepPercent)
+		setBool(&c.PodAnnotationsEnabled, p.PodAnnotations)
+		setBool(&c.SheddingPolicyEnabled, p.SheddingPolicies)
+	}
+
+	if w := file.Weight; w != nil {
+		setFloat(&c.WeightDrainStartPercent, w.DrainStartPercent)
+		setInt(&c.WeightMin, w.Min)
+	}
+
+	if file.AppReadinessProbe != nil {
+		c.AppReadinessProbe = file.AppReadinessProbe
+	}
+
+	if file.Events != nil {
+		setBool(&c.EventsEnabled, file.Events.Enabled)
+	}
+
+	if p := file.PodStatus; p != nil {
+		setBool(&c.PodConditionEnabled, p.Condition)
+		setString(&c.PodStateLabel, p.StateLabel)
+		if p.EvaluationInterval != "" {
+			interval, err := time.ParseDuration(p.EvaluationInterval)
+			if err != nil {
+				problems = append(problems, i18n.T("config.file_evaluation_interval", p.EvaluationInterval))
+			} else {
+				c.EvaluationInterval = interval
+			}
+		}
+	}
+
+	if e := file.EndpointSlice; e != nil {
+		setString(&c.EndpointSliceService, e.Service)
+		if len(e.Ports) > 0 {
+			c.EndpointSlicePorts = e.Ports
+			for i := range c.EndpointSlicePorts {
+				c.EndpointSlicePorts[i].Protocol = strings.ToUpper(c.EndpointSlicePorts[i].Protocol)
+				if c.EndpointSlicePorts[i].Protocol == "" {
+					c.EndpointSlicePorts[i].Protocol = "TCP"
+				}
+			}
+		}
+	}
+
+	if file.HTTP != nil && file.HTTP.Port != nil {
+		c.HttpPort = strconv.Itoa(*file.HTTP.Port)
+	}
+
+	if file.AgentCheck != nil && file.AgentCheck.Port != nil {
+		c.AgentCheckPort = strconv.Itoa(*file.AgentCheck.Port)
+	}
+
+	if g := file.GRPCHealth; g != nil {
+		if g.Port != nil {
+			c.GRPCHealthPort = strconv.Itoa(*g.Port)
+		}
+		setString(&c.GRPCHealthService, g.Service)
+	}
+
+	if file.ORCA != nil {
+		setBool(&c.ORCAEnabled, file.ORCA.Enabled)
+	}
+
+	if file.Debug != nil {
+		setInt(&c.DecisionHistorySize, file.Debug.DecisionHistorySize)
+		setBool(&c.LogLevelEndpoint, file.Debug.LogLevelEndpoint)
+	}
+
+	if file.OTLP != nil {
+		setString(&c.OTLPEndpoint, file.OTLP.Endpoint)
+	}
+
+	if t := file.Tracing; t != nil {
+		setBool(&c.TracingEnabled, t.Enabled)
+		setFloat(&c.TracingSampleRatio, t.SampleRatio)
+	}
+
+	if m := file.OTLPMetrics; m != nil {
+		setBool(&c.OTLPMetricsEnabled, m.Enabled)
+		if m.Interval != "" {
+			interval, err := time.ParseDuration(m.Interval)
+			if err != nil {
+				problems = append(problems, i18n.T("config.file_otlp_metrics_interval", m.Interval))
+			} else {
+				c.OTLPMetricsInterval = interval
+			}
+		}
+	}
+
+	if s := file.StatsD; s != nil {
+		setString(&c.StatsDAddress, s.Address)
+		if s.Prefix != nil {
+			c.StatsDPrefix = *s.Prefix
+		}
+		if s.FlushInterval != "" {
+			interval, err := time.ParseDuration(s.FlushInterval)
+			if err != nil {
+				problems = append(problems, i18n.T("config.file_statsd_flush_interval", s.FlushInterval))
+			} else {
+				c.StatsDFlushInterval = interval
+			}
+		}
+		if s.Tags != nil {
+			c.StatsDTags = s.Tags
+		}
+	}
+
+	if l := file.Log; l != nil {
+		setString(&c.LogLevel, l.Level)
+		setString(&c.LogFormat, strings.ToLower(l.Format))
+		setString(&c.LogTimestampFormat, l.TimestampFormat)
+		setBool(&c.LogCaller, l.Caller)
+		if l.Levels != nil {
+			c.LogComponentLevels = l.Levels
+		}
+		setBool(&c.LogStateChangesOnly, l.StateChangesOnly)
+		if s := l.Sampling; s != nil {
+			setInt(&c.LogSampleBurst, s.Burst)
+			if s.Window != "" {
+				window, err := time.ParseDuration(s.Window)
+				if err != nil {
+					problems = append(problems, i18n.T("config.file_log_sample_window", s.Window))
+				} else {
+					c.LogSampleWindow = window
+				}
+			}
+		}
+	}
+
+	if m := file.Messages; m != nil {
+		setString(&c.MessageLang, m.Lang)
+	}
+
+	return problems
+}
+
+// 仅在value非空时覆盖
+func setString(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+// 仅在value已设置时覆盖
+func setFloat(dst *float64, value *float64) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+// 仅在value已设置时覆盖
+func setInt(dst *int, value *int) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+// 仅在value已设置时覆盖
+func setBool(dst *bool, value *bool) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+// 解析数量语法的值并覆盖，value为空时跳过
+func setQuantity(dst *int64, value string, parse func(string) (int64, error), field string) string {
+	if value == "" {
+		return ""
+	}
+	parsed, err := parse(value)
+	if err != nil {
+		return i18n.T("common.invalid_quantity", field, value, err)
+	}
+	*dst = parsed
+	return ""
+}
+
+// 追加非空的问题描述
+func appendProblem(problems []string, problem string) []string {
+	if problem == "" {
+		return problems
+	}
+	return append(problems, problem)
+}
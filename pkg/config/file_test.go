@@ -0,0 +1,201 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 写入临时配置文件并返回路径
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("写入配置文件失败: %v", err)
+	}
+	return path
+}
+
+func TestLoadYAMLFile(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+kubernetes:
+  namespace: file-namespace
+  deploymentName: file-deployment
+  containerName: file-container
+  podName: file-pod
+thresholds:
+  memoryPercent: 70
+  cpuPercent: 75.5
+  memory: 3.5Gi
+basis:
+  cpu:
+    type: absolute
+    value: "2"
+policy:
+  overload: any
+  minimumPodsToKeepPercent: 30
+http:
+  port: 9090
+log:
+  level: debug
+`)
+
+	// 环境变量优先于配置文件
+	t.Setenv("RESOURCE_THRESHOLD_CPU_PERCENT", "90")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(%s)返回错误: %v", path, err)
+	}
+
+	if cfg.Namespace != "file-namespace" || cfg.PodName != "file-pod" {
+		t.Errorf("Namespace/PodName = %s/%s; 期望 file-namespace/file-pod", cfg.Namespace, cfg.PodName)
+	}
+	if cfg.ResourceThresholdMemoryPercent != 70 {
+		t.Errorf("ResourceThresholdMemoryPercent = %f; 期望 70", cfg.ResourceThresholdMemoryPercent)
+	}
+	if cfg.ResourceThresholdCPUPercent != 90 {
+		t.Errorf("ResourceThresholdCPUPercent = %f; 期望环境变量覆盖为 90", cfg.ResourceThresholdCPUPercent)
+	}
+	if cfg.ResourceThresholdMemoryMB != 3584 {
+		t.Errorf("ResourceThresholdMemoryMB = %d; 期望 3584", cfg.ResourceThresholdMemoryMB)
+	}
+	if cfg.CPUBasis != BasisAbsolute || cfg.CPUBasisValue != 2000 {
+		t.Errorf("CPUBasis = %s/%d; 期望 absolute/2000", cfg.CPUBasis, cfg.CPUBasisValue)
+	}
+	if cfg.MemoryBasis != BasisLimit {
+		t.Errorf("MemoryBasis = %s; 期望保留默认值 limit", cfg.MemoryBasis)
+	}
+	if cfg.OverloadPolicy != OverloadPolicyAny || cfg.MinimumPodsToKeepPercent != 30 {
+		t.Errorf("OverloadPolicy/MinimumPodsToKeepPercent = %s/%f; 期望 any/30", cfg.OverloadPolicy, cfg.MinimumPodsToKeepPercent)
+	}
+	if cfg.HttpPort != "9090" || cfg.LogLevel != "debug" {
+		t.Errorf("HttpPort/LogLevel = %s/%s; 期望 9090/debug", cfg.HttpPort, cfg.LogLevel)
+	}
+}
+
+func TestLoadJSONFile(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{
+  "kubernetes": {
+    "namespace": "json-namespace",
+    "deploymentName": "json-deployment",
+    "containerName": "json-container",
+    "podName": "json-pod"
+  },
+  "thresholds": {"cpu": "1800m"}
+}`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(%s)返回错误: %v", path, err)
+	}
+	if cfg.ResourceThresholdCPUMillicores != 1800 {
+		t.Errorf("ResourceThresholdCPUMillicores = %d; 期望 1800", cfg.ResourceThresholdCPUMillicores)
+	}
+}
+
+func TestLoadReportsAllProblems(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+kubernetes:
+  namespace: test
+  deploymentName: test
+  podName: test
+  containr: typo
+thresholds:
+  memoryPercent: 120
+  cpu: lots
+basis:
+  memory:
+    type: absolute
+endpointSlice:
+  ports:
+    - name: http
+      port: 8080
+      protcol: TCP
+unknownSection: true
+`)
+	t.Setenv("CONTAINER_NAME", "")
+	t.Setenv("MINIMUM_PODS_TO_KEEP_PERCENT", "fifty")
+	t.Setenv("OVERLOAD_POLICY", "majority")
+
+	_, err := Load(path)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Load(%s)返回 %v; 期望 ValidationError", path, err)
+	}
+
+	// 每个问题都应出现在错误中
+	expected := []string{
+		"kubernetes.containr",
+		"endpointSlice.ports[0].protcol",
+		"unknownSection",
+		"thresholds.cpu",
+		"MINIMUM_PODS_TO_KEEP_PERCENT",
+		"CONTAINER_NAME",
+		"thresholds.memoryPercent",
+		"OVERLOAD_POLICY",
+		"RESOURCE_MEMORY_BASIS",
+	}
+	if len(validationErr.Problems) != len(expected) {
+		t.Errorf("发现%d个问题; 期望 %d:\n%v", len(validationErr.Problems), len(expected), err)
+	}
+	for _, keyword := range expected {
+		if !strings.Contains(err.Error(), keyword) {
+			t.Errorf("错误信息中缺少 %s:\n%v", keyword, err)
+		}
+	}
+}
+
+// 从Kubernetes直接复制的探针定义在配置文件和环境变量中都可以使用，不支持的exec探针都会被拒绝
+func TestCopiedReadinessProbe(t *testing.T) {
+	const kubernetes = `
+kubernetes:
+  namespace: probe-namespace
+  deploymentName: probe-deployment
+  containerName: probe-container
+  podName: probe-pod
+`
+	path := writeConfigFile(t, "config.yaml", kubernetes+`
+appReadinessProbe:
+  httpGet:
+    path: /ready
+    port: 8080
+  initialDelaySeconds: 5
+  periodSeconds: 10
+  timeoutSeconds: 2
+  successThreshold: 1
+  failureThreshold: 3
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(%s)返回错误: %v", path, err)
+	}
+	if cfg.AppReadinessProbe == nil || cfg.AppReadinessProbe.HTTPGet == nil || cfg.AppReadinessProbe.TimeoutSeconds != 2 {
+		t.Errorf("AppReadinessProbe = %+v; 期望 httpGet且timeoutSeconds=2", cfg.AppReadinessProbe)
+	}
+
+	t.Setenv("APP_READINESS_PROBE", `{"tcpSocket":{"port":8080},"initialDelaySeconds":5,"periodSeconds":10,"successThreshold":1,"failureThreshold":3}`)
+	cfg, err = Load(writeConfigFile(t, "env.yaml", kubernetes))
+	if err != nil {
+		t.Fatalf("Load()返回错误: %v", err)
+	}
+	if cfg.AppReadinessProbe == nil || cfg.AppReadinessProbe.TCPSocket == nil {
+		t.Errorf("AppReadinessProbe = %+v; 期望 tcpSocket", cfg.AppReadinessProbe)
+	}
+
+	t.Setenv("APP_READINESS_PROBE", `{"exec":{"command":["true"]}}`)
+	if _, err := Load(writeConfigFile(t, "exec-env.yaml", kubernetes)); err == nil {
+		t.Error("环境变量中的exec探针没有被拒绝")
+	}
+	t.Setenv("APP_READINESS_PROBE", "")
+	path = writeConfigFile(t, "exec.yaml", kubernetes+`
+appReadinessProbe:
+  exec:
+    command: ["true"]
+`)
+	if _, err := Load(path); err == nil {
+		t.Error("配置文件中的exec探针没有被拒绝")
+	}
+}
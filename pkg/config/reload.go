@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+)
+
+// reloadableEnv 可热更新字段对应的环境变量，环境变量在配置文件之后应用，设置后配置文件中的修改不会生效
+var reloadableEnv = []struct {
+	field string
+	env   string
+}{
+	{"ResourceThresholdMemoryPercent", "RESOURCE_THRESHOLD_MEMORY_PERCENT"},
+	{"ResourceThresholdCPUPercent", "RESOURCE_THRESHOLD_CPU_PERCENT"},
+	{"ResourceThresholdMemoryMB", "RESOURCE_THRESHOLD_MEMORY"},
+	{"ResourceThresholdCPUMillicores", "RESOURCE_THRESHOLD_CPU"},
+	{"OverloadPolicy", "OVERLOAD_POLICY"},
+	{"MinimumPodsToKeepPercent", "MINIMUM_PODS_TO_KEEP_PERCENT"},
+	{"WeightDrainStartPercent", "WEIGHT_DRAIN_START_PERCENT"},
+	{"WeightMin", "WEIGHT_MIN"},
+}
+
+// WithReloadable 返回c的副本，其中可热更新的字段（阈值、过载策略、随机退避和权重参数）取自next，
+// 监控目标、计算基准、端口等需要重启才能生效的字段保持不变
+func (c *Config) WithReloadable(next *Config) *Config {
+	updated := *c
+	updated.ResourceThresholdMemoryPercent = next.ResourceThresholdMemoryPercent
+	updated.ResourceThresholdCPUPercent = next.ResourceThresholdCPUPercent
+	updated.ResourceThresholdMemoryMB = next.ResourceThresholdMemoryMB
+	updated.ResourceThresholdCPUMillicores = next.ResourceThresholdCPUMillicores
+	updated.OverloadPolicy = next.OverloadPolicy
+	updated.MinimumPodsToKeepPercent = next.MinimumPodsToKeepPercent
+	updated.WeightDrainStartPercent = next.WeightDrainStartPercent
+	updated.WeightMin = next.WeightMin
+	return &updated
+}
+
+// Diff 列出两份配置之间发生变化的字段，格式为"字段: 旧值 -> 新值"
+func Diff(old, updated *Config) []string {
+	oldValue := reflect.ValueOf(old).Elem()
+	newValue := reflect.ValueOf(updated).Elem()
+	t := oldValue.Type()
+
+	var changes []string
+	for i := 0; i < t.NumField(); i++ {
+		before := oldValue.Field(i).Interface()
+		after := newValue.Field(i).Interface()
+		if !reflect.DeepEqual(before, after) {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", t.Field(i).Name, before, after))
+		}
+	}
+	return changes
+}
+
+// LoadFile 只按 默认值 -> 配置文件 加载，不读取环境变量也不校验，用于判断配置文件本身的变化。
+// 配置文件有问题时返回nil
+func LoadFile(path string) *Config {
+	cfg := defaultConfig()
+	if problems := cfg.applyFile(path); len(problems) > 0 {
+		return nil
+	}
+	return cfg
+}
+
+// EnvPinned 列出配置文件中发生变化、但因设置了对应环境变量而不会生效的可热更新字段，
+// 格式为"字段: 旧值 -> 新值 (环境变量)"，oldFile和newFile由LoadFile加载
+func EnvPinned(oldFile, newFile *Config) []string {
+	if oldFile == nil || newFile == nil {
+		return nil
+	}
+	oldValue := reflect.ValueOf(oldFile).Elem()
+	newValue := reflect.ValueOf(newFile).Elem()
+
+	var pinned []string
+	for _, field := range reloadableEnv {
+		if _, set := os.LookupEnv(field.env); !set {
+			continue
+		}
+		before := oldValue.FieldByName(field.field).Interface()
+		after := newValue.FieldByName(field.field).Interface()
+		if !reflect.DeepEqual(before, after) {
+			pinned = append(pinned, fmt.Sprintf("%s: %v -> %v (%s)", field.field, before, after, field.env))
+		}
+	}
+	return pinned
+}
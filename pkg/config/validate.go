@@ -0,0 +1,281 @@
+package config
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"metrics-sidecar/pkg/i18n"
+)
+
+// ValidationError 汇总配置中发现的所有问题
+type ValidationError struct {
+	Problems []string
+}
+
+// Error 实现error接口，逐行列出所有问题
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString(i18n.T("config.invalid", len(e.Problems)))
+	for _, problem := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(problem)
+	}
+	return b.String()
+}
+
+// Validate 校验配置，存在问题时返回ValidationError
+func (c *Config) Validate() error {
+	if problems := c.validate(); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// validate 校验配置并返回所有问题
+func (c *Config) validate() []string {
+	var problems []string
+
+	// 必填字段
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"NAMESPACE (kubernetes.namespace)", c.Namespace},
+		{"DEPLOYMENT_NAME (kubernetes.deploymentName)", c.DeploymentName},
+		{"CONTAINER_NAME (kubernetes.containerName)", c.ContainerName},
+		{"POD_NAME (kubernetes.podName)", c.PodName},
+	}
+	for _, field := range required {
+		if field.value == "" {
+			problems = append(problems, i18n.T("config.required", field.name))
+		}
+	}
+
+	// 百分比范围
+	percents := []struct {
+		name  string
+		value float64
+	}{
+		{"RESOURCE_THRESHOLD_MEMORY_PERCENT (thresholds.memoryPercent)", c.ResourceThresholdMemoryPercent},
+		{"RESOURCE_THRESHOLD_CPU_PERCENT (thresholds.cpuPercent)", c.ResourceThresholdCPUPercent},
+		{"MINIMUM_PODS_TO_KEEP_PERCENT (policy.minimumPodsToKeepPercent)", c.MinimumPodsToKeepPercent},
+	}
+	for _, field := range percents {
+		if field.value < 0 || field.value > 100 {
+			problems = append(problems, i18n.T("config.percent_range", field.name, field.value))
+		}
+	}
+
+	// 达到阈值时权重已降到最低，开始降低权重的位置必须低于阈值
+	if c.WeightDrainStartPercent < 0 || c.WeightDrainStartPercent >= 100 {
+		problems = append(problems, i18n.T("config.weight_drain_start_range", c.WeightDrainStartPercent))
+	}
+	if c.WeightMin < 0 || c.WeightMin > 100 {
+		problems = append(problems, i18n.T("config.weight_min_range", c.WeightMin))
+	}
+
+	if c.DecisionHistorySize < 0 {
+		problems = append(problems, i18n.T("config.history_size_negative", c.DecisionHistorySize))
+	}
+
+	// 绝对阈值不能为负数
+	if c.ResourceThresholdMemoryMB < 0 {
+		problems = append(problems, i18n.T("config.memory_threshold_negative", c.ResourceThresholdMemoryMB))
+	}
+	if c.ResourceThresholdCPUMillicores < 0 {
+		problems = append(problems, i18n.T("config.cpu_threshold_negative", c.ResourceThresholdCPUMillicores))
+	}
+
+	if c.OverloadPolicy != OverloadPolicyAll && c.OverloadPolicy != OverloadPolicyAny {
+		problems = append(problems, i18n.T("config.overload_policy",
+			c.OverloadPolicy, OverloadPolicyAll, OverloadPolicyAny))
+	}
+
+	problems = append(problems, validateBasis("RESOURCE_CPU_BASIS (basis.cpu)", c.CPUBasis, c.CPUBasisValue)...)
+	problems = append(problems, validateBasis("RESOURCE_MEMORY_BASIS (basis.memory)", c.MemoryBasis, c.MemoryBasisValue)...)
+
+	if c.AppReadinessProbe != nil {
+		problems = append(problems, c.AppReadinessProbe.Validate("APP_READINESS_PROBE (appReadinessProbe)")...)
+	}
+
+	if c.PodStateLabel != "" {
+		for _, msg := range validation.IsQualifiedName(c.PodStateLabel) {
+			problems = append(problems, i18n.T("config.state_label", c.PodStateLabel, msg))
+		}
+	}
+	if c.EvaluationInterval <= 0 {
+		problems = append(problems, i18n.T("config.evaluation_interval", c.EvaluationInterval))
+	}
+
+	if c.EndpointSliceService != "" {
+		problems = append(problems, c.validateEndpointSlice()...)
+	}
+
+	if port, err := strconv.Atoi(c.HttpPort); err != nil || port <= 0 || port > 65535 {
+		problems = append(problems, i18n.T("config.http_port", c.HttpPort))
+	}
+	problems = append(problems, c.validateListenPorts()...)
+	problems = append(problems, c.validateOpenTelemetry()...)
+	if c.StatsDAddress != "" {
+		problems = append(problems, c.validateStatsD()...)
+	}
+
+	problems = append(problems, c.validateLog()...)
+
+	return problems
+}
+
+// validateLog 校验日志和消息语言相关的配置
+func (c *Config) validateLog() []string {
+	var problems []string
+	if !validLogLevel(c.LogLevel) {
+		problems = append(problems, i18n.T("config.log_level", c.LogLevel))
+	}
+	for component, level := range c.LogComponentLevels {
+		if !validLogLevel(level) {
+			problems = append(problems, i18n.T("config.log_component_level", component, level))
+		}
+	}
+	switch c.LogFormat {
+	case LogFormatText, LogFormatJSON, LogFormatLogfmt:
+	default:
+		problems = append(problems, i18n.T("config.log_format",
+			c.LogFormat, LogFormatText, LogFormatJSON, LogFormatLogfmt))
+	}
+	if c.LogTimestampFormat == "" {
+		problems = append(problems, i18n.T("config.log_timestamp_format"))
+	}
+	if _, ok := i18n.Parse(c.MessageLang); c.MessageLang != "" && !ok {
+		problems = append(problems, i18n.T("config.message_lang", c.MessageLang))
+	}
+	if c.LogSampleBurst < 0 {
+		problems = append(problems, i18n.T("config.log_sample_burst", c.LogSampleBurst))
+	}
+	if c.LogSampleBurst > 0 && c.LogSampleWindow <= 0 {
+		problems = append(problems, i18n.T("config.log_sample_window", c.LogSampleWindow))
+	}
+
+	return problems
+}
+
+// validLogLevel 判断日志级别是否有效
+func validLogLevel(level string) bool {
+	switch strings.ToLower(level) {
+	case "debug", "info", "warn", "warning", "error":
+		return true
+	}
+	return false
+}
+
+// validateBasis 校验资源使用率计算基准
+func validateBasis(name, basis string, value int64) []string {
+	switch basis {
+	case BasisLimit, BasisRequest, BasisNodeAllocatable:
+		return nil
+	case BasisAbsolute:
+		if value <= 0 {
+			return []string{i18n.T("config.basis_value", name)}
+		}
+		return nil
+	default:
+		return []string{i18n.T("config.basis",
+			name, basis, BasisLimit, BasisRequest, BasisNodeAllocatable, BasisAbsolute)}
+	}
+}
+
+// validateEndpointSlice 校验EndpointSlice直接控制配置
+func (c *Config) validateEndpointSlice() []string {
+	var problems []string
+	for _, msg := range validation.IsDNS1035Label(c.EndpointSliceService) {
+		problems = append(problems, i18n.T("config.endpointslice_service", c.EndpointSliceService, msg))
+	}
+	if len(c.EndpointSlicePorts) == 0 {
+		problems = append(problems, i18n.T("config.endpointslice_ports_required"))
+	}
+	for _, port := range c.EndpointSlicePorts {
+		if port.Port <= 0 || port.Port > 65535 {
+			problems = append(problems, i18n.T("config.endpointslice_port_range", port))
+		}
+		switch port.Protocol {
+		case "TCP", "UDP", "SCTP":
+		default:
+			problems = append(problems, i18n.T("config.endpointslice_protocol", port))
+		}
+	}
+	return problems
+}
+
+// validateOpenTelemetry 校验OTLP接收端地址和追踪配置
+func (c *Config) validateOpenTelemetry() []string {
+	var problems []string
+	if c.OTLPEndpoint != "" {
+		if u, err := url.Parse(c.OTLPEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, i18n.T("config.otlp_endpoint", c.OTLPEndpoint))
+		}
+	}
+	if c.TracingEnabled && c.OTLPEndpoint == "" {
+		problems = append(problems, i18n.T("config.tracing_endpoint_required"))
+	}
+	if c.OTLPMetricsEnabled {
+		if c.OTLPEndpoint == "" {
+			problems = append(problems, i18n.T("config.otlp_metrics_endpoint_required"))
+		}
+		if c.OTLPMetricsInterval <= 0 {
+			problems = append(problems, i18n.T("config.otlp_metrics_interval", c.OTLPMetricsInterval))
+		}
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		problems = append(problems, i18n.T("config.tracing_sample_ratio", c.TracingSampleRatio))
+	}
+	return problems
+}
+
+// validateStatsD 校验StatsD接收端地址、发送间隔和标签
+func (c *Config) validateStatsD() []string {
+	var problems []string
+	if _, port, err := net.SplitHostPort(c.StatsDAddress); err != nil || port == "" {
+		problems = append(problems, i18n.T("config.statsd_address", c.StatsDAddress))
+	}
+	if c.StatsDFlushInterval <= 0 {
+		problems = append(problems, i18n.T("config.statsd_flush_interval", c.StatsDFlushInterval))
+	}
+	// DogStatsD协议中|和,是分隔符
+	for _, tag := range c.StatsDTags {
+		if tag == "" || strings.ContainsAny(tag, "|,#\n") {
+			problems = append(problems, i18n.T("config.statsd_tag", tag))
+		}
+	}
+	return problems
+}
+
+// validateListenPorts 校验可选的监听端口，端口之间以及与HTTP_PORT不能冲突
+func (c *Config) validateListenPorts() []string {
+	var problems []string
+	used := map[string]string{c.HttpPort: "HTTP_PORT (http.port)"}
+	ports := []struct {
+		name  string
+		value string
+	}{
+		{"AGENT_CHECK_PORT (agentCheck.port)", c.AgentCheckPort},
+		{"GRPC_HEALTH_PORT (grpcHealth.port)", c.GRPCHealthPort},
+	}
+	for _, field := range ports {
+		if field.value == "" {
+			continue
+		}
+		if port, err := strconv.Atoi(field.value); err != nil || port <= 0 || port > 65535 {
+			problems = append(problems, i18n.T("common.invalid_port", field.name, field.value))
+			continue
+		}
+		if other, exists := used[field.value]; exists {
+			problems = append(problems, i18n.T("config.port_conflict", field.name, field.value, other))
+			continue
+		}
+		used[field.value] = field.name
+	}
+	return problems
+}
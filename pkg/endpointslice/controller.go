@@ -0,0 +1,327 @@
+package endpointslice
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+
+	"metrics-sidecar/pkg/config"
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/k8s"
+	"metrics-sidecar/pkg/logger"
+)
+
+// ManagedBy 写入endpointslice.kubernetes.io/managed-by标签的值，
+// 用于和EndpointSlice控制器管理的切片区分
+const ManagedBy = "metrics-sidecar.io"
+
+const (
+	// 更新失败后的重试间隔
+	retryInterval = 5 * time.Second
+	// 定期检查EndpointSlice的间隔，作为监听之外的兜底
+	resyncInterval = time.Minute
+	// EndpointSlice名称的最大长度
+	maxNameLength = 253
+)
+
+var (
+	// EndpointSlice控制的日志器
+	sliceLog = logger.GetLogger("endpointslice")
+)
+
+// Controller 为当前Pod维护一个独占的EndpointSlice，直接设置其中端点的
+// serving/terminating状态。用于没有selector、由外部管理端点的Service。
+// 决策在锁内同步通知，实际的API调用在Run的goroutine中异步完成
+type Controller struct {
+	client  *k8s.Client
+	service string
+	ports   []discoveryv1.EndpointPort
+	trigger chan struct{}
+
+	mu          sync.Mutex
+	serving     *bool       // 最近一次决策是否可以接收流量，nil表示尚未决策
+	pod         *corev1.Pod // 最近一次观察到的当前Pod
+	terminating bool        // 收到终止信号后置为true
+
+	// 串行化对EndpointSlice的写入，Run和Terminate可能并发调用
+	syncMu sync.Mutex
+}
+
+// NewController 创建EndpointSlice控制器
+func NewController(client *k8s.Client, service string, ports []config.EndpointPort) *Controller {
+	slicePorts := make([]discoveryv1.EndpointPort, 0, len(ports))
+	for _, port := range ports {
+		name, number, protocol := port.Name, port.Port, corev1.Protocol(port.Protocol)
+		slicePorts = append(slicePorts, discoveryv1.EndpointPort{
+			Name:     &name,
+			Port:     &number,
+			Protocol: &protocol,
+		})
+	}
+
+	return &Controller{
+		client:  client,
+		service: service,
+		ports:   slicePorts,
+		trigger: make(chan struct{}, 1),
+	}
+}
+
+// Report 记录最新决策，仅在是否可以接收流量变化时触发更新，不会阻塞
+func (c *Controller) Report(serving bool) {
+	c.mu.Lock()
+	changed := c.serving == nil || *c.serving != serving
+	if changed {
+		c.serving = &serving
+	}
+	c.mu.Unlock()
+
+	if changed {
+		c.notify()
+	}
+}
+
+// notify 触发一次同步，已有待处理的触发时忽略
+func (c *Controller) notify() {
+	select {
+	case c.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// setPod 记录当前Pod，地址、节点或删除状态变化时触发同步
+func (c *Controller) setPod(pod *corev1.Pod) {
+	c.mu.Lock()
+	changed := c.pod == nil || c.pod.Status.PodIP != pod.Status.PodIP ||
+		c.pod.Spec.NodeName != pod.Spec.NodeName ||
+		(c.pod.DeletionTimestamp == nil) != (pod.DeletionTimestamp == nil)
+	c.pod = pod
+	c.mu.Unlock()
+
+	if changed {
+		c.notify()
+	}
+}
+
+// Run 监听当前Pod和EndpointSlice，将决策写入EndpointSlice直到ctx结束。
+// EndpointSlice被外部修改或删除时会重新写入
+func (c *Controller) Run(ctx context.Context) {
+	go c.client.WatchPod(ctx, c.setPod)
+	go c.watchSlice(ctx)
+
+	retry := time.NewTimer(retryInterval)
+	retry.Stop()
+	resync := time.NewTicker(resyncInterval)
+	defer resync.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			retry.Stop()
+			return
+		case <-c.trigger:
+		case <-retry.C:
+		case <-resync.C:
+		}
+
+		if err := c.sync(ctx); err != nil {
+			sliceLog.WithError(err).Warn(i18n.T("endpointslice.sync_failed"))
+			retry.Reset(retryInterval)
+		}
+	}
+}
+
+// Terminate 将端点标记为terminating并立即写入，在sidecar收到终止信号时调用，
+// 使外部Service在进程退出前停止转发新流量
+func (c *Controller) Terminate(ctx context.Context) error {
+	c.mu.Lock()
+	c.terminating = true
+	c.mu.Unlock()
+	return c.sync(ctx)
+}
+
+// watchSlice 监听自身的EndpointSlice，被修改或删除时触发同步
+func (c *Controller) watchSlice(ctx context.Context) {
+	factory := informers.NewSharedInformerFactoryWithOptions(c.client.KubeClient, resyncInterval,
+		informers.WithNamespace(c.client.Config.Namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", c.sliceName()).String()
+		}),
+	)
+
+	informer := factory.Discovery().V1().EndpointSlices().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, _ interface{}) { c.notify() },
+		DeleteFunc: func(_ interface{}) { c.notify() },
+	})
+
+	factory.Start(ctx.Done())
+	<-ctx.Done()
+	factory.Shutdown()
+}
+
+// sliceName 当前Pod的EndpointSlice名称
+func (c *Controller) sliceName() string {
+	return SliceName(c.service, c.client.Config.PodName)
+}
+
+// sync 将期望状态写入EndpointSlice，与现有内容一致时不做修改
+func (c *Controller) sync(ctx context.Context) error {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+
+	c.mu.Lock()
+	serving, pod, terminating := c.serving, c.pod, c.terminating
+	c.mu.Unlock()
+
+	// 尚未决策或Pod尚未分配地址时无法构造端点
+	if serving == nil || pod == nil || pod.Status.PodIP == "" {
+		return nil
+	}
+	desired := BuildSlice(c.service, pod, c.ports, *serving, terminating || pod.DeletionTimestamp != nil)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	slices := c.client.KubeClient.DiscoveryV1().EndpointSlices(c.client.Config.Namespace)
+
+	existing, err := slices.Get(ctx, desired.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := slices.Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			return i18n.Errorf("endpointslice.create_failed", desired.Name, err)
+		}
+		c.logApplied(desired, i18n.T("endpointslice.created"))
+		return nil
+	}
+	if err != nil {
+		return i18n.Errorf("endpointslice.get_failed", desired.Name, err)
+	}
+
+	if managedBy := existing.Labels[discoveryv1.LabelManagedBy]; managedBy != ManagedBy {
+		return i18n.Errorf("endpointslice.foreign", desired.Name, managedBy)
+	}
+	if SliceUpToDate(existing, desired) {
+		return nil
+	}
+
+	// addressType创建后不可修改，Pod地址族变化时需要重建
+	if existing.AddressType != desired.AddressType {
+		if err := slices.Delete(ctx, existing.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return i18n.Errorf("endpointslice.delete_failed", existing.Name, err)
+		}
+		if _, err := slices.Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			return i18n.Errorf("endpointslice.create_failed", desired.Name, err)
+		}
+		c.logApplied(desired, i18n.T("endpointslice.recreated"))
+		return nil
+	}
+
+	updated := existing.DeepCopy()
+	if updated.Labels == nil {
+		updated.Labels = map[string]string{}
+	}
+	for key, value := range desired.Labels {
+		updated.Labels[key] = value
+	}
+	updated.OwnerReferences = desired.OwnerReferences
+	updated.Endpoints = desired.Endpoints
+	updated.Ports = desired.Ports
+	if _, err := slices.Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		return i18n.Errorf("endpointslice.update_failed", desired.Name, err)
+	}
+	c.logApplied(desired, i18n.T("endpointslice.updated"))
+	return nil
+}
+
+// logApplied 记录写入后的端点状态
+func (c *Controller) logApplied(slice *discoveryv1.EndpointSlice, msg string) {
+	conditions := slice.Endpoints[0].Conditions
+	sliceLog.WithFields(logrus.Fields{
+		"endpointslice": slice.Name,
+		"service":       c.service,
+		"serving":       *conditions.Serving,
+		"terminating":   *conditions.Terminating,
+	}).Info(msg)
+}
+
+// SliceName 返回Pod在Service下独占的EndpointSlice名称
+func SliceName(service, podName string) string {
+	name := service + "-" + podName
+	if len(name) > maxNameLength {
+		name = strings.TrimRight(name[:maxNameLength], "-.")
+	}
+	return name
+}
+
+// BuildSlice 构造只包含当前Pod一个端点的EndpointSlice。
+// 按Kubernetes的约定，ready在serving且未terminating时为true
+func BuildSlice(service string, pod *corev1.Pod, ports []discoveryv1.EndpointPort, serving, terminating bool) *discoveryv1.EndpointSlice {
+	addressType := discoveryv1.AddressTypeIPv4
+	if strings.Contains(pod.Status.PodIP, ":") {
+		addressType = discoveryv1.AddressTypeIPv6
+	}
+
+	ready := serving && !terminating
+	endpoint := discoveryv1.Endpoint{
+		Addresses: []string{pod.Status.PodIP},
+		Conditions: discoveryv1.EndpointConditions{
+			Ready:       &ready,
+			Serving:     &serving,
+			Terminating: &terminating,
+		},
+		TargetRef: &corev1.ObjectReference{
+			Kind:      "Pod",
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			UID:       pod.UID,
+		},
+	}
+	if pod.Spec.NodeName != "" {
+		nodeName := pod.Spec.NodeName
+		endpoint.NodeName = &nodeName
+	}
+
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      SliceName(service, pod.Name),
+			Namespace: pod.Namespace,
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName: service,
+				discoveryv1.LabelManagedBy:   ManagedBy,
+			},
+			// Pod删除后由垃圾回收清理EndpointSlice
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "v1",
+				Kind:       "Pod",
+				Name:       pod.Name,
+				UID:        pod.UID,
+			}},
+		},
+		AddressType: addressType,
+		Endpoints:   []discoveryv1.Endpoint{endpoint},
+		Ports:       ports,
+	}
+}
+
+// SliceUpToDate 判断现有EndpointSlice是否已与期望内容一致，其他标签和字段不做比较
+func SliceUpToDate(existing, desired *discoveryv1.EndpointSlice) bool {
+	for key, value := range desired.Labels {
+		if existing.Labels[key] != value {
+			return false
+		}
+	}
+	return existing.AddressType == desired.AddressType &&
+		apiequality.Semantic.DeepEqual(existing.OwnerReferences, desired.OwnerReferences) &&
+		apiequality.Semantic.DeepEqual(existing.Endpoints, desired.Endpoints) &&
+		apiequality.Semantic.DeepEqual(existing.Ports, desired.Ports)
+}
@@ -0,0 +1,94 @@
+package endpointslice
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testPod(ip string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-7d9f-abcde", Namespace: "default", UID: "uid-1"},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+		Status:     corev1.PodStatus{PodIP: ip},
+	}
+}
+
+func testPorts() []discoveryv1.EndpointPort {
+	name, port, protocol := "http", int32(8080), corev1.ProtocolTCP
+	return []discoveryv1.EndpointPort{{Name: &name, Port: &port, Protocol: &protocol}}
+}
+
+func TestBuildSliceConditions(t *testing.T) {
+	tests := []struct {
+		serving, terminating bool
+		expectedReady        bool
+	}{
+		{serving: true, terminating: false, expectedReady: true},
+		{serving: false, terminating: false, expectedReady: false},
+		{serving: true, terminating: true, expectedReady: false},
+	}
+	for _, tt := range tests {
+		slice := BuildSlice("external", testPod("10.0.0.5"), testPorts(), tt.serving, tt.terminating)
+		conditions := slice.Endpoints[0].Conditions
+		if *conditions.Ready != tt.expectedReady || *conditions.Serving != tt.serving || *conditions.Terminating != tt.terminating {
+			t.Errorf("BuildSlice(serving=%v, terminating=%v)的端点状态 = ready=%v serving=%v terminating=%v; 期望 ready=%v",
+				tt.serving, tt.terminating, *conditions.Ready, *conditions.Serving, *conditions.Terminating, tt.expectedReady)
+		}
+	}
+}
+
+func TestBuildSliceMetadata(t *testing.T) {
+	slice := BuildSlice("external", testPod("10.0.0.5"), testPorts(), true, false)
+	if slice.Name != "external-app-7d9f-abcde" {
+		t.Errorf("EndpointSlice名称 = %s; 期望 external-app-7d9f-abcde", slice.Name)
+	}
+	if slice.Labels[discoveryv1.LabelServiceName] != "external" || slice.Labels[discoveryv1.LabelManagedBy] != ManagedBy {
+		t.Errorf("EndpointSlice标签 = %v; 期望包含Service名称和managed-by", slice.Labels)
+	}
+	if slice.AddressType != discoveryv1.AddressTypeIPv4 {
+		t.Errorf("IPv4地址的addressType = %s; 期望 IPv4", slice.AddressType)
+	}
+	if len(slice.OwnerReferences) != 1 || slice.OwnerReferences[0].UID != "uid-1" {
+		t.Errorf("ownerReferences = %v; 期望指向当前Pod", slice.OwnerReferences)
+	}
+
+	ipv6 := BuildSlice("external", testPod("fd00::5"), testPorts(), true, false)
+	if ipv6.AddressType != discoveryv1.AddressTypeIPv6 {
+		t.Errorf("IPv6地址的addressType = %s; 期望 IPv6", ipv6.AddressType)
+	}
+}
+
+func TestSliceName(t *testing.T) {
+	name := SliceName(strings.Repeat("s", 200), strings.Repeat("p", 100))
+	if len(name) > maxNameLength {
+		t.Errorf("SliceName长度 = %d; 期望不超过%d", len(name), maxNameLength)
+	}
+}
+
+func TestSliceUpToDate(t *testing.T) {
+	desired := BuildSlice("external", testPod("10.0.0.5"), testPorts(), true, false)
+
+	existing := desired.DeepCopy()
+	existing.Labels["team"] = "payments"
+	existing.ResourceVersion = "42"
+	if !SliceUpToDate(existing, desired) {
+		t.Error("仅有额外标签和元数据不同时SliceUpToDate = false; 期望 true")
+	}
+
+	drifted := desired.DeepCopy()
+	serving := false
+	drifted.Endpoints[0].Conditions.Serving = &serving
+	if SliceUpToDate(drifted, desired) {
+		t.Error("端点状态被外部修改时SliceUpToDate = true; 期望 false")
+	}
+
+	relabeled := desired.DeepCopy()
+	delete(relabeled.Labels, discoveryv1.LabelServiceName)
+	if SliceUpToDate(relabeled, desired) {
+		t.Error("缺少Service名称标签时SliceUpToDate = true; 期望 false")
+	}
+}
@@ -0,0 +1,157 @@
+package grpchealth
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"metrics-sidecar/pkg/handlers"
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/logger"
+)
+
+const (
+	// 单次Check的超时，包括收集指标
+	checkTimeout = 5 * time.Second
+	// 关闭时等待进行中请求的时间，Watch流不会主动结束，超时后强制关闭
+	shutdownTimeout = 2 * time.Second
+)
+
+var (
+	// gRPC健康检查服务的日志器
+	grpcLog = logger.GetLogger("grpchealth")
+)
+
+// Server 提供标准的grpc.health.v1.Health服务。
+// Check在EVALUATION_INTERVAL内返回最近一次决策，过期时重新决策；Watch由决策监听器推送状态变化，
+// 需要配合HealthHandler.RunPeriodic定期决策
+type Server struct {
+	*health.Server // 保存各服务的最新状态并实现Watch
+
+	addr          string
+	services      []string // 响应的服务名，始终包含空服务名
+	healthHandler *handlers.HealthHandler
+	grpcServer    *grpc.Server
+	registrations []func(*grpc.Server) error // 同一端口上的其他gRPC服务
+}
+
+// NewServer 创建gRPC健康检查服务，service非空时额外响应该服务名
+func NewServer(port, service string, healthHandler *handlers.HealthHandler) *Server {
+	s := &Server{
+		Server:        health.NewServer(),
+		addr:          ":" + port,
+		services:      []string{""},
+		healthHandler: healthHandler,
+	}
+	if service != "" {
+		s.services = append(s.services, service)
+	}
+
+	// 首次决策之前不接收流量
+	for _, name := range s.services {
+		s.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	return s
+}
+
+// Listener 返回决策监听器，将决策写入各服务的状态，状态变化时推送给Watch
+func (s *Server) Listener() handlers.DecisionListener {
+	return func(_, current *handlers.Decision) {
+		servingStatus := ServingStatus(current)
+		for _, name := range s.services {
+			s.SetServingStatus(name, servingStatus)
+		}
+	}
+}
+
+// AddService 在同一端口上注册其他gRPC服务，应在Run之前调用
+func (s *Server) AddService(register func(*grpc.Server) error) {
+	s.registrations = append(s.registrations, register)
+}
+
+// Check 返回最近一次决策（过期时重新决策）对应的状态，未知的服务名返回NotFound
+func (s *Server) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if !s.known(req.Service) {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.Service)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	decision, err := s.healthHandler.CachedDecision(ctx)
+	if err != nil {
+		grpcLog.WithError(err).Error(i18n.T("grpchealth.decision_failed"))
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	}
+
+	grpcLog.WithFields(logrus.Fields{
+		"service": req.Service,
+		"status":  decision.Status,
+	}).Debug(i18n.T("grpchealth.responded"))
+	return &healthpb.HealthCheckResponse{Status: ServingStatus(decision)}, nil
+}
+
+// known 判断是否响应该服务名
+func (s *Server) known(service string) bool {
+	for _, name := range s.services {
+		if name == service {
+			return true
+		}
+	}
+	return false
+}
+
+// Run 监听端口并提供服务，直到ctx结束后优雅关闭
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return i18n.Errorf("grpchealth.listen_failed", err)
+	}
+
+	s.grpcServer = grpc.NewServer()
+	healthpb.RegisterHealthServer(s.grpcServer, s)
+	for _, register := range s.registrations {
+		if err := register(s.grpcServer); err != nil {
+			listener.Close()
+			return i18n.Errorf("grpchealth.register_failed", err)
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+		// 先将所有服务置为NOT_SERVING推送给Watch，再等待进行中的请求完成
+		s.Shutdown()
+		stopped := make(chan struct{})
+		go func() {
+			s.grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(shutdownTimeout):
+			s.grpcServer.Stop()
+		}
+	}()
+
+	grpcLog.WithField("addr", s.addr).Info(i18n.T("grpchealth.started"))
+	if err := s.grpcServer.Serve(listener); err != nil {
+		return i18n.Errorf("grpchealth.serve_failed", err)
+	}
+	return nil
+}
+
+// ServingStatus 将决策映射为gRPC健康状态：卸载流量或容器未就绪时为NOT_SERVING，
+// HEALTHY、RESOURCE_OVERLOADED_BUT_KEEPING和POD_SHORTAGE时为SERVING
+func ServingStatus(decision *handlers.Decision) healthpb.HealthCheckResponse_ServingStatus {
+	if decision.Shedding() || decision.Status == handlers.StatusNotReady {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
@@ -0,0 +1,96 @@
+package grpchealth
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"metrics-sidecar/pkg/handlers"
+)
+
+func TestServingStatus(t *testing.T) {
+	tests := map[string]healthpb.HealthCheckResponse_ServingStatus{
+		handlers.StatusHealthy:                      healthpb.HealthCheckResponse_SERVING,
+		handlers.StatusResourceOverloadedButKeeping: healthpb.HealthCheckResponse_SERVING,
+		handlers.StatusPodShortage:                  healthpb.HealthCheckResponse_SERVING,
+		handlers.StatusResourceExhausted:            healthpb.HealthCheckResponse_NOT_SERVING,
+		handlers.StatusNotReady:                     healthpb.HealthCheckResponse_NOT_SERVING,
+	}
+	for decisionStatus, expected := range tests {
+		if got := ServingStatus(&handlers.Decision{Status: decisionStatus}); got != expected {
+			t.Errorf("ServingStatus(%s) = %s; 期望 %s", decisionStatus, got, expected)
+		}
+	}
+}
+
+// startTestServer 在内存连接上启动服务，返回客户端
+func startTestServer(t *testing.T, s *Server) healthpb.HealthClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, s)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("连接测试服务失败: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestWatchStreamsTransitions(t *testing.T) {
+	s := NewServer("0", "example.v1.Echo", nil)
+	client := startTestServer(t, s)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "example.v1.Echo"})
+	if err != nil {
+		t.Fatalf("Watch返回错误: %v", err)
+	}
+
+	expect := func(expected healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("接收Watch状态失败: %v", err)
+		}
+		if resp.Status != expected {
+			t.Errorf("Watch状态 = %s; 期望 %s", resp.Status, expected)
+		}
+	}
+
+	// 首次决策之前为NOT_SERVING
+	expect(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	listener := s.Listener()
+	listener(nil, &handlers.Decision{Status: handlers.StatusHealthy})
+	expect(healthpb.HealthCheckResponse_SERVING)
+
+	// 状态未变化时不推送，下一条消息是卸载流量
+	listener(nil, &handlers.Decision{Status: handlers.StatusResourceOverloadedButKeeping})
+	listener(nil, &handlers.Decision{Status: handlers.StatusResourceExhausted})
+	expect(healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+func TestCheckUnknownService(t *testing.T) {
+	client := startTestServer(t, NewServer("0", "", nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Check(unknown)的错误码 = %s; 期望 NotFound", status.Code(err))
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/telemetry"
+)
+
+// DecisionMetrics 关于sidecar自身决策行为的计数器和仪表，
+// 可用于告警长时间未恢复接收流量的Pod
+type DecisionMetrics struct {
+	decisions        *prometheus.CounterVec
+	randomDraws      *prometheus.CounterVec
+	sheddingSeconds  prometheus.Counter
+	sheddingEpisodes prometheus.Counter
+	state            *prometheus.GaugeVec
+	stateSince       prometheus.Gauge
+	weight           prometheus.Gauge
+	lastDecision     prometheus.Gauge
+}
+
+// NewDecisionMetrics 创建决策指标并注册到registry
+func NewDecisionMetrics(registry prometheus.Registerer) *DecisionMetrics {
+	m := &DecisionMetrics{
+		decisions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: telemetry.Namespace,
+			Name:      "decisions_total",
+			Help:      i18n.T("metric.decisions"),
+		}, []string{"status"}),
+		randomDraws: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: telemetry.Namespace,
+			Name:      "random_draws_total",
+			Help:      i18n.T("metric.random_draws"),
+		}, []string{"result"}),
+		sheddingSeconds: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: telemetry.Namespace,
+			Name:      "shedding_seconds_total",
+			Help:      i18n.T("metric.shedding_seconds"),
+		}),
+		sheddingEpisodes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: telemetry.Namespace,
+			Name:      "shedding_episodes_total",
+			Help:      i18n.T("metric.shedding_episodes"),
+		}),
+		state: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: telemetry.Namespace,
+			Name:      "state",
+			Help:      i18n.T("metric.state"),
+		}, []string{"status"}),
+		stateSince: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: telemetry.Namespace,
+			Name:      "state_since_timestamp_seconds",
+			Help:      i18n.T("metric.state_since"),
+		}),
+		weight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: telemetry.Namespace,
+			Name:      "weight",
+			Help:      i18n.T("metric.weight"),
+		}),
+		lastDecision: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: telemetry.Namespace,
+			Name:      "last_decision_timestamp_seconds",
+			Help:      i18n.T("metric.last_decision"),
+		}),
+	}
+
+	// 预先创建所有状态的序列，未出现过的状态也输出0
+	for _, status := range AllStatuses {
+		m.decisions.WithLabelValues(status)
+		m.state.WithLabelValues(status)
+	}
+	m.randomDraws.WithLabelValues("shed")
+	m.randomDraws.WithLabelValues("keep")
+
+	registry.MustRegister(m.decisions, m.randomDraws, m.sheddingSeconds, m.sheddingEpisodes,
+		m.state, m.stateSince, m.weight, m.lastDecision)
+	return m
+}
+
+// Listener 返回更新决策指标的监听器
+func (m *DecisionMetrics) Listener() DecisionListener {
+	return m.observe
+}
+
+// observe 根据相邻两次决策更新指标
+func (m *DecisionMetrics) observe(previous, current *Decision) {
+	m.decisions.WithLabelValues(current.Status).Inc()
+	if current.RandomValue != nil {
+		result := "keep"
+		if current.Shedding() {
+			result = "shed"
+		}
+		m.randomDraws.WithLabelValues(result).Inc()
+	}
+
+	// 上一次决策拒绝流量时，两次决策之间的时间计入拒绝流量的累计时间
+	if previous != nil && previous.Shedding() {
+		if elapsed := current.Time.Sub(previous.Time).Seconds(); elapsed > 0 {
+			m.sheddingSeconds.Add(elapsed)
+		}
+	}
+	if current.Shedding() && (previous == nil || !previous.Shedding()) {
+		m.sheddingEpisodes.Inc()
+	}
+
+	if previous == nil || previous.Status != current.Status {
+		for _, status := range AllStatuses {
+			value := 0.0
+			if status == current.Status {
+				value = 1
+			}
+			m.state.WithLabelValues(status).Set(value)
+		}
+		m.stateSince.Set(float64(current.Time.UnixNano()) / 1e9)
+	}
+	m.weight.Set(float64(current.Weight))
+	m.lastDecision.Set(float64(current.Time.UnixNano()) / 1e9)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestDecisionMetrics(t *testing.T) {
+	m := NewDecisionMetrics(prometheus.NewRegistry())
+	listener := m.Listener()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	randomValue := 90.0
+	decisions := []*Decision{
+		{Time: start, Status: StatusHealthy, Weight: 100},
+		{Time: start.Add(10 * time.Second), Status: StatusResourceExhausted, RandomValue: &randomValue},
+		{Time: start.Add(40 * time.Second), Status: StatusResourceExhausted},
+		{Time: start.Add(60 * time.Second), Status: StatusHealthy, Weight: 80},
+	}
+	var previous *Decision
+	for _, decision := range decisions {
+		listener(previous, decision)
+		previous = decision
+	}
+
+	if got := testutil.ToFloat64(m.decisions.WithLabelValues(StatusResourceExhausted)); got != 2 {
+		t.Errorf("RESOURCE_EXHAUSTED决策次数 = %v; 期望 2", got)
+	}
+	if got := testutil.ToFloat64(m.sheddingSeconds); got != 50 {
+		t.Errorf("拒绝流量累计时间 = %v; 期望 50", got)
+	}
+	if got := testutil.ToFloat64(m.sheddingEpisodes); got != 1 {
+		t.Errorf("拒绝流量次数 = %v; 期望 1", got)
+	}
+	if got := testutil.ToFloat64(m.randomDraws.WithLabelValues("shed")); got != 1 {
+		t.Errorf("随机值抽取次数(shed) = %v; 期望 1", got)
+	}
+	if healthy, exhausted := testutil.ToFloat64(m.state.WithLabelValues(StatusHealthy)),
+		testutil.ToFloat64(m.state.WithLabelValues(StatusResourceExhausted)); healthy != 1 || exhausted != 0 {
+		t.Errorf("当前状态仪表 HEALTHY=%v, RESOURCE_EXHAUSTED=%v; 期望 1, 0", healthy, exhausted)
+	}
+	if got := testutil.ToFloat64(m.stateSince); got != float64(start.Add(60*time.Second).Unix()) {
+		t.Errorf("进入当前状态的时间戳 = %v; 期望恢复健康的时间", got)
+	}
+	if got := testutil.ToFloat64(m.weight); got != 80 {
+		t.Errorf("当前权重 = %v; 期望 80", got)
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"metrics-sidecar/pkg/i18n"
+)
+
+// Kubernetes事件的原因
+const (
+	EventReasonSheddingStarted    = "SheddingStarted"    // 开始拒绝流量
+	EventReasonSheddingStopped    = "SheddingStopped"    // 恢复接收流量
+	EventReasonPodShortage        = "PodShortage"        // 可用Pod不足，过载时也保持服务
+	EventReasonMetricsUnavailable = "MetricsUnavailable" // 无法获取Pod度量指标
+	EventReasonMetricsRecovered   = "MetricsRecovered"   // Pod度量指标恢复
+)
+
+// EventRecorder 在当前Pod上记录Kubernetes事件，由k8s.Client实现
+type EventRecorder interface {
+	RecordPodEvent(eventType, reason, message string)
+}
+
+// podEvent 一个待记录的事件
+type podEvent struct {
+	eventType string
+	reason    string
+	message   string
+}
+
+// NewEventListener 返回在状态转换时记录Kubernetes事件的决策监听器。
+// 事件由EventRecorder异步发送，不会阻塞决策
+func NewEventListener(recorder EventRecorder) DecisionListener {
+	return func(previous, current *Decision) {
+		for _, event := range transitionEvents(previous, current) {
+			recorder.RecordPodEvent(event.eventType, event.reason, event.message)
+		}
+	}
+}
+
+// transitionEvents 比较两次决策，返回需要记录的事件，状态未变化时返回空
+func transitionEvents(previous, current *Decision) []podEvent {
+	var events []podEvent
+
+	previousStatus := ""
+	previousMetricsError := ""
+	if previous != nil {
+		previousStatus = previous.Status
+		previousMetricsError = previous.Metrics.MetricsError
+	}
+
+	if current.Status != previousStatus {
+		switch {
+		case current.Status == StatusResourceExhausted:
+			events = append(events, podEvent{corev1.EventTypeWarning, EventReasonSheddingStarted, current.Message})
+		case previousStatus == StatusResourceExhausted:
+			events = append(events, podEvent{corev1.EventTypeNormal, EventReasonSheddingStopped,
+				i18n.T("event.shedding_stopped", current.Status)})
+		}
+		if current.Status == StatusPodShortage {
+			events = append(events, podEvent{corev1.EventTypeWarning, EventReasonPodShortage, current.Message})
+		}
+	}
+
+	currentMetricsError := current.Metrics.MetricsError
+	switch {
+	case currentMetricsError != "" && previousMetricsError == "":
+		events = append(events, podEvent{corev1.EventTypeWarning, EventReasonMetricsUnavailable,
+			i18n.T("event.metrics_unavailable", currentMetricsError)})
+	case currentMetricsError == "" && previousMetricsError != "":
+		events = append(events, podEvent{corev1.EventTypeNormal, EventReasonMetricsRecovered, i18n.T("event.metrics_recovered")})
+	}
+
+	return events
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"testing"
+
+	"metrics-sidecar/pkg/metrics"
+)
+
+// 记录事件原因的EventRecorder
+type fakeRecorder struct {
+	reasons []string
+}
+
+func (r *fakeRecorder) RecordPodEvent(eventType, reason, message string) {
+	r.reasons = append(r.reasons, reason)
+}
+
+// 创建指定状态的决策
+func decisionWith(status, metricsError string) *Decision {
+	return &Decision{
+		Status:  status,
+		Metrics: &metrics.ResourceMetrics{MetricsError: metricsError},
+	}
+}
+
+func TestEventListener(t *testing.T) {
+	recorder := &fakeRecorder{}
+	listener := NewEventListener(recorder)
+
+	steps := []struct {
+		decision *Decision
+		expected []string
+	}{
+		{decisionWith(StatusHealthy, ""), nil},
+		{decisionWith(StatusHealthy, ""), nil},
+		{decisionWith(StatusResourceExhausted, ""), []string{EventReasonSheddingStarted}},
+		{decisionWith(StatusResourceExhausted, ""), nil},
+		{decisionWith(StatusPodShortage, ""), []string{EventReasonSheddingStopped, EventReasonPodShortage}},
+		{decisionWith(StatusHealthy, "metrics-server不可用"), []string{EventReasonMetricsUnavailable}},
+		{decisionWith(StatusHealthy, "metrics-server不可用"), nil},
+		{decisionWith(StatusHealthy, ""), []string{EventReasonMetricsRecovered}},
+	}
+
+	var previous *Decision
+	for i, step := range steps {
+		recorder.reasons = nil
+		listener(previous, step.decision)
+		previous = step.decision
+
+		if len(recorder.reasons) != len(step.expected) {
+			t.Errorf("第%d步记录的事件 = %v; 期望 %v", i+1, recorder.reasons, step.expected)
+			continue
+		}
+		for j, reason := range step.expected {
+			if recorder.reasons[j] != reason {
+				t.Errorf("第%d步记录的事件 = %v; 期望 %v", i+1, recorder.reasons, step.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestEventListenerFirstDecision(t *testing.T) {
+	recorder := &fakeRecorder{}
+	NewEventListener(recorder)(nil, decisionWith(StatusResourceExhausted, ""))
+	if len(recorder.reasons) != 1 || recorder.reasons[0] != EventReasonSheddingStarted {
+		t.Errorf("首次决策记录的事件 = %v; 期望 [%s]", recorder.reasons, EventReasonSheddingStarted)
+	}
+}
@@ -3,25 +3,61 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"metrics-sidecar/pkg/config"
+	"metrics-sidecar/pkg/i18n"
 	"metrics-sidecar/pkg/k8s"
 	"metrics-sidecar/pkg/logger"
 	"metrics-sidecar/pkg/metrics"
+	"metrics-sidecar/pkg/probe"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
-var (
-	resourceOverLoaded bool
-	rng                *rand.Rand // 用于生成随机数的随机数生成器
+// 健康检查状态
+const (
+	StatusHealthy                      = "HEALTHY"                         // 资源使用正常
+	StatusNotReady                     = "NOT_READY"                       // 目标容器尚未就绪
+	StatusPodShortage                  = "POD_SHORTAGE"                    // 可用Pod比例低于最小阈值，保持服务
+	StatusResourceExhausted            = "RESOURCE_EXHAUSTED"              // 资源过载，拒绝流量
+	StatusResourceOverloadedButKeeping = "RESOURCE_OVERLOADED_BUT_KEEPING" // 资源过载但随机退避后继续服务
+)
+
+// AllStatuses 所有健康检查状态，用于初始化和上报按状态区分的指标
+var AllStatuses = []string{
+	StatusHealthy,
+	StatusNotReady,
+	StatusPodShortage,
+	StatusResourceExhausted,
+	StatusResourceOverloadedButKeeping,
+}
 
-	// 标记是否继续进行随机决策，初始为true表示需要随机决策
-	shouldRandomize = true
+// 决策原因代码，不随语言变化且保持稳定，与人类可读的消息一起出现在响应和日志中
+const (
+	ReasonContainerNotReady       = "CONTAINER_NOT_READY"         // 目标容器尚未就绪
+	ReasonAppReadinessProbeFailed = "APP_READINESS_PROBE_FAILED"  // 代为执行的应用就绪探针失败
+	ReasonPodAvailabilityLow      = "POD_AVAILABILITY_LOW"        // 可用Pod比例低于最小阈值
+	ReasonWithinThresholds        = "RESOURCES_WITHIN_THRESHOLDS" // 资源未过载
+	ReasonOverloadedShedding      = "OVERLOADED_SHEDDING"         // 资源过载，之前的随机决策已决定拒绝流量
+	ReasonOverloadedRandomShed    = "OVERLOADED_RANDOM_SHED"      // 资源过载，本次随机值大于阈值，开始拒绝流量
+	ReasonOverloadedRandomKeep    = "OVERLOADED_RANDOM_KEEP"      // 资源过载，本次随机值不大于阈值，继续服务
+	ReasonMetricsUnavailable      = "METRICS_COLLECTION_FAILED"   // 无法收集资源指标，没有做出决策
+)
+
+var (
+	rng *rand.Rand // 用于生成随机数的随机数生成器
 
 	// 健康检查处理器的日志器
 	log = logger.GetLogger("health")
@@ -34,43 +70,178 @@ func init() {
 	rng = rand.New(source)
 }
 
+// Decision 一次健康检查的决策结果
+type Decision struct {
+	Time        time.Time
+	Status      string
+	StatusCode  int
+	Reason      string // 原因代码，如RESOURCES_WITHIN_THRESHOLDS
+	Message     string // 按当前语言生成的人类可读消息
+	Metrics     *metrics.ResourceMetrics
+	Config      *config.Config // 做出决策时生效的配置
+	RandomValue *float64       // 本次随机退避抽取的随机值，未抽取时为nil
+	Weight      int            // 期望的流量权重(0-100)，供支持权重的负载均衡器逐步减少流量
+}
+
+// Shedding 判断该决策是否拒绝流量
+func (d *Decision) Shedding() bool {
+	return d.Status == StatusResourceExhausted
+}
+
+// DecisionListener 在每次决策后被调用，previous为上一次决策，首次决策时为nil。
+// 监听器在决策锁内同步调用，必须快速返回
+type DecisionListener func(previous, current *Decision)
+
 // HealthHandler 健康检查处理器
 type HealthHandler struct {
 	K8sClient        *k8s.Client
 	MetricsCollector *metrics.MetricsCollector
-	Config           *config.Config
+	config           atomic.Pointer[config.Config] // 当前生效的配置，支持热更新时原子替换
+	prober           *probe.Prober                 // 执行应用原有的就绪探针
+
+	mu              sync.Mutex // 保护以下决策状态
+	shouldRandomize bool       // 标记是否继续进行随机决策，为true表示需要随机决策
+	last            *Decision  // 最近一次决策
+	listeners       []DecisionListener
 }
 
 // NewHealthHandler 创建新的健康检查处理器
 func NewHealthHandler(k8sClient *k8s.Client, metricsCollector *metrics.MetricsCollector, cfg *config.Config) *HealthHandler {
-	return &HealthHandler{
+	h := &HealthHandler{
 		K8sClient:        k8sClient,
 		MetricsCollector: metricsCollector,
-		Config:           cfg,
+		prober:           probe.NewProber(),
+		shouldRandomize:  true,
+	}
+	h.config.Store(cfg)
+	return h
+}
+
+// CurrentConfig 返回当前生效的配置
+func (h *HealthHandler) CurrentConfig() *config.Config {
+	return h.config.Load()
+}
+
+// SetConfig 原子替换当前生效的配置，正在处理的请求继续使用旧配置
+func (h *HealthHandler) SetConfig(cfg *config.Config) {
+	h.config.Store(cfg)
+}
+
+// AddListener 注册决策监听器，应在开始处理请求前调用
+func (h *HealthHandler) AddListener(listener DecisionListener) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.listeners = append(h.listeners, listener)
+}
+
+// LastDecision 返回最近一次决策，尚未决策时返回nil
+func (h *HealthHandler) LastDecision() *Decision {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.last
+}
+
+// CachedDecision 返回EVALUATION_INTERVAL内的最近一次决策，没有或已过期时重新决策。
+// 供负载均衡器频繁轮询的入口使用，避免每次轮询都收集指标并抽取新的随机值
+func (h *HealthHandler) CachedDecision(ctx context.Context) (*Decision, error) {
+	if last := h.LastDecision(); last != nil && time.Since(last.Time) < h.CurrentConfig().EvaluationInterval {
+		return last, nil
+	}
+	return h.Evaluate(ctx)
+}
+
+// Evaluate 收集资源指标并做出决策，所有健康检查入口共享同一份随机退避状态
+func (h *HealthHandler) Evaluate(ctx context.Context) (*Decision, error) {
+	// 整个决策使用同一份配置，避免处理过程中配置被替换
+	cfg := h.CurrentConfig()
+
+	// 按需收集指标
+	resourceMetrics, err := h.collectResourceMetrics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 决策span包括等待决策锁和调用监听器的时间
+	_, span := startSpan(ctx, "decide")
+	defer span.End()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	decision := h.decide(cfg, resourceMetrics)
+	span.SetAttributes(h.decisionAttributes(decision)...)
+	previous := h.last
+	h.last = decision
+	if previous == nil || previous.Status != decision.Status {
+		fields := logrus.Fields{"to": decision.Status, logger.FieldReasonCode: decision.Reason, "message": decision.Message}
+		if previous != nil {
+			fields["from"] = previous.Status
+		}
+		log.WithFields(fields).Info(i18n.T("health.state_changed"))
+	}
+	for _, listener := range h.listeners {
+		listener(previous, decision)
+	}
+	return decision, nil
+}
+
+// RunPeriodic 定期做出决策，供Pod状态条件等不依赖探针请求的功能使用，直到ctx结束。
+// 最近半个周期内已有决策（如探针请求触发）时跳过，避免额外抽取随机值
+func (h *HealthHandler) RunPeriodic(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if last := h.LastDecision(); last == nil || time.Since(last.Time) >= interval/2 {
+			if _, err := h.Evaluate(ctx); err != nil {
+				log.WithError(err).WithField(logger.FieldReasonCode, ReasonMetricsUnavailable).Warn(i18n.T("health.periodic_failed"))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
 }
 
 // ServeHTTP 实现http.Handler接口
 func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	startTime := time.Now()
+	level := pathLevel(h.CurrentConfig())
 	log.WithFields(logrus.Fields{
 		"method": r.Method,
 		"path":   r.URL.Path,
-	}).Info("开始处理健康检查请求")
+	}).Log(level, i18n.T("health.request_started"))
 
-	// 按需收集指标
-	resourceMetrics, err := h.collectResourceMetrics()
+	decision, err := h.Evaluate(r.Context())
 	if err != nil {
-		log.WithError(err).Error("健康检查失败")
+		trace.SpanFromContext(r.Context()).SetStatus(codes.Error, err.Error())
+		log.WithError(err).WithField(logger.FieldReasonCode, ReasonMetricsUnavailable).Error(i18n.T("health.failed"))
 		w.WriteHeader(http.StatusServiceUnavailable)
-		fmt.Fprintf(w, "健康检查失败: 无法收集资源指标 - %v", err)
+		h.writeJSONResponse(w, errorResponse(ReasonMetricsUnavailable, i18n.T("health.failed_response", err)))
 		return
 	}
 
-	// 输出详情
+	w.WriteHeader(decision.StatusCode)
+	h.writeJSONResponse(w, h.details(decision))
+	log.WithFields(logrus.Fields{
+		"status":               decision.Status,
+		logger.FieldReasonCode: decision.Reason,
+		"message":              decision.Message,
+		"duration":             time.Since(startTime),
+	}).Log(level, i18n.T("health.request_completed"))
+}
+
+// details 生成健康检查响应的详情
+func (h *HealthHandler) details(decision *Decision) map[string]interface{} {
+	cfg := decision.Config
+	resourceMetrics := decision.Metrics
+
 	details := make(map[string]interface{})
 	details["deployment"] = map[string]interface{}{
-		"name":                 h.Config.DeploymentName,
+		"name":                 cfg.DeploymentName,
 		"replicas":             resourceMetrics.DeploymentReplicas,
 		"available_replicas":   resourceMetrics.DeploymentAvailableReplicas,
 		"availability_percent": h.calcPodsRatio(resourceMetrics),
@@ -78,195 +249,262 @@ func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	details["container"] = map[string]interface{}{
 		"name":                 resourceMetrics.ContainerName,
 		"ready":                resourceMetrics.ContainerReady,
+		"not_ready_reason":     resourceMetrics.ContainerNotReadyReason,
 		"memory_usage_mb":      resourceMetrics.ContainerMemUsage,
 		"memory_limit_mb":      resourceMetrics.ContainerMemLimit,
+		"memory_basis":         resourceMetrics.ContainerMemBasis,
 		"memory_percent":       h.calcMemoryPercent(resourceMetrics),
 		"cpu_usage_millicores": resourceMetrics.ContainerCPUUsage,
 		"cpu_limit_millicores": resourceMetrics.ContainerCPULimit,
+		"cpu_basis":            resourceMetrics.ContainerCPUBasis,
 		"cpu_percent":          h.calcCPUPercent(resourceMetrics),
 	}
+	details["thresholds"] = map[string]interface{}{
+		"memory": h.memoryThresholdText(cfg),
+		"cpu":    h.cpuThresholdText(cfg),
+		"policy": cfg.OverloadPolicy,
+	}
+	details["status"] = decision.Status
+	details["reason_code"] = decision.Reason
+	details["message"] = decision.Message
+	details["weight"] = decision.Weight
+	return details
+}
 
-	// 检查容器状态并记录结果
-	status := "HEALTHY"
-	statusCode := http.StatusOK
-	var message string
+// decide 根据资源指标做出决策，调用方需持有h.mu
+func (h *HealthHandler) decide(cfg *config.Config, resourceMetrics *metrics.ResourceMetrics) *Decision {
+	decision := &Decision{
+		Time:       time.Now(),
+		Status:     StatusHealthy,
+		StatusCode: http.StatusOK,
+		Metrics:    resourceMetrics,
+		Config:     cfg,
+	}
 
 	// 1. 检查容器是否就绪
 	if !resourceMetrics.ContainerReady {
-		status = "NOT_READY"
-		message = fmt.Sprintf("容器 %s 尚未就绪", resourceMetrics.ContainerName)
-		log.WithField("status", status).Info(message)
-
-		details["status"] = status
-		details["message"] = message
-		w.WriteHeader(statusCode)
-		h.writeJSONResponse(w, details)
-		log.WithField("duration", time.Since(startTime)).Info("健康检查请求处理完成")
-		return
+		decision.Status = StatusNotReady
+		decision.Reason = ReasonContainerNotReady
+		decision.Message = i18n.T("decision.container_not_ready", resourceMetrics.ContainerName)
+		if resourceMetrics.ContainerNotReadyReason != "" {
+			decision.Reason = ReasonAppReadinessProbeFailed
+			decision.Message = i18n.T("decision.app_probe_failed", resourceMetrics.ContainerName, resourceMetrics.ContainerNotReadyReason)
+		}
+		// 应用就绪探针由sidecar代为执行时，探测失败需要让kubelet摘除流量
+		if cfg.AppReadinessProbe != nil {
+			decision.StatusCode = http.StatusServiceUnavailable
+		}
+		log.WithFields(logrus.Fields{
+			"status":               decision.Status,
+			logger.FieldReasonCode: decision.Reason,
+			"message":              decision.Message,
+		}).Log(pathLevel(cfg), i18n.T("health.not_ready"))
+		return decision
 	}
 
+	// 未拒绝流量时按资源压力计算权重，拒绝流量时保持为0
+	weight := h.calcWeight(cfg, resourceMetrics)
+
 	// 2. 检查Pod最小可用比例
 	podsRatio := h.calcPodsRatio(resourceMetrics)
-	if podsRatio < h.Config.MinimumPodsToKeepPercent {
-		status = "POD_SHORTAGE"
-		message = fmt.Sprintf("可用Pod数量(%d/%d = %.2f%%)低于最小阈值(%.2f%%)",
+	if podsRatio < cfg.MinimumPodsToKeepPercent {
+		decision.Status = StatusPodShortage
+		decision.Reason = ReasonPodAvailabilityLow
+		decision.Message = i18n.T("decision.pod_shortage",
 			resourceMetrics.DeploymentAvailableReplicas, resourceMetrics.DeploymentReplicas,
-			podsRatio, h.Config.MinimumPodsToKeepPercent)
-		log.WithField("status", status).Info(message)
-
-		details["status"] = status
-		details["message"] = message
-		w.WriteHeader(statusCode)
-		h.writeJSONResponse(w, details)
-		log.WithField("duration", time.Since(startTime)).Info("健康检查请求处理完成")
-		return
+			podsRatio, cfg.MinimumPodsToKeepPercent)
+		decision.Weight = weight
+		log.WithFields(logrus.Fields{
+			"status":               decision.Status,
+			logger.FieldReasonCode: decision.Reason,
+			"message":              decision.Message,
+		}).Log(pathLevel(cfg), i18n.T("health.pod_shortage"))
+		return decision
 	}
 
 	// 3. 检查资源使用率
 	memUsagePercent := h.calcMemoryPercent(resourceMetrics)
 	cpuUsagePercent := h.calcCPUPercent(resourceMetrics)
 
-	// 判断资源是否过载
-	resourceOverLoaded = memUsagePercent > h.Config.ResourceThresholdMemoryPercent &&
-		cpuUsagePercent > h.Config.ResourceThresholdCPUPercent
-
-	// 如果资源过载，进行随机退避决策
-	if resourceOverLoaded {
-		// 如果允许随机决策，执行随机逻辑
-		if shouldRandomize {
-			// 生成0-100的随机数
-			randomValue := rng.Float64() * 100
-
-			// 如果随机值大于阈值，设置为不再随机并返回不健康状态
-			if randomValue > h.Config.MinimumPodsToKeepPercent {
-				shouldRandomize = false // 第一次随机大于阈值，后续都不再随机
-				log.WithFields(logrus.Fields{
-					"random_value": randomValue,
-					"threshold":    h.Config.MinimumPodsToKeepPercent,
-				}).Info("首次随机决策: 固定拒绝流量")
-
-				status = "RESOURCE_EXHAUSTED"
-				statusCode = http.StatusBadRequest
-				message = fmt.Sprintf("资源使用率过高: 内存使用率 %.2f%% (阈值: %.2f%%), CPU使用率 %.2f%% (阈值: %.2f%%)",
-					memUsagePercent, h.Config.ResourceThresholdMemoryPercent,
-					cpuUsagePercent, h.Config.ResourceThresholdCPUPercent)
-				log.WithFields(logrus.Fields{
-					"status":  status,
-					"message": message,
-				}).Info("健康检查结果: 固定拒绝流量")
-			} else {
-				// 随机值小于等于阈值，保持允许随机状态，本次返回健康
-				log.WithFields(logrus.Fields{
-					"random_value": randomValue,
-					"threshold":    h.Config.MinimumPodsToKeepPercent,
-				}).Info("首次随机决策: 继续随机决策")
-				status = "RESOURCE_OVERLOADED_BUT_KEEPING"
-				message = fmt.Sprintf("资源使用率过高但随机退避生效: 内存使用率 %.2f%%, CPU使用率 %.2f%%, 随机值 %.2f",
-					memUsagePercent, cpuUsagePercent, randomValue)
-			}
-		} else {
-			// 之前已经随机过且大于阈值，固定返回不健康状态
-			status = "RESOURCE_EXHAUSTED"
-			statusCode = http.StatusBadRequest
-			message = fmt.Sprintf("资源使用率过高: 内存使用率 %.2f%% (阈值: %.2f%%), CPU使用率 %.2f%% (阈值: %.2f%%)",
-				memUsagePercent, h.Config.ResourceThresholdMemoryPercent,
-				cpuUsagePercent, h.Config.ResourceThresholdCPUPercent)
-			log.WithFields(logrus.Fields{
-				"status":  status,
-				"message": message,
-			}).Info("健康检查结果: 之前已决策固定拒绝流量")
-		}
-	} else {
+	// 按过载策略判断资源是否过载
+	if !h.isOverloaded(cfg, resourceMetrics) {
 		// 资源未过载，重置标志位，允许下次资源过载时重新随机
-		shouldRandomize = true
-
-		// 资源未过载
-		message = fmt.Sprintf("健康检查通过: 内存使用率 %.2f%%, CPU使用率 %.2f%%, Pod可用率 %.2f%%",
+		h.shouldRandomize = true
+		decision.Reason = ReasonWithinThresholds
+		decision.Message = i18n.T("decision.healthy",
 			memUsagePercent, cpuUsagePercent, podsRatio)
+		decision.Weight = weight
+		return decision
+	}
+
+	// 资源过载，之前已经随机过且大于阈值，固定返回不健康状态
+	if !h.shouldRandomize {
+		decision.Status = StatusResourceExhausted
+		decision.StatusCode = http.StatusBadRequest
+		decision.Reason = ReasonOverloadedShedding
+		decision.Message = h.exhaustedMessage(cfg, resourceMetrics)
+		log.WithFields(logrus.Fields{
+			"status":               decision.Status,
+			logger.FieldReasonCode: decision.Reason,
+			"message":              decision.Message,
+		}).Log(pathLevel(cfg), i18n.T("health.shedding_previously"))
+		return decision
 	}
 
-	details["status"] = status
-	details["message"] = message
-	w.WriteHeader(statusCode)
-	h.writeJSONResponse(w, details)
+	// 生成0-100的随机数进行随机退避决策
+	randomValue := rng.Float64() * 100
+	decision.RandomValue = &randomValue
+
+	// 如果随机值大于阈值，设置为不再随机并返回不健康状态
+	if randomValue > cfg.MinimumPodsToKeepPercent {
+		h.shouldRandomize = false // 第一次随机大于阈值，后续都不再随机
+		log.WithFields(logrus.Fields{
+			"random_value": randomValue,
+			"threshold":    cfg.MinimumPodsToKeepPercent,
+		}).Log(pathLevel(cfg), i18n.T("health.random_shed"))
+
+		decision.Status = StatusResourceExhausted
+		decision.StatusCode = http.StatusBadRequest
+		decision.Reason = ReasonOverloadedRandomShed
+		decision.Message = h.exhaustedMessage(cfg, resourceMetrics)
+		log.WithFields(logrus.Fields{
+			"status":               decision.Status,
+			logger.FieldReasonCode: decision.Reason,
+			"message":              decision.Message,
+		}).Log(pathLevel(cfg), i18n.T("health.shedding"))
+		return decision
+	}
+
+	// 随机值小于等于阈值，保持允许随机状态，本次返回健康
 	log.WithFields(logrus.Fields{
-		"status":   status,
-		"message":  message,
-		"duration": time.Since(startTime),
-	}).Info("健康检查请求处理完成")
+		"random_value": randomValue,
+		"threshold":    cfg.MinimumPodsToKeepPercent,
+	}).Log(pathLevel(cfg), i18n.T("health.random_keep"))
+	decision.Status = StatusResourceOverloadedButKeeping
+	decision.Reason = ReasonOverloadedRandomKeep
+	decision.Message = i18n.T("decision.overloaded_keeping",
+		memUsagePercent, cpuUsagePercent, randomValue)
+	decision.Weight = weight
+	return decision
 }
 
-// 收集资源指标
-func (h *HealthHandler) collectResourceMetrics() (*metrics.ResourceMetrics, error) {
-	log.Info("开始收集资源指标")
+// pathLevel 返回健康检查路径上每次请求都会输出的日志级别，只记录状态变化时降为Debug
+func pathLevel(cfg *config.Config) logrus.Level {
+	if cfg.LogStateChangesOnly {
+		return logrus.DebugLevel
+	}
+	return logrus.InfoLevel
+}
+
+// 收集资源指标，每次Kubernetes/metrics调用都记录为collectResourceMetrics的子span
+func (h *HealthHandler) collectResourceMetrics(ctx context.Context) (resourceMetrics *metrics.ResourceMetrics, err error) {
+	cfg := h.CurrentConfig()
+	level := pathLevel(cfg)
+	log.Log(level, i18n.T("health.collect_started"))
 	startTime := time.Now()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, collectSpan := startSpan(ctx, "collectResourceMetrics")
+	defer func() { endSpan(collectSpan, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	metrics := &metrics.ResourceMetrics{
-		ContainerName: h.Config.ContainerName,
+		ContainerName: cfg.ContainerName,
 	}
 
 	// 获取Deployment信息
-	log.WithField("deployment", h.Config.DeploymentName).Info("获取Deployment信息")
-	deploymentInfo, err := h.K8sClient.GetDeploymentInfo(ctx)
+	log.WithField("deployment", cfg.DeploymentName).Log(level, i18n.T("health.get_deployment"))
+	spanCtx, span := startSpan(ctx, "GetDeploymentInfo", semconv.K8SDeploymentName(cfg.DeploymentName))
+	deploymentInfo, err := h.K8sClient.GetDeploymentInfo(spanCtx)
+	endSpan(span, err)
 	if err != nil {
-		log.WithError(err).Error("获取Deployment信息失败")
+		log.WithError(err).Error(i18n.T("health.get_deployment_failed"))
 	} else if deploymentInfo != nil {
 		metrics.DeploymentReplicas = deploymentInfo.Replicas
 		metrics.DeploymentAvailableReplicas = deploymentInfo.AvailableReplicas
 		log.WithFields(logrus.Fields{
 			"replicas":           deploymentInfo.Replicas,
 			"available_replicas": deploymentInfo.AvailableReplicas,
-		}).Debug("Deployment信息")
+		}).Debug(i18n.T("health.deployment_info"))
 	}
 
 	// 获取容器资源限制 (直接使用已缓存的值)
-	log.WithField("container", h.Config.ContainerName).Info("获取容器资源限制")
-	containerLimits, err := h.K8sClient.GetContainerLimits(ctx)
+	log.WithField("container", cfg.ContainerName).Log(level, i18n.T("health.get_limits"))
+	spanCtx, span = startSpan(ctx, "GetContainerLimits", semconv.K8SContainerName(cfg.ContainerName))
+	containerLimits, err := h.K8sClient.GetContainerLimits(spanCtx)
+	endSpan(span, err)
 	if err != nil {
-		log.WithError(err).Error("获取容器资源限制失败")
-		return nil, fmt.Errorf("无法获取容器资源限制: %v", err)
+		log.WithError(err).Error(i18n.T("health.get_limits_failed"))
+		return nil, errors.New(i18n.T("health.limits_error", err))
 	}
 	metrics.ContainerCPULimit = containerLimits.CPULimit
 	metrics.ContainerMemLimit = containerLimits.MemLimit
+	metrics.ContainerCPUBasis = containerLimits.CPUBasis
+	metrics.ContainerMemBasis = containerLimits.MemBasis
 	log.WithFields(logrus.Fields{
-		"cpu":    containerLimits.CPULimit,
-		"memory": containerLimits.MemLimit,
-	}).Debug("容器资源限制")
-
-	// 获取Pod信息
-	log.WithField("pod", h.Config.PodName).Info("获取Pod信息")
-	podInfo, err := h.K8sClient.GetPodInfo(ctx)
-	if err != nil {
-		log.WithError(err).Error("获取Pod信息失败")
-	} else if podInfo != nil && podInfo.Containers != nil {
-		container := podInfo.Containers[h.Config.ContainerName]
-		if container != nil {
-			metrics.ContainerReady = container.Ready
-			log.WithField("ready", container.Ready).Debug("容器就绪状态")
+		"cpu":          containerLimits.CPULimit,
+		"cpu_basis":    containerLimits.CPUBasis,
+		"memory":       containerLimits.MemLimit,
+		"memory_basis": containerLimits.MemBasis,
+	}).Debug(i18n.T("health.limits"))
+
+	if cfg.AppReadinessProbe != nil {
+		// 主容器的就绪探针指向sidecar时，Pod状态中的就绪状态取决于sidecar自身，
+		// 因此由sidecar代为执行应用原有的就绪探针
+		log.WithField("probe", cfg.AppReadinessProbe.String()).Log(level, i18n.T("health.app_probe"))
+		spanCtx, span := startSpan(ctx, "AppReadinessProbe", attribute.String("probe", cfg.AppReadinessProbe.String()))
+		err := h.prober.Check(spanCtx, cfg.AppReadinessProbe)
+		endSpan(span, err)
+		if err != nil {
+			metrics.ContainerNotReadyReason = err.Error()
+			log.WithError(err).Warn(i18n.T("health.app_probe_failed"))
+		} else {
+			metrics.ContainerReady = true
+		}
+	} else {
+		// 获取Pod信息
+		log.WithField("pod", cfg.PodName).Log(level, i18n.T("health.get_pod"))
+		spanCtx, span := startSpan(ctx, "GetPodInfo", semconv.K8SPodName(cfg.PodName))
+		podInfo, err := h.K8sClient.GetPodInfo(spanCtx)
+		endSpan(span, err)
+		if err != nil {
+			log.WithError(err).Error(i18n.T("health.get_pod_failed"))
+		} else if podInfo != nil && podInfo.Containers != nil {
+			container := podInfo.Containers[cfg.ContainerName]
+			if container != nil {
+				metrics.ContainerReady = container.Ready
+				log.WithField("ready", container.Ready).Debug(i18n.T("health.container_ready"))
+			}
 		}
 	}
 
 	// 获取Pod度量指标
-	log.Info("获取Pod度量指标")
-	podMetrics, err := h.MetricsCollector.GetPodMetrics(ctx)
+	log.Log(level, i18n.T("health.get_pod_metrics"))
+	spanCtx, span = startSpan(ctx, "GetPodMetrics", semconv.K8SPodName(cfg.PodName))
+	podMetrics, err := h.MetricsCollector.GetPodMetrics(spanCtx)
+	endSpan(span, err)
 	if err != nil {
-		log.WithError(err).Error("获取Pod度量指标失败")
+		metrics.MetricsError = err.Error()
+		log.WithError(err).Error(i18n.T("health.get_pod_metrics_failed"))
 	} else if podMetrics != nil && podMetrics.Containers != nil {
-		container := podMetrics.Containers[h.Config.ContainerName]
+		container := podMetrics.Containers[cfg.ContainerName]
 		if container != nil {
 			metrics.ContainerCPUUsage = container.CPUUsage
 			metrics.ContainerMemUsage = container.MemUsage
 			log.WithFields(logrus.Fields{
 				"cpu":    container.CPUUsage,
 				"memory": container.MemUsage,
-			}).Debug("容器资源使用")
+			}).Debug(i18n.T("health.container_usage"))
 		}
 	}
 
-	log.WithField("duration", time.Since(startTime)).Info("资源指标收集完成")
+	collectSpan.SetAttributes(
+		attribute.Bool("container.ready", metrics.ContainerReady),
+		attribute.Int64("container.cpu_usage_millicores", metrics.ContainerCPUUsage),
+		attribute.Int64("container.memory_usage_mb", metrics.ContainerMemUsage),
+	)
+	log.WithField("duration", time.Since(startTime)).Log(level, i18n.T("health.collect_completed"))
 	return metrics, nil
 }
 
@@ -294,12 +532,109 @@ func (h *HealthHandler) calcCPUPercent(metrics *metrics.ResourceMetrics) float64
 	return float64(metrics.ContainerCPUUsage) / float64(metrics.ContainerCPULimit) * 100
 }
 
+// 判断内存是否超过阈值，配置了绝对阈值时以绝对阈值为准
+func (h *HealthHandler) memoryOverThreshold(cfg *config.Config, metrics *metrics.ResourceMetrics) bool {
+	if cfg.ResourceThresholdMemoryMB > 0 {
+		return metrics.ContainerMemUsage > cfg.ResourceThresholdMemoryMB
+	}
+	return h.calcMemoryPercent(metrics) > cfg.ResourceThresholdMemoryPercent
+}
+
+// 判断CPU是否超过阈值，配置了绝对阈值时以绝对阈值为准
+func (h *HealthHandler) cpuOverThreshold(cfg *config.Config, metrics *metrics.ResourceMetrics) bool {
+	if cfg.ResourceThresholdCPUMillicores > 0 {
+		return metrics.ContainerCPUUsage > cfg.ResourceThresholdCPUMillicores
+	}
+	return h.calcCPUPercent(metrics) > cfg.ResourceThresholdCPUPercent
+}
+
+// 按过载策略判断资源是否过载
+func (h *HealthHandler) isOverloaded(cfg *config.Config, metrics *metrics.ResourceMetrics) bool {
+	if cfg.OverloadPolicy == config.OverloadPolicyAny {
+		return h.memoryOverThreshold(cfg, metrics) || h.cpuOverThreshold(cfg, metrics)
+	}
+	return h.memoryOverThreshold(cfg, metrics) && h.cpuOverThreshold(cfg, metrics)
+}
+
+// 内存使用量相对阈值的比例，1表示恰好达到阈值
+func (h *HealthHandler) memoryPressure(cfg *config.Config, metrics *metrics.ResourceMetrics) float64 {
+	if cfg.ResourceThresholdMemoryMB > 0 {
+		return float64(metrics.ContainerMemUsage) / float64(cfg.ResourceThresholdMemoryMB)
+	}
+	if cfg.ResourceThresholdMemoryPercent <= 0 {
+		return math.Inf(1)
+	}
+	return h.calcMemoryPercent(metrics) / cfg.ResourceThresholdMemoryPercent
+}
+
+// CPU使用量相对阈值的比例，1表示恰好达到阈值
+func (h *HealthHandler) cpuPressure(cfg *config.Config, metrics *metrics.ResourceMetrics) float64 {
+	if cfg.ResourceThresholdCPUMillicores > 0 {
+		return float64(metrics.ContainerCPUUsage) / float64(cfg.ResourceThresholdCPUMillicores)
+	}
+	if cfg.ResourceThresholdCPUPercent <= 0 {
+		return math.Inf(1)
+	}
+	return h.calcCPUPercent(metrics) / cfg.ResourceThresholdCPUPercent
+}
+
+// 按资源压力计算权重: 压力低于WeightDrainStartPercent时为100，之后线性下降，
+// 达到阈值时降到WeightMin。与过载策略一致，any取压力较大的资源，all取较小的资源
+func (h *HealthHandler) calcWeight(cfg *config.Config, metrics *metrics.ResourceMetrics) int {
+	memPressure := h.memoryPressure(cfg, metrics)
+	cpuPressure := h.cpuPressure(cfg, metrics)
+	pressure := math.Min(memPressure, cpuPressure)
+	if cfg.OverloadPolicy == config.OverloadPolicyAny {
+		pressure = math.Max(memPressure, cpuPressure)
+	}
+
+	start := cfg.WeightDrainStartPercent / 100
+	weight := 100.0
+	if pressure >= 1 {
+		weight = 0
+	} else if pressure > start {
+		weight = 100 * (1 - pressure) / (1 - start)
+	}
+	return max(int(math.Round(weight)), cfg.WeightMin)
+}
+
+// 内存阈值的描述文本
+func (h *HealthHandler) memoryThresholdText(cfg *config.Config) string {
+	if cfg.ResourceThresholdMemoryMB > 0 {
+		return fmt.Sprintf("%dMB", cfg.ResourceThresholdMemoryMB)
+	}
+	return fmt.Sprintf("%.2f%%", cfg.ResourceThresholdMemoryPercent)
+}
+
+// CPU阈值的描述文本
+func (h *HealthHandler) cpuThresholdText(cfg *config.Config) string {
+	if cfg.ResourceThresholdCPUMillicores > 0 {
+		return fmt.Sprintf("%dm", cfg.ResourceThresholdCPUMillicores)
+	}
+	return fmt.Sprintf("%.2f%%", cfg.ResourceThresholdCPUPercent)
+}
+
+// 资源过载拒绝流量时的消息
+func (h *HealthHandler) exhaustedMessage(cfg *config.Config, metrics *metrics.ResourceMetrics) string {
+	return i18n.T("decision.resource_exhausted",
+		metrics.ContainerMemUsage, h.calcMemoryPercent(metrics), h.memoryThresholdText(cfg),
+		metrics.ContainerCPUUsage, h.calcCPUPercent(metrics), h.cpuThresholdText(cfg))
+}
+
+// errorResponse 无法做出决策时的响应内容
+func errorResponse(reason, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"reason_code": reason,
+		"message":     message,
+	}
+}
+
 // 输出JSON响应
 func (h *HealthHandler) writeJSONResponse(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	jsonData, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
-		log.WithError(err).Error("序列化JSON失败")
+		log.WithError(err).Error(i18n.T("health.json_failed"))
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -1,10 +1,16 @@
 package handlers
 
 import (
+	"context"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"metrics-sidecar/pkg/config"
+	"metrics-sidecar/pkg/i18n"
 	"metrics-sidecar/pkg/metrics"
+	"metrics-sidecar/pkg/probe"
 )
 
 // 测试健康状态计算函数
@@ -16,9 +22,7 @@ func TestCalcFunctions(t *testing.T) {
 		MinimumPodsToKeepPercent:       50.0,
 	}
 
-	handler := &HealthHandler{
-		Config: cfg,
-	}
+	handler := NewHealthHandler(nil, nil, cfg)
 
 	// 测试Pod比例计算
 	t.Run("calcPodsRatio", func(t *testing.T) {
@@ -80,3 +84,246 @@ func TestCalcFunctions(t *testing.T) {
 		}
 	})
 }
+
+// 测试百分比阈值、绝对阈值与过载策略的组合
+func TestIsOverloaded(t *testing.T) {
+	// 内存使用 3000MB/4096MB ≈ 73%，CPU使用 1900m/2000m = 95%
+	usage := &metrics.ResourceMetrics{
+		ContainerMemLimit: 4096,
+		ContainerMemUsage: 3000,
+		ContainerCPULimit: 2000,
+		ContainerCPUUsage: 1900,
+	}
+
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		expected bool
+	}{
+		{
+			name: "百分比阈值且仅CPU超限",
+			cfg: &config.Config{
+				ResourceThresholdMemoryPercent: 80.0,
+				ResourceThresholdCPUPercent:    80.0,
+				OverloadPolicy:                 config.OverloadPolicyAll,
+			},
+			expected: false,
+		},
+		{
+			name: "内存绝对阈值与CPU百分比阈值同时超限",
+			cfg: &config.Config{
+				ResourceThresholdMemoryPercent: 80.0,
+				ResourceThresholdMemoryMB:      2048,
+				ResourceThresholdCPUPercent:    80.0,
+				OverloadPolicy:                 config.OverloadPolicyAll,
+			},
+			expected: true,
+		},
+		{
+			name: "CPU绝对阈值未超限",
+			cfg: &config.Config{
+				ResourceThresholdMemoryMB:      2048,
+				ResourceThresholdCPUMillicores: 1950,
+				OverloadPolicy:                 config.OverloadPolicyAll,
+			},
+			expected: false,
+		},
+		{
+			name: "any策略下任一资源超限",
+			cfg: &config.Config{
+				ResourceThresholdMemoryPercent: 80.0,
+				ResourceThresholdCPUMillicores: 1800,
+				OverloadPolicy:                 config.OverloadPolicyAny,
+			},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHealthHandler(nil, nil, tt.cfg)
+			if result := handler.isOverloaded(tt.cfg, usage); result != tt.expected {
+				t.Errorf("isOverloaded返回 %v; 期望 %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+// 测试随机退避的状态流转
+func TestDecide(t *testing.T) {
+	overloaded := &metrics.ResourceMetrics{
+		ContainerReady:              true,
+		DeploymentReplicas:          4,
+		DeploymentAvailableReplicas: 4,
+		ContainerMemLimit:           1000,
+		ContainerMemUsage:           900,
+		ContainerCPULimit:           1000,
+		ContainerCPUUsage:           900,
+	}
+	normal := &metrics.ResourceMetrics{
+		ContainerReady:              true,
+		DeploymentReplicas:          4,
+		DeploymentAvailableReplicas: 4,
+		ContainerMemLimit:           1000,
+		ContainerMemUsage:           100,
+		ContainerCPULimit:           1000,
+		ContainerCPUUsage:           100,
+	}
+
+	// 随机退避阈值为0时，过载后必然固定拒绝流量，直到资源恢复
+	cfg := &config.Config{
+		ResourceThresholdMemoryPercent: 80.0,
+		ResourceThresholdCPUPercent:    80.0,
+		OverloadPolicy:                 config.OverloadPolicyAll,
+		MinimumPodsToKeepPercent:       0,
+	}
+	handler := NewHealthHandler(nil, nil, cfg)
+
+	first := handler.decide(cfg, overloaded)
+	if first.Status != StatusResourceExhausted || first.RandomValue == nil || first.Reason != ReasonOverloadedRandomShed {
+		t.Errorf("首次过载决策 = %s/%s; 期望 %s/%s且抽取随机值", first.Status, first.Reason, StatusResourceExhausted, ReasonOverloadedRandomShed)
+	}
+	second := handler.decide(cfg, overloaded)
+	if second.Status != StatusResourceExhausted || second.RandomValue != nil || second.Reason != ReasonOverloadedShedding {
+		t.Errorf("再次过载决策 = %s/%s; 期望固定为 %s/%s且不再抽取随机值", second.Status, second.Reason, StatusResourceExhausted, ReasonOverloadedShedding)
+	}
+	if recovered := handler.decide(cfg, normal); recovered.Status != StatusHealthy || recovered.Reason != ReasonWithinThresholds || !handler.shouldRandomize {
+		t.Errorf("资源恢复后决策 = %s/%s; 期望 %s/%s并重新允许随机决策", recovered.Status, recovered.Reason, StatusHealthy, ReasonWithinThresholds)
+	}
+
+	// 随机退避阈值为100时，过载后始终保持服务
+	cfg.MinimumPodsToKeepPercent = 100
+	if keeping := handler.decide(cfg, overloaded); keeping.Status != StatusResourceOverloadedButKeeping || keeping.Reason != ReasonOverloadedRandomKeep {
+		t.Errorf("过载决策 = %s/%s; 期望 %s/%s", keeping.Status, keeping.Reason, StatusResourceOverloadedButKeeping, ReasonOverloadedRandomKeep)
+	}
+}
+
+func TestDecideNotReady(t *testing.T) {
+	notReady := &metrics.ResourceMetrics{
+		ContainerName:           "main-app",
+		ContainerNotReadyReason: "HTTP探测返回状态码500",
+	}
+	cfg := &config.Config{OverloadPolicy: config.OverloadPolicyAll}
+	handler := NewHealthHandler(nil, nil, cfg)
+
+	// 未配置应用就绪探针时保持原有行为，只报告状态
+	if decision := handler.decide(cfg, notReady); decision.Status != StatusNotReady || decision.StatusCode != http.StatusOK {
+		t.Errorf("决策 = %s/%d; 期望 %s/200", decision.Status, decision.StatusCode, StatusNotReady)
+	}
+	if decision := handler.decide(cfg, &metrics.ResourceMetrics{ContainerName: "main-app"}); decision.Reason != ReasonContainerNotReady {
+		t.Errorf("原因代码 = %s; 期望 %s", decision.Reason, ReasonContainerNotReady)
+	}
+
+	// 由sidecar执行应用就绪探针时，探测失败返回503
+	cfg.AppReadinessProbe = &probe.Spec{TCPSocket: &probe.TCPSocketAction{Port: 8080}}
+	decision := handler.decide(cfg, notReady)
+	if decision.Status != StatusNotReady || decision.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("决策 = %s/%d; 期望 %s/503", decision.Status, decision.StatusCode, StatusNotReady)
+	}
+	if !strings.Contains(decision.Message, notReady.ContainerNotReadyReason) || decision.Reason != ReasonAppReadinessProbeFailed {
+		t.Errorf("消息 = %q, 原因代码 = %s; 期望包含探测失败原因且原因代码为 %s", decision.Message, decision.Reason, ReasonAppReadinessProbeFailed)
+	}
+
+	// 消息随语言变化，原因代码不变
+	i18n.SetLanguage(i18n.English)
+	defer i18n.SetLanguage(i18n.Chinese)
+	decision = handler.decide(cfg, notReady)
+	expected := "container main-app is not ready: " + notReady.ContainerNotReadyReason
+	if decision.Message != expected || decision.Reason != ReasonAppReadinessProbeFailed {
+		t.Errorf("英文消息 = %q, 原因代码 = %s; 期望 %q, %s", decision.Message, decision.Reason, expected, ReasonAppReadinessProbeFailed)
+	}
+}
+
+func TestCalcWeight(t *testing.T) {
+	cfg := &config.Config{
+		ResourceThresholdMemoryPercent: 80.0,
+		ResourceThresholdCPUPercent:    80.0,
+		OverloadPolicy:                 config.OverloadPolicyAny,
+		WeightDrainStartPercent:        50.0,
+		WeightMin:                      10,
+	}
+	handler := NewHealthHandler(nil, nil, cfg)
+
+	tests := []struct {
+		name     string
+		cpuUsage int64
+		expected int
+	}{
+		{name: "低于开始降权的位置", cpuUsage: 300, expected: 100},
+		{name: "开始降权与阈值之间线性下降", cpuUsage: 600, expected: 50},
+		{name: "超过阈值时降到最小权重", cpuUsage: 900, expected: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage := &metrics.ResourceMetrics{
+				ContainerMemLimit: 1000,
+				ContainerMemUsage: 100,
+				ContainerCPULimit: 1000,
+				ContainerCPUUsage: tt.cpuUsage,
+			}
+			if weight := handler.calcWeight(cfg, usage); weight != tt.expected {
+				t.Errorf("calcWeight返回 %d; 期望 %d", weight, tt.expected)
+			}
+		})
+	}
+
+	// all策略下取压力较小的资源，内存使用较低时保持满权重
+	cfg.OverloadPolicy = config.OverloadPolicyAll
+	usage := &metrics.ResourceMetrics{ContainerMemLimit: 1000, ContainerMemUsage: 100, ContainerCPULimit: 1000, ContainerCPUUsage: 900}
+	if weight := handler.calcWeight(cfg, usage); weight != 100 {
+		t.Errorf("all策略下calcWeight返回 %d; 期望 100", weight)
+	}
+}
+
+func TestDecideWeight(t *testing.T) {
+	overloaded := &metrics.ResourceMetrics{
+		ContainerReady:              true,
+		DeploymentReplicas:          4,
+		DeploymentAvailableReplicas: 4,
+		ContainerMemLimit:           1000,
+		ContainerMemUsage:           900,
+		ContainerCPULimit:           1000,
+		ContainerCPUUsage:           900,
+	}
+	cfg := &config.Config{
+		ResourceThresholdMemoryPercent: 80.0,
+		ResourceThresholdCPUPercent:    80.0,
+		OverloadPolicy:                 config.OverloadPolicyAll,
+		WeightDrainStartPercent:        80.0,
+		WeightMin:                      10,
+	}
+	handler := NewHealthHandler(nil, nil, cfg)
+
+	// 拒绝流量时权重为0
+	if exhausted := handler.decide(cfg, overloaded); exhausted.Status != StatusResourceExhausted || exhausted.Weight != 0 {
+		t.Errorf("决策 = %s/权重%d; 期望 %s/权重0", exhausted.Status, exhausted.Weight, StatusResourceExhausted)
+	}
+
+	// 过载但保持服务时使用最小权重
+	handler.shouldRandomize = true
+	cfg.MinimumPodsToKeepPercent = 100
+	if keeping := handler.decide(cfg, overloaded); keeping.Status != StatusResourceOverloadedButKeeping || keeping.Weight != 10 {
+		t.Errorf("决策 = %s/权重%d; 期望 %s/权重10", keeping.Status, keeping.Weight, StatusResourceOverloadedButKeeping)
+	}
+
+	if notReady := handler.decide(cfg, &metrics.ResourceMetrics{}); notReady.Weight != 0 {
+		t.Errorf("容器未就绪时权重 = %d; 期望 0", notReady.Weight)
+	}
+}
+
+func TestCachedDecision(t *testing.T) {
+	cfg := &config.Config{EvaluationInterval: time.Minute}
+	handler := NewHealthHandler(nil, nil, cfg)
+	handler.last = &Decision{Time: time.Now().Add(-time.Second), Status: StatusHealthy, Weight: 80}
+
+	// 未过期时直接返回最近一次决策，不收集指标（处理器没有指标收集器，重新决策会失败）
+	for i := 0; i < 3; i++ {
+		decision, err := handler.CachedDecision(context.Background())
+		if err != nil {
+			t.Fatalf("CachedDecision()返回错误: %v", err)
+		}
+		if decision != handler.last {
+			t.Errorf("CachedDecision() = %+v; 期望最近一次决策", decision)
+		}
+	}
+}
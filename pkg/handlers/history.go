@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/metrics"
+)
+
+// DecisionRecord 决策历史中的一条记录，包含做出决策时的输入、阈值和结果
+type DecisionRecord struct {
+	Time        time.Time                `json:"time"`
+	Status      string                   `json:"status"`
+	StatusCode  int                      `json:"status_code"`
+	ReasonCode  string                   `json:"reason_code"`            // 原因代码，如RESOURCES_WITHIN_THRESHOLDS
+	Message     string                   `json:"message"`                // 按当前语言生成的人类可读消息
+	RandomValue *float64                 `json:"random_value,omitempty"` // 未抽取随机值时省略
+	Weight      int                      `json:"weight"`
+	Inputs      *metrics.ResourceMetrics `json:"inputs"`
+	Thresholds  DecisionThresholds       `json:"thresholds"`
+}
+
+// DecisionThresholds 做出决策时生效的阈值和策略
+type DecisionThresholds struct {
+	Memory                   string  `json:"memory"`
+	CPU                      string  `json:"cpu"`
+	Policy                   string  `json:"policy"`
+	MinimumPodsToKeepPercent float64 `json:"minimum_pods_to_keep_percent"`
+}
+
+// DecisionHistory 在内存中以环形缓冲区保留最近的决策，通过/debug/decisions查询，
+// 便于事后还原Pod在某个时间为什么拒绝流量
+type DecisionHistory struct {
+	healthHandler *HealthHandler
+
+	mu      sync.Mutex
+	records []DecisionRecord // 环形缓冲区，容量固定
+	next    int              // 下一条记录写入的位置
+	full    bool             // 缓冲区是否已写满一轮
+}
+
+// NewDecisionHistory 创建容量为size的决策历史，size必须大于0
+func NewDecisionHistory(healthHandler *HealthHandler, size int) *DecisionHistory {
+	return &DecisionHistory{
+		healthHandler: healthHandler,
+		records:       make([]DecisionRecord, size),
+	}
+}
+
+// Listener 返回记录每次决策的监听器
+func (d *DecisionHistory) Listener() DecisionListener {
+	return func(_, current *Decision) {
+		d.add(d.record(current))
+	}
+}
+
+// record 将决策转换为历史记录
+func (d *DecisionHistory) record(decision *Decision) DecisionRecord {
+	cfg := decision.Config
+	return DecisionRecord{
+		Time:        decision.Time,
+		Status:      decision.Status,
+		StatusCode:  decision.StatusCode,
+		ReasonCode:  decision.Reason,
+		Message:     decision.Message,
+		RandomValue: decision.RandomValue,
+		Weight:      decision.Weight,
+		Inputs:      decision.Metrics,
+		Thresholds: DecisionThresholds{
+			Memory:                   d.healthHandler.memoryThresholdText(cfg),
+			CPU:                      d.healthHandler.cpuThresholdText(cfg),
+			Policy:                   cfg.OverloadPolicy,
+			MinimumPodsToKeepPercent: cfg.MinimumPodsToKeepPercent,
+		},
+	}
+}
+
+// add 写入一条记录，缓冲区已满时覆盖最旧的记录
+func (d *DecisionHistory) add(record DecisionRecord) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.records[d.next] = record
+	d.next = (d.next + 1) % len(d.records)
+	if d.next == 0 {
+		d.full = true
+	}
+}
+
+// Records 按时间顺序返回当前保留的所有记录
+func (d *DecisionHistory) Records() []DecisionRecord {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.full {
+		return append([]DecisionRecord(nil), d.records[:d.next]...)
+	}
+	result := make([]DecisionRecord, 0, len(d.records))
+	result = append(result, d.records[d.next:]...)
+	return append(result, d.records[:d.next]...)
+}
+
+// historyQuery /debug/decisions的查询条件
+type historyQuery struct {
+	since    time.Time       // 只返回该时间及之后的记录，零值表示不限制
+	until    time.Time       // 只返回该时间及之前的记录，零值表示不限制
+	statuses map[string]bool // 只返回这些状态的记录，为空表示不限制
+	limit    int             // 最多返回最近的多少条，0表示不限制
+}
+
+// parseHistoryQuery 解析查询参数。since/until支持RFC3339时间或相对当前时间的时长（如10m），
+// status支持逗号分隔的多个状态，limit为返回的最大条数
+func parseHistoryQuery(r *http.Request, now time.Time) (*historyQuery, error) {
+	params := r.URL.Query()
+	query := &historyQuery{}
+
+	var err error
+	if query.since, err = parseHistoryTime(params.Get("since"), now); err != nil {
+		return nil, i18n.Errorf("history.invalid_since", err)
+	}
+	if query.until, err = parseHistoryTime(params.Get("until"), now); err != nil {
+		return nil, i18n.Errorf("history.invalid_until", err)
+	}
+
+	if value := params.Get("status"); value != "" {
+		query.statuses = make(map[string]bool)
+		for _, status := range strings.Split(value, ",") {
+			if status = strings.ToUpper(strings.TrimSpace(status)); status != "" {
+				query.statuses[status] = true
+			}
+		}
+	}
+
+	if value := params.Get("limit"); value != "" {
+		if query.limit, err = strconv.Atoi(value); err != nil || query.limit < 0 {
+			return nil, i18n.Errorf("history.invalid_limit", value)
+		}
+	}
+	return query, nil
+}
+
+// parseHistoryTime 解析RFC3339时间或相对当前时间的时长，空值返回零值
+func parseHistoryTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return time.Time{}, i18n.Errorf("history.invalid_time", value)
+	}
+	return now.Add(-duration), nil
+}
+
+// filter 按查询条件筛选记录，超过limit时保留最近的记录
+func (q *historyQuery) filter(records []DecisionRecord) []DecisionRecord {
+	result := make([]DecisionRecord, 0, len(records))
+	for _, record := range records {
+		if !q.since.IsZero() && record.Time.Before(q.since) {
+			continue
+		}
+		if !q.until.IsZero() && record.Time.After(q.until) {
+			continue
+		}
+		if len(q.statuses) > 0 && !q.statuses[record.Status] {
+			continue
+		}
+		result = append(result, record)
+	}
+	if q.limit > 0 && len(result) > q.limit {
+		result = result[len(result)-q.limit:]
+	}
+	return result
+}
+
+// ServeHTTP 按时间顺序返回符合条件的决策记录
+func (d *DecisionHistory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query, err := parseHistoryQuery(r, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	records := query.filter(d.Records())
+	d.healthHandler.writeJSONResponse(w, map[string]interface{}{
+		"capacity":  len(d.records),
+		"count":     len(records),
+		"decisions": records,
+	})
+}
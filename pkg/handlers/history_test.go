@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"metrics-sidecar/pkg/config"
+	"metrics-sidecar/pkg/metrics"
+)
+
+func TestDecisionHistoryRingBuffer(t *testing.T) {
+	cfg := &config.Config{OverloadPolicy: config.OverloadPolicyAll, ResourceThresholdMemoryPercent: 80, ResourceThresholdCPUPercent: 80}
+	history := NewDecisionHistory(NewHealthHandler(nil, nil, cfg), 3)
+	listener := history.Listener()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		listener(nil, &Decision{
+			Time:    start.Add(time.Duration(i) * time.Minute),
+			Status:  StatusHealthy,
+			Metrics: &metrics.ResourceMetrics{},
+			Config:  cfg,
+		})
+	}
+
+	records := history.Records()
+	if len(records) != 3 {
+		t.Fatalf("保留的记录数 = %d; 期望 3", len(records))
+	}
+	for i, record := range records {
+		if expected := start.Add(time.Duration(i+2) * time.Minute); !record.Time.Equal(expected) {
+			t.Errorf("第%d条记录时间 = %s; 期望 %s", i, record.Time, expected)
+		}
+	}
+	if records[0].Thresholds.Memory != "80.00%" {
+		t.Errorf("记录的内存阈值 = %s; 期望 80.00%%", records[0].Thresholds.Memory)
+	}
+}
+
+func TestHistoryQueryFilter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)
+	records := []DecisionRecord{
+		{Time: now.Add(-30 * time.Minute), Status: StatusHealthy},
+		{Time: now.Add(-20 * time.Minute), Status: StatusResourceExhausted},
+		{Time: now.Add(-10 * time.Minute), Status: StatusResourceOverloadedButKeeping},
+		{Time: now.Add(-5 * time.Minute), Status: StatusResourceExhausted},
+	}
+
+	tests := []struct {
+		url      string
+		expected int
+	}{
+		{"/debug/decisions", 4},
+		{"/debug/decisions?since=15m", 2},
+		{"/debug/decisions?until=2024-01-01T00:45:00Z", 2},
+		{"/debug/decisions?status=resource_exhausted", 2},
+		{"/debug/decisions?status=RESOURCE_EXHAUSTED,HEALTHY&since=25m", 2},
+		{"/debug/decisions?limit=1", 1},
+	}
+	for _, tt := range tests {
+		query, err := parseHistoryQuery(httptest.NewRequest(http.MethodGet, tt.url, nil), now)
+		if err != nil {
+			t.Errorf("parseHistoryQuery(%s)返回错误: %v", tt.url, err)
+			continue
+		}
+		if got := query.filter(records); len(got) != tt.expected {
+			t.Errorf("%s 返回 %d 条记录; 期望 %d", tt.url, len(got), tt.expected)
+		}
+	}
+
+	for _, url := range []string{"/debug/decisions?since=yesterday", "/debug/decisions?limit=-1"} {
+		if _, err := parseHistoryQuery(httptest.NewRequest(http.MethodGet, url, nil), now); err == nil {
+			t.Errorf("parseHistoryQuery(%s)未返回错误; 期望返回错误", url)
+		}
+	}
+}
@@ -2,16 +2,18 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"time"
 
 	"metrics-sidecar/pkg/config"
+	"metrics-sidecar/pkg/i18n"
 	"metrics-sidecar/pkg/k8s"
 	"metrics-sidecar/pkg/logger"
 	"metrics-sidecar/pkg/metrics"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -40,26 +42,28 @@ func NewMetricsHandler(k8sClient *k8s.Client, metricsCollector *metrics.MetricsC
 // ServeHTTP 实现http.Handler接口
 func (h *MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	startTime := time.Now()
+	level := pathLevel(h.HealthHandler.CurrentConfig())
 	metricsLog.WithFields(logrus.Fields{
 		"method": r.Method,
 		"path":   r.URL.Path,
-	}).Info("开始处理指标API请求")
+	}).Log(level, i18n.T("metrics.request_started"))
 
 	// 复用健康检查处理器的指标收集逻辑
-	metrics, err := h.HealthHandler.collectResourceMetrics()
+	metrics, err := h.HealthHandler.collectResourceMetrics(r.Context())
 	if err != nil {
-		metricsLog.WithError(err).Error("获取指标失败")
+		trace.SpanFromContext(r.Context()).SetStatus(codes.Error, err.Error())
+		metricsLog.WithError(err).WithField(logger.FieldReasonCode, ReasonMetricsUnavailable).Error(i18n.T("metrics.failed"))
 		w.WriteHeader(http.StatusServiceUnavailable)
-		fmt.Fprintf(w, "Failed to collect metrics: %v", err)
+		h.HealthHandler.writeJSONResponse(w, errorResponse(ReasonMetricsUnavailable, i18n.T("metrics.failed_response", err)))
 		return
 	}
 
-	metricsLog.Info("成功收集到指标数据，正在返回JSON响应")
+	metricsLog.Log(level, i18n.T("metrics.collected"))
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(metrics)
 	if err != nil {
-		metricsLog.WithError(err).Error("序列化JSON失败")
+		metricsLog.WithError(err).Error(i18n.T("health.json_failed"))
 	}
 
-	metricsLog.WithField("duration", time.Since(startTime)).Info("指标API请求处理完成")
+	metricsLog.WithField("duration", time.Since(startTime)).Log(level, i18n.T("metrics.request_completed"))
 }
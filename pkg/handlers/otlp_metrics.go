@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/telemetry"
+)
+
+// OTLPMetrics 将每次决策的资源指标快照和决策状态作为OpenTelemetry指标，
+// 由MeterProvider按推送间隔读取最近一次决策，供只有OpenTelemetry Collector的集群使用
+type OTLPMetrics struct {
+	healthHandler *HealthHandler
+	decisions     metric.Int64Counter
+
+	mu   sync.Mutex
+	last *Decision // 最近一次决策，尚未决策时为nil
+}
+
+// otlpGauges 推送时从最近一次决策读取的仪表
+type otlpGauges struct {
+	cpuUsage, cpuLimit, cpuUtilization metric.Float64ObservableGauge
+	memUsage, memLimit                 metric.Int64ObservableGauge
+	memUtilization                     metric.Float64ObservableGauge
+	ready                              metric.Int64ObservableGauge
+	replicas, availableReplicas        metric.Int64ObservableGauge
+	availability, weight               metric.Float64ObservableGauge
+	state                              metric.Int64ObservableGauge
+}
+
+// otlpName 返回带sidecar前缀的指标名
+func otlpName(name string) string {
+	return telemetry.Namespace + "." + name
+}
+
+// NewOTLPMetrics 在meter上创建资源使用和决策指标
+func NewOTLPMetrics(meter metric.Meter, healthHandler *HealthHandler) (*OTLPMetrics, error) {
+	m := &OTLPMetrics{healthHandler: healthHandler}
+
+	var err error
+	if m.decisions, err = meter.Int64Counter(otlpName("decisions"),
+		metric.WithDescription(i18n.T("metric.decisions")), metric.WithUnit("{decision}")); err != nil {
+		return nil, err
+	}
+
+	var g otlpGauges
+	floatGauges := []struct {
+		dst              *metric.Float64ObservableGauge
+		name, desc, unit string
+	}{
+		{&g.cpuUsage, "container.cpu.usage", i18n.T("metric.cpu_usage"), "{cpu}"},
+		{&g.cpuLimit, "container.cpu.limit", i18n.T("metric.cpu_limit"), "{cpu}"},
+		{&g.cpuUtilization, "container.cpu.utilization", i18n.T("metric.cpu_utilization"), "1"},
+		{&g.memUtilization, "container.memory.utilization", i18n.T("metric.memory_utilization"), "1"},
+		{&g.availability, "deployment.availability", i18n.T("metric.availability"), "1"},
+		{&g.weight, "decision.weight", i18n.T("metric.weight"), "1"},
+	}
+	for _, gauge := range floatGauges {
+		if *gauge.dst, err = meter.Float64ObservableGauge(otlpName(gauge.name),
+			metric.WithDescription(gauge.desc), metric.WithUnit(gauge.unit)); err != nil {
+			return nil, err
+		}
+	}
+	intGauges := []struct {
+		dst              *metric.Int64ObservableGauge
+		name, desc, unit string
+	}{
+		{&g.memUsage, "container.memory.usage", i18n.T("metric.memory_usage"), "By"},
+		{&g.memLimit, "container.memory.limit", i18n.T("metric.memory_limit"), "By"},
+		{&g.ready, "container.ready", i18n.T("metric.ready"), "1"},
+		{&g.replicas, "deployment.replicas", i18n.T("metric.replicas"), "{replica}"},
+		{&g.availableReplicas, "deployment.available_replicas", i18n.T("metric.available_replicas"), "{replica}"},
+		{&g.state, "decision.state", i18n.T("metric.state"), "1"},
+	}
+	for _, gauge := range intGauges {
+		if *gauge.dst, err = meter.Int64ObservableGauge(otlpName(gauge.name),
+			metric.WithDescription(gauge.desc), metric.WithUnit(gauge.unit)); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		m.observe(o, &g)
+		return nil
+	}, g.cpuUsage, g.cpuLimit, g.cpuUtilization, g.memUsage, g.memLimit, g.memUtilization,
+		g.ready, g.replicas, g.availableReplicas, g.availability, g.weight, g.state)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Listener 返回记录最近一次决策并统计决策次数的监听器
+func (m *OTLPMetrics) Listener() DecisionListener {
+	return func(_, current *Decision) {
+		m.decisions.Add(context.Background(), 1, metric.WithAttributes(attribute.String("status", current.Status)))
+
+		m.mu.Lock()
+		m.last = current
+		m.mu.Unlock()
+	}
+}
+
+// observe 推送时写入最近一次决策的指标，尚未决策时不写入
+func (m *OTLPMetrics) observe(o metric.Observer, g *otlpGauges) {
+	m.mu.Lock()
+	decision := m.last
+	m.mu.Unlock()
+	if decision == nil {
+		return
+	}
+
+	rm := decision.Metrics
+	cpuBasis := metric.WithAttributes(attribute.String("basis", rm.ContainerCPUBasis))
+	memBasis := metric.WithAttributes(attribute.String("basis", rm.ContainerMemBasis))
+	o.ObserveFloat64(g.cpuLimit, float64(rm.ContainerCPULimit)/1000, cpuBasis)
+	o.ObserveInt64(g.memLimit, rm.ContainerMemLimit*1024*1024, memBasis)
+
+	// 获取Pod度量指标失败时使用量为0，不推送以免被误认为空闲
+	if rm.MetricsError == "" {
+		o.ObserveFloat64(g.cpuUsage, float64(rm.ContainerCPUUsage)/1000)
+		o.ObserveFloat64(g.cpuUtilization, m.healthHandler.calcCPUPercent(rm)/100, cpuBasis)
+		o.ObserveInt64(g.memUsage, rm.ContainerMemUsage*1024*1024)
+		o.ObserveFloat64(g.memUtilization, m.healthHandler.calcMemoryPercent(rm)/100, memBasis)
+	}
+
+	ready := int64(0)
+	if rm.ContainerReady {
+		ready = 1
+	}
+	o.ObserveInt64(g.ready, ready)
+
+	o.ObserveInt64(g.replicas, int64(rm.DeploymentReplicas))
+	o.ObserveInt64(g.availableReplicas, int64(rm.DeploymentAvailableReplicas))
+	if rm.DeploymentReplicas > 0 {
+		o.ObserveFloat64(g.availability, m.healthHandler.calcPodsRatio(rm)/100)
+	}
+
+	for _, status := range AllStatuses {
+		value := int64(0)
+		if status == decision.Status {
+			value = 1
+		}
+		o.ObserveInt64(g.state, value, metric.WithAttributes(attribute.String("status", status)))
+	}
+	o.ObserveFloat64(g.weight, float64(decision.Weight))
+}
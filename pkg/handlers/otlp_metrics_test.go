@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"metrics-sidecar/pkg/config"
+	"metrics-sidecar/pkg/metrics"
+)
+
+// collectOTLP 读取一次指标，按名称返回各数据点的值，键为"名称"或"名称{属性=值}"
+func collectOTLP(t *testing.T, reader *sdkmetric.ManualReader) map[string]float64 {
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("读取指标失败: %v", err)
+	}
+
+	values := make(map[string]float64)
+	key := func(name string, attrs attribute.Set) string {
+		for _, kv := range attrs.ToSlice() {
+			name += "{" + string(kv.Key) + "=" + kv.Value.Emit() + "}"
+		}
+		return name
+	}
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Gauge[float64]:
+				for _, dp := range data.DataPoints {
+					values[key(m.Name, dp.Attributes)] = dp.Value
+				}
+			case metricdata.Gauge[int64]:
+				for _, dp := range data.DataPoints {
+					values[key(m.Name, dp.Attributes)] = float64(dp.Value)
+				}
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					values[key(m.Name, dp.Attributes)] = float64(dp.Value)
+				}
+			}
+		}
+	}
+	return values
+}
+
+func TestOTLPMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	m, err := NewOTLPMetrics(provider.Meter("test"), NewHealthHandler(nil, nil, &config.Config{}))
+	if err != nil {
+		t.Fatalf("NewOTLPMetrics返回错误: %v", err)
+	}
+
+	// 尚未决策时不推送任何数据点
+	if values := collectOTLP(t, reader); len(values) != 0 {
+		t.Errorf("首次决策前的指标 = %v; 期望为空", values)
+	}
+
+	listener := m.Listener()
+	resourceMetrics := &metrics.ResourceMetrics{
+		DeploymentReplicas:          4,
+		DeploymentAvailableReplicas: 3,
+		ContainerReady:              true,
+		ContainerCPULimit:           2000,
+		ContainerCPUUsage:           1500,
+		ContainerCPUBasis:           config.BasisLimit,
+		ContainerMemLimit:           1024,
+		ContainerMemUsage:           512,
+		ContainerMemBasis:           config.BasisRequest,
+	}
+	listener(nil, &Decision{Time: time.Now(), Status: StatusHealthy, Weight: 100, Metrics: resourceMetrics})
+	listener(nil, &Decision{Time: time.Now(), Status: StatusResourceExhausted, Metrics: resourceMetrics})
+
+	values := collectOTLP(t, reader)
+	expected := map[string]float64{
+		"metrics_sidecar.container.cpu.usage":                         1.5,
+		"metrics_sidecar.container.cpu.limit{basis=limit}":            2,
+		"metrics_sidecar.container.cpu.utilization{basis=limit}":      0.75,
+		"metrics_sidecar.container.memory.usage":                      512 * 1024 * 1024,
+		"metrics_sidecar.container.memory.limit{basis=request}":       1024 * 1024 * 1024,
+		"metrics_sidecar.container.memory.utilization{basis=request}": 0.5,
+		"metrics_sidecar.container.ready":                             1,
+		"metrics_sidecar.deployment.replicas":                         4,
+		"metrics_sidecar.deployment.available_replicas":               3,
+		"metrics_sidecar.deployment.availability":                     0.75,
+		"metrics_sidecar.decision.state{status=RESOURCE_EXHAUSTED}":   1,
+		"metrics_sidecar.decision.state{status=HEALTHY}":              0,
+		"metrics_sidecar.decision.weight":                             0,
+		"metrics_sidecar.decisions{status=HEALTHY}":                   1,
+		"metrics_sidecar.decisions{status=RESOURCE_EXHAUSTED}":        1,
+	}
+	for name, value := range expected {
+		got, exists := values[name]
+		if !exists {
+			t.Errorf("缺少指标 %s", name)
+		} else if got != value {
+			t.Errorf("%s = %v; 期望 %v", name, got, value)
+		}
+	}
+
+	// 获取Pod度量指标失败时不推送使用量
+	failed := *resourceMetrics
+	failed.MetricsError = "metrics-server不可用"
+	listener(nil, &Decision{Time: time.Now(), Status: StatusHealthy, Weight: 100, Metrics: &failed})
+	values = collectOTLP(t, reader)
+	if _, exists := values["metrics_sidecar.container.cpu.usage"]; exists {
+		t.Error("指标不可用时不应推送metrics_sidecar.container.cpu.usage")
+	}
+	if _, exists := values["metrics_sidecar.container.cpu.limit{basis=limit}"]; !exists {
+		t.Error("指标不可用时仍应推送metrics_sidecar.container.cpu.limit")
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 健康检查处理的tracer，未启用追踪时为空实现
+var tracer = otel.Tracer("metrics-sidecar/pkg/handlers")
+
+// startSpan 在ctx中的span下创建子span
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan 结束span，err非空时记录错误
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// decisionAttributes 决策span的属性：状态、权重以及决策所依据的使用率
+func (h *HealthHandler) decisionAttributes(decision *Decision) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("decision.status", decision.Status),
+		attribute.Int("decision.status_code", decision.StatusCode),
+		attribute.Bool("decision.shedding", decision.Shedding()),
+		attribute.Int("decision.weight", decision.Weight),
+		attribute.Float64("resource.memory_percent", h.calcMemoryPercent(decision.Metrics)),
+		attribute.Float64("resource.cpu_percent", h.calcCPUPercent(decision.Metrics)),
+		attribute.Float64("deployment.availability_percent", h.calcPodsRatio(decision.Metrics)),
+	}
+	if decision.RandomValue != nil {
+		attrs = append(attrs, attribute.Float64("decision.random_value", *decision.RandomValue))
+	}
+	return attrs
+}
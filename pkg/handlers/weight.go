@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/logger"
+)
+
+// Envoy主动健康检查识别的响应头，带有该头的主机被视为降级，
+// 只在健康主机不足时才会分配流量
+const envoyDegradedHeader = "x-envoy-degraded"
+
+// WeightHandler 返回期望的流量权重(0-100)，供支持权重的负载均衡器逐步减少流量。
+// 与/healthz共享同一个决策，EVALUATION_INTERVAL内复用最近一次决策，权重为0时返回503
+type WeightHandler struct {
+	HealthHandler *HealthHandler
+}
+
+// NewWeightHandler 创建权重处理器
+func NewWeightHandler(healthHandler *HealthHandler) *WeightHandler {
+	return &WeightHandler{HealthHandler: healthHandler}
+}
+
+// ServeHTTP 实现http.Handler接口。默认返回JSON，
+// 请求参数format=text或Accept为text/plain时只返回权重数字
+func (h *WeightHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	startTime := time.Now()
+
+	decision, err := h.HealthHandler.CachedDecision(r.Context())
+	if err != nil {
+		log.WithError(err).WithField(logger.FieldReasonCode, ReasonMetricsUnavailable).Error(i18n.T("weight.failed"))
+		message := i18n.T("weight.failed_response", err)
+		if wantsText(r) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, message)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		h.HealthHandler.writeJSONResponse(w, errorResponse(ReasonMetricsUnavailable, message))
+		return
+	}
+
+	w.Header().Set("X-Weight", strconv.Itoa(decision.Weight))
+	if decision.Weight > 0 && decision.Weight < 100 {
+		w.Header().Set(envoyDegradedHeader, "true")
+	}
+	statusCode := http.StatusOK
+	if decision.Weight == 0 {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	if wantsText(r) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(statusCode)
+		fmt.Fprintf(w, "%d\n", decision.Weight)
+	} else {
+		w.WriteHeader(statusCode)
+		h.HealthHandler.writeJSONResponse(w, map[string]interface{}{
+			"weight":      decision.Weight,
+			"status":      decision.Status,
+			"reason_code": decision.Reason,
+			"message":     decision.Message,
+		})
+	}
+
+	log.WithFields(logrus.Fields{
+		"status":               decision.Status,
+		logger.FieldReasonCode: decision.Reason,
+		"weight":               decision.Weight,
+		"duration":             time.Since(startTime),
+	}).Log(pathLevel(h.HealthHandler.CurrentConfig()), i18n.T("weight.request_completed"))
+}
+
+// wantsText 判断请求是否需要纯文本格式的权重
+func wantsText(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == "text"
+	}
+	return strings.HasPrefix(r.Header.Get("Accept"), "text/plain")
+}
@@ -0,0 +1,113 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Lang 日志和响应中人类可读消息的语言
+type Lang string
+
+// 支持的语言
+const (
+	Chinese Lang = "zh"
+	English Lang = "en"
+)
+
+// 当前语言，启动时由SetLanguage设置，默认中文
+var current atomic.Value
+
+// Parse 解析LANG格式的语言设置，如zh、en、en_US.UTF-8、zh-CN，只取语言部分且不区分大小写
+func Parse(value string) (Lang, bool) {
+	lang := strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	switch Lang(lang) {
+	case Chinese, English:
+		return Lang(lang), true
+	}
+	return Chinese, false
+}
+
+// localeEnvs 未显式指定语言时依次读取的系统语言环境变量，优先级与POSIX一致
+var localeEnvs = []string{"LC_ALL", "LC_MESSAGES", "LANG"}
+
+// Detect 返回value指定的语言，value为空时取LC_ALL、LC_MESSAGES、LANG中第一个非空的值，
+// 不支持的语言（如C、fr_FR）使用中文
+func Detect(value string) Lang {
+	for _, name := range localeEnvs {
+		if value != "" {
+			break
+		}
+		value = os.Getenv(name)
+	}
+	lang, _ := Parse(value)
+	return lang
+}
+
+// SetLanguage 设置之后的日志和响应使用的语言
+func SetLanguage(lang Lang) {
+	current.Store(lang)
+}
+
+// Language 返回当前语言
+func Language() Lang {
+	if lang, ok := current.Load().(Lang); ok {
+		return lang
+	}
+	return Chinese
+}
+
+// T 按当前语言返回key对应的消息，args为消息中的格式化参数
+func T(key string, args ...interface{}) string {
+	return Language().T(key, args...)
+}
+
+// T 返回key在该语言下的消息，该语言缺少时使用中文，都缺少时返回key本身
+func (l Lang) T(key string, args ...interface{}) string {
+	return fmt.Sprintf(l.format(key), args...)
+}
+
+// Errorf 按当前语言返回key对应的错误，消息中的%w与fmt.Errorf一样包装错误
+func Errorf(key string, args ...interface{}) error {
+	return fmt.Errorf(Language().format(key), args...)
+}
+
+// format 返回key在该语言下的格式化字符串
+func (l Lang) format(key string) string {
+	if format, exists := catalogs[l][key]; exists {
+		return format
+	}
+	if format, exists := catalogs[Chinese][key]; exists {
+		return format
+	}
+	return key
+}
+
+// Code 返回key对应的稳定代码，如reload.failed对应RELOAD_FAILED，用于日志的reason_code字段
+func Code(key string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// 各语言中不含格式化参数的消息到键的反查表，用于为日志补充原因代码
+var keysByMessage = func() map[Lang]map[string]string {
+	reverse := make(map[Lang]map[string]string, len(catalogs))
+	for lang, catalog := range catalogs {
+		reverse[lang] = make(map[string]string, len(catalog))
+		for key, format := range catalog {
+			if !strings.Contains(format, "%") {
+				reverse[lang][format] = key
+			}
+		}
+	}
+	return reverse
+}()
+
+// Lookup 返回当前语言中消息message对应的键，只能反查不含格式化参数的消息
+func Lookup(message string) (string, bool) {
+	key, exists := keysByMessage[Language()][message]
+	return key, exists
+}
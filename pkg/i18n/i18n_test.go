@@ -0,0 +1,164 @@
+package i18n
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Lang
+		ok       bool
+	}{
+		{"zh", Chinese, true},
+		{"en", English, true},
+		{"en_US.UTF-8", English, true},
+		{"zh-CN", Chinese, true},
+		{"EN", English, true},
+		{"fr_FR", Chinese, false},
+		{"", Chinese, false},
+	}
+	for _, test := range tests {
+		lang, ok := Parse(test.value)
+		if lang != test.expected || ok != test.ok {
+			t.Errorf("Parse(%q) = %s, %v; 期望 %s, %v", test.value, lang, ok, test.expected, test.ok)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := English.T("decision.container_not_ready", "app"); got != "container app is not ready" {
+		t.Errorf("English.T = %q; 期望 %q", got, "container app is not ready")
+	}
+	if got := Chinese.T("decision.container_not_ready", "app"); got != "容器 app 尚未就绪" {
+		t.Errorf("Chinese.T = %q; 期望 %q", got, "容器 app 尚未就绪")
+	}
+	if got := English.T("unknown.key"); got != "unknown.key" {
+		t.Errorf("未知的键 = %q; 期望返回键本身", got)
+	}
+
+	SetLanguage(English)
+	defer SetLanguage(Chinese)
+	if got := T("health.failed"); got != "health check failed" {
+		t.Errorf("T = %q; 期望使用当前语言", got)
+	}
+}
+
+// 所有语言的目录必须包含相同的键，且格式化参数一致
+func TestCatalogsConsistent(t *testing.T) {
+	verbs := regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+	reference := catalogs[Chinese]
+	for lang, catalog := range catalogs {
+		for key, format := range reference {
+			translated, exists := catalog[key]
+			if !exists {
+				t.Errorf("%s缺少%s", lang, key)
+				continue
+			}
+			expected := strings.Join(verbs.FindAllString(format, -1), " ")
+			if got := strings.Join(verbs.FindAllString(translated, -1), " "); got != expected {
+				t.Errorf("%s的%s格式化参数 = %q; 期望 %q", lang, key, got, expected)
+			}
+		}
+		for key := range catalog {
+			if _, exists := reference[key]; !exists {
+				t.Errorf("%s多出%s", lang, key)
+			}
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "en_US.UTF-8")
+	if got := Detect(""); got != English {
+		t.Errorf("Detect(\"\") = %s; 期望使用LANG的%s", got, English)
+	}
+	if got := Detect("zh"); got != Chinese {
+		t.Errorf("Detect(\"zh\") = %s; 期望 %s", got, Chinese)
+	}
+	t.Setenv("LC_ALL", "C")
+	if got := Detect(""); got != Chinese {
+		t.Errorf("LC_ALL=C时Detect(\"\") = %s; 期望 %s", got, Chinese)
+	}
+}
+
+func TestErrorf(t *testing.T) {
+	cause := errors.New("boom")
+	err := Errorf("k8s.get_pod_failed", cause)
+	if err.Error() != "获取Pod失败: boom" {
+		t.Errorf("Errorf = %q; 期望 %q", err.Error(), "获取Pod失败: boom")
+	}
+
+	SetLanguage(English)
+	defer SetLanguage(Chinese)
+	if err := Errorf("k8s.get_pod_failed", cause); err.Error() != "failed to get pod: boom" {
+		t.Errorf("Errorf = %q; 期望使用当前语言", err.Error())
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if key, ok := Lookup("配置热更新失败，继续使用上一次有效的配置"); !ok || key != "reload.failed" {
+		t.Errorf("Lookup = %q, %v; 期望 reload.failed, true", key, ok)
+	}
+	if _, ok := Lookup("获取Pod失败: %v"); ok {
+		t.Errorf("含格式化参数的消息不应反查")
+	}
+	if got := Code("reload.env_pinned"); got != "RELOAD_ENV_PINNED" {
+		t.Errorf("Code = %q; 期望 RELOAD_ENV_PINNED", got)
+	}
+}
+
+// 不含格式化参数的消息用于反查原因代码，同一语言中不能重复
+func TestMessagesUnique(t *testing.T) {
+	for lang, catalog := range catalogs {
+		seen := make(map[string]string)
+		for key, format := range catalog {
+			if strings.Contains(format, "%") {
+				continue
+			}
+			if other, exists := seen[format]; exists {
+				t.Errorf("%s中%s和%s的消息相同: %q", lang, key, other, format)
+			}
+			seen[format] = key
+		}
+	}
+}
+
+// 源码中通过i18n.T和i18n.Errorf引用的键必须在所有语言的目录中存在，缺少时消息会退化为键本身
+func TestReferencedKeysExist(t *testing.T) {
+	reference := regexp.MustCompile(`i18n\.(?:T|Errorf)\("([^"]+)"`)
+	found := 0
+	for _, root := range []string{"../../pkg", "../../cmd"} {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || filepath.Ext(path) != ".go" {
+				return err
+			}
+			source, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			for _, match := range reference.FindAllStringSubmatch(string(source), -1) {
+				found++
+				for lang, catalog := range catalogs {
+					if _, exists := catalog[match[1]]; !exists {
+						t.Errorf("%s引用的%s在%s中不存在", path, match[1], lang)
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("扫描%s失败: %v", root, err)
+		}
+	}
+	if found == 0 {
+		t.Fatal("没有找到任何i18n.T或i18n.Errorf调用")
+	}
+}
@@ -0,0 +1,672 @@
+package i18n
+
+// catalogs 各语言的消息目录，键在所有语言中相同，格式化参数的顺序和类型必须一致
+var catalogs = map[Lang]map[string]string{
+	Chinese: {
+		// 决策消息
+		"decision.container_not_ready": "容器 %s 尚未就绪",
+		"decision.app_probe_failed":    "容器 %s 尚未就绪: %s",
+		"decision.pod_shortage":        "可用Pod数量(%d/%d = %.2f%%)低于最小阈值(%.2f%%)",
+		"decision.healthy":             "健康检查通过: 内存使用率 %.2f%%, CPU使用率 %.2f%%, Pod可用率 %.2f%%",
+		"decision.resource_exhausted":  "资源使用率过高: 内存使用 %dMB/%.2f%% (阈值: %s), CPU使用 %dm/%.2f%% (阈值: %s)",
+		"decision.overloaded_keeping":  "资源使用率过高但随机退避生效: 内存使用率 %.2f%%, CPU使用率 %.2f%%, 随机值 %.2f",
+
+		// 健康检查
+		"health.state_changed":          "健康检查状态变化",
+		"health.periodic_failed":        "定期健康检查失败",
+		"health.request_started":        "开始处理健康检查请求",
+		"health.failed":                 "健康检查失败",
+		"health.failed_response":        "健康检查失败: 无法收集资源指标 - %v",
+		"health.request_completed":      "健康检查请求处理完成",
+		"health.not_ready":              "健康检查结果: 容器尚未就绪",
+		"health.pod_shortage":           "健康检查结果: 可用Pod数量低于最小阈值，继续服务",
+		"health.shedding_previously":    "健康检查结果: 之前已决策固定拒绝流量",
+		"health.random_shed":            "首次随机决策: 固定拒绝流量",
+		"health.shedding":               "健康检查结果: 固定拒绝流量",
+		"health.random_keep":            "首次随机决策: 继续随机决策",
+		"health.collect_started":        "开始收集资源指标",
+		"health.collect_completed":      "资源指标收集完成",
+		"health.get_deployment":         "获取Deployment信息",
+		"health.get_deployment_failed":  "获取Deployment信息失败",
+		"health.deployment_info":        "Deployment信息",
+		"health.get_limits":             "获取容器资源限制",
+		"health.get_limits_failed":      "获取容器资源限制失败",
+		"health.limits_error":           "无法获取容器资源限制: %v",
+		"health.limits":                 "容器资源限制",
+		"health.app_probe":              "执行应用就绪探针",
+		"health.app_probe_failed":       "应用就绪探针失败",
+		"health.get_pod":                "获取Pod信息",
+		"health.get_pod_failed":         "获取Pod信息失败",
+		"health.container_ready":        "容器就绪状态",
+		"health.get_pod_metrics":        "获取Pod度量指标",
+		"health.get_pod_metrics_failed": "获取Pod度量指标失败",
+		"health.container_usage":        "容器资源使用",
+		"health.json_failed":            "序列化JSON失败",
+
+		// 权重和指标API
+		"weight.failed":              "计算权重失败",
+		"weight.failed_response":     "计算权重失败: 无法收集资源指标 - %v",
+		"weight.request_completed":   "权重请求处理完成",
+		"metrics.request_started":    "开始处理指标API请求",
+		"metrics.failed":             "获取指标失败",
+		"metrics.failed_response":    "获取指标失败: %v",
+		"metrics.collected":          "成功收集到指标数据，正在返回JSON响应",
+		"metrics.request_completed":  "指标API请求处理完成",
+		"metrics.pod_metrics_failed": "获取Pod度量指标失败: %w",
+
+		// Pod事件
+		"event.shedding_stopped":    "恢复接收流量，当前状态: %s",
+		"event.metrics_unavailable": "无法获取Pod度量指标，资源使用率按0计算: %s",
+		"event.metrics_recovered":   "Pod度量指标已恢复",
+
+		// HTTP访问日志
+		"http.request_completed": "HTTP请求完成",
+
+		// 通用
+		"common.none":             "无",
+		"common.invalid_quantity": "%s=%q不是有效的数量: %v",
+		"common.invalid_port":     "%s=%q不是有效的端口",
+
+		// 配置加载和校验
+		"config.read_file_failed":               "读取配置文件失败: %v",
+		"config.parse_file_failed":              "解析配置文件%s失败: %v",
+		"config.file_not_object":                "配置文件%s的顶层必须是对象: %v",
+		"config.file_type_error":                "配置文件字段类型错误: %v",
+		"config.unknown_field":                  "未知的配置字段: %s%s",
+		"config.file_evaluation_interval":       "podStatus.evaluationInterval=%q不是有效的时长",
+		"config.file_otlp_metrics_interval":     "otlpMetrics.interval=%q不是有效的时长",
+		"config.file_statsd_flush_interval":     "statsd.flushInterval=%q不是有效的时长",
+		"config.file_log_sample_window":         "log.sampling.window=%q不是有效的时长",
+		"config.invalid":                        "配置无效，共%d个问题:",
+		"config.required":                       "缺少必填配置: %s",
+		"config.percent_range":                  "%s=%.2f超出范围，必须在0到100之间",
+		"config.weight_drain_start_range":       "WEIGHT_DRAIN_START_PERCENT (weight.drainStartPercent)=%.2f超出范围，必须大于等于0且小于100",
+		"config.weight_min_range":               "WEIGHT_MIN (weight.min)=%d超出范围，必须在0到100之间",
+		"config.history_size_negative":          "DECISION_HISTORY_SIZE (debug.decisionHistorySize)不能为负数: %d",
+		"config.memory_threshold_negative":      "RESOURCE_THRESHOLD_MEMORY (thresholds.memory)不能为负数: %dMB",
+		"config.cpu_threshold_negative":         "RESOURCE_THRESHOLD_CPU (thresholds.cpu)不能为负数: %dm",
+		"config.overload_policy":                "OVERLOAD_POLICY (policy.overload)=%q无效，可选值: %s, %s",
+		"config.state_label":                    "POD_STATE_LABEL (podStatus.stateLabel)=%q不是有效的标签名: %s",
+		"config.evaluation_interval":            "EVALUATION_INTERVAL (podStatus.evaluationInterval)=%s必须大于0",
+		"config.http_port":                      "HTTP_PORT (http.port)=%q不是有效的端口",
+		"config.log_level":                      "LOG_LEVEL (log.level)=%q无效，可选值: debug, info, warn, error",
+		"config.log_component_level":            "LOG_COMPONENT_LEVELS (log.levels)中%s=%q无效，可选值: debug, info, warn, error",
+		"config.log_format":                     "LOG_FORMAT (log.format)=%q无效，可选值: %s, %s, %s",
+		"config.log_timestamp_format":           "LOG_TIMESTAMP_FORMAT (log.timestampFormat)不能为空，不输出时间戳时设置为none",
+		"config.message_lang":                   "MESSAGE_LANG (messages.lang)=%q无效，可选值: zh, en",
+		"config.log_sample_burst":               "LOG_SAMPLE_BURST (log.sampling.burst)=%d不能为负数",
+		"config.log_sample_window":              "LOG_SAMPLE_WINDOW (log.sampling.window)=%s必须大于0",
+		"config.basis_value":                    "%s为absolute时必须设置大于0的基准值",
+		"config.basis":                          "%s=%q无效，可选值: %s, %s, %s, %s",
+		"config.endpointslice_service":          "ENDPOINTSLICE_SERVICE (endpointSlice.service)=%q不是有效的Service名称: %s",
+		"config.endpointslice_ports_required":   "启用EndpointSlice直接控制时必须设置ENDPOINTSLICE_PORTS (endpointSlice.ports)",
+		"config.endpointslice_port_range":       "ENDPOINTSLICE_PORTS (endpointSlice.ports)中的端口%s超出范围",
+		"config.endpointslice_protocol":         "ENDPOINTSLICE_PORTS (endpointSlice.ports)中的端口%s协议无效，可选值: TCP, UDP, SCTP",
+		"config.otlp_endpoint":                  "OTLP_ENDPOINT (otlp.endpoint)=%q不是有效的地址，格式如http://otel-collector:4318",
+		"config.tracing_endpoint_required":      "启用TRACING_ENABLED (tracing.enabled)时必须设置OTLP_ENDPOINT (otlp.endpoint)",
+		"config.otlp_metrics_endpoint_required": "启用OTLP_METRICS_ENABLED (otlpMetrics.enabled)时必须设置OTLP_ENDPOINT (otlp.endpoint)",
+		"config.otlp_metrics_interval":          "OTLP_METRICS_INTERVAL (otlpMetrics.interval)=%s必须大于0",
+		"config.tracing_sample_ratio":           "TRACING_SAMPLE_RATIO (tracing.sampleRatio)=%.2f超出范围，必须在0到1之间",
+		"config.statsd_address":                 "STATSD_ADDRESS (statsd.address)=%q不是有效的地址，格式如127.0.0.1:8125",
+		"config.statsd_flush_interval":          "STATSD_FLUSH_INTERVAL (statsd.flushInterval)=%s必须大于0",
+		"config.statsd_tag":                     "STATSD_TAGS (statsd.tags)中的标签%q无效，不能为空或包含|,#和换行",
+		"config.port_conflict":                  "%s=%s与%s冲突",
+		"config.port_not_number":                "端口%q不是有效的数字",
+		"config.env_float":                      "环境变量%s=%q不是有效的数字",
+		"config.env_int":                        "环境变量%s=%q不是有效的整数",
+		"config.env_bool":                       "环境变量%s=%q不是有效的布尔值",
+		"config.env_cpu":                        "环境变量%s=%q不是有效的CPU数量: %v",
+		"config.env_memory":                     "环境变量%s=%q不是有效的内存数量: %v",
+		"config.env_duration":                   "环境变量%s=%q不是有效的时长",
+		"config.env_map":                        "环境变量%s中的%q不是key=value格式",
+		"config.env_invalid":                    "环境变量%s=%q无效: %v",
+		"config.env_probe":                      "环境变量%s不是有效的探针定义: %v",
+
+		// 配置热更新
+		"reload.watcher_failed":   "创建文件监听器失败: %v",
+		"reload.watch_dir_failed": "监听目录%s失败: %v",
+		"reload.started":          "开始监听配置文件变化",
+		"reload.changed":          "检测到配置目录变化",
+		"reload.watch_error":      "配置文件监听出错",
+		"reload.failed":           "配置热更新失败，继续使用上一次有效的配置",
+		"reload.env_pinned":       "该配置项已由环境变量设置，配置文件中的修改被覆盖，不会生效",
+		"reload.restart_required": "该配置项不支持热更新，需要重启后生效",
+		"reload.unchanged":        "配置文件已变化，但可热更新的配置没有变化",
+		"reload.applied":          "配置已热更新",
+
+		// Kubernetes客户端
+		"k8s.watch_pod_started":      "开始监听Pod变化",
+		"k8s.in_cluster":             "使用InCluster配置连接Kubernetes集群",
+		"k8s.in_cluster_failed":      "创建InCluster配置失败: %v",
+		"k8s.kubeconfig":             "使用kubeconfig文件连接Kubernetes集群",
+		"k8s.kubeconfig_failed":      "构建K8s配置失败: %v",
+		"k8s.clientset_failed":       "创建Kubernetes客户端失败: %v",
+		"k8s.metrics_client_failed":  "创建Metrics客户端失败: %v",
+		"k8s.dynamic_client_failed":  "创建Dynamic客户端失败: %v",
+		"k8s.limits_failed":          "获取容器资源限制失败: %v",
+		"k8s.init_limits":            "初始化: 获取容器资源限制",
+		"k8s.limits_attempt":         "尝试获取容器资源限制",
+		"k8s.get_deployment_failed":  "获取Deployment失败: %v",
+		"k8s.get_deployment_retry":   "获取Deployment信息失败，将重试",
+		"k8s.container_not_found":    "在Deployment[%s]中未找到容器[%s]",
+		"k8s.container_missing":      "警告: 未找到指定容器",
+		"k8s.invalid_limits":         "警告: 资源限制值无效",
+		"k8s.limits_loaded":          "成功获取容器资源限制",
+		"k8s.limits_exhausted":       "经过%d次尝试，无法获取容器资源限制: %v",
+		"k8s.unknown_basis":          "未知的%s使用率基准: %s",
+		"k8s.invalid_denominator":    "无效的%s分母: 基准=%s, 值=%d",
+		"k8s.get_pod_failed":         "获取Pod失败: %v",
+		"k8s.pod_unscheduled":        "Pod[%s]尚未调度到节点",
+		"k8s.get_node_failed":        "获取节点[%s]失败: %v",
+		"k8s.limits_uninitialized":   "容器资源限制未初始化",
+		"k8s.patch_condition_failed": "更新Pod状态条件失败: %v",
+		"k8s.patch_label_failed":     "更新Pod标签失败: %v",
+		"k8s.pod_reference_failed":   "生成Pod引用失败: %v",
+		"k8s.events_enabled":         "已启用Kubernetes事件",
+
+		// 应用就绪探针
+		"probe.handler_missing":  "%s必须设置httpGet或tcpSocket",
+		"probe.handler_conflict": "%s只能设置httpGet和tcpSocket中的一个",
+		"probe.invalid_scheme":   "%s.httpGet.scheme=%q无效，可选值: HTTP, HTTPS",
+		"probe.negative_timeout": "%s.timeoutSeconds不能为负数",
+		"probe.invalid_port":     "%s=%d不是有效的端口",
+		"probe.no_handler":       "探针未设置httpGet或tcpSocket",
+		"probe.request_failed":   "构造探测请求失败: %v",
+		"probe.http_failed":      "HTTP探测失败: %v",
+		"probe.http_status":      "HTTP探测返回状态码%d",
+		"probe.tcp_failed":       "TCP探测失败: %v",
+
+		// 策略覆盖
+		"policy.crd_unavailable":               "无法访问SheddingPolicy，请确认已安装CRD并授予权限，SheddingPolicy不可用",
+		"policy.watch_started":                 "开始监听SheddingPolicy变化",
+		"policy.unparsable":                    "忽略无法解析的SheddingPolicy",
+		"policy.some_invalid":                  "部分SheddingPolicy无效，已忽略",
+		"policy.multiple_matched":              "当前Pod匹配了多个SheddingPolicy，使用最早创建的一个",
+		"policy.remove_failed":                 "移除SheddingPolicy失败，继续使用上一次有效的配置",
+		"policy.none_matched":                  "当前Pod未匹配任何SheddingPolicy",
+		"policy.invalid":                       "SheddingPolicy中的策略无效，继续使用上一次有效的配置",
+		"policy.applied":                       "已应用SheddingPolicy中的策略",
+		"policy.status_remove_failed":          "从SheddingPolicy状态中移除当前Pod失败",
+		"policy.status_update_failed":          "更新SheddingPolicy状态失败",
+		"policy.annotation_percent":            "注解%s=%q不是有效的百分比",
+		"policy.annotation_quantity":           "注解%s=%q不是有效的数量: %v",
+		"policy.annotation_unknown":            "未知的注解: %s",
+		"policy.annotations_partially_invalid": "Pod注解中存在无效的策略，已忽略这些注解",
+		"policy.annotations_invalid":           "Pod注解中的策略无效，继续使用上一次有效的配置",
+		"policy.annotations_applied":           "已应用Pod注解中的策略",
+		"policy.parse_failed":                  "解析SheddingPolicy %s失败: %v",
+		"policy.invalid_selector":              "SheddingPolicy %s的selector无效: %v",
+		"policy.layer_invalid":                 "覆盖层%s导致配置无效: %v",
+
+		// Pod状态上报
+		"podstatus.update_failed": "更新Pod状态失败，稍后重试",
+		"podstatus.updated":       "已更新Pod状态",
+
+		// EndpointSlice直接控制
+		"endpointslice.sync_failed":   "更新EndpointSlice失败，稍后重试",
+		"endpointslice.create_failed": "创建EndpointSlice[%s]失败: %v",
+		"endpointslice.created":       "已创建EndpointSlice",
+		"endpointslice.get_failed":    "获取EndpointSlice[%s]失败: %v",
+		"endpointslice.foreign":       "EndpointSlice[%s]由%q管理，拒绝覆盖",
+		"endpointslice.delete_failed": "删除EndpointSlice[%s]失败: %v",
+		"endpointslice.recreated":     "已重建EndpointSlice",
+		"endpointslice.update_failed": "更新EndpointSlice[%s]失败: %v",
+		"endpointslice.updated":       "已更新EndpointSlice",
+
+		// 决策历史
+		"history.invalid_since": "参数since无效: %v",
+		"history.invalid_until": "参数until无效: %v",
+		"history.invalid_limit": "参数limit=%q不是有效的非负整数",
+		"history.invalid_time":  "%q既不是RFC3339时间也不是有效的时长",
+
+		// 负载报告
+		"loadreport.no_decision":   "尚未做出决策，暂无负载报告",
+		"loadreport.encode_failed": "序列化负载报告失败",
+
+		// HAProxy agent-check
+		"agentcheck.listen_failed":   "监听agent-check端口失败: %v",
+		"agentcheck.started":         "agent-check服务已启动",
+		"agentcheck.accept_failed":   "接受agent-check连接失败",
+		"agentcheck.decision_failed": "agent-check决策失败",
+		"agentcheck.write_failed":    "写入agent-check响应失败",
+		"agentcheck.responded":       "已响应agent-check",
+
+		// gRPC健康检查
+		"grpchealth.decision_failed": "gRPC健康检查决策失败",
+		"grpchealth.responded":       "已响应gRPC健康检查",
+		"grpchealth.listen_failed":   "监听gRPC健康检查端口失败: %v",
+		"grpchealth.register_failed": "注册gRPC服务失败: %v",
+		"grpchealth.started":         "gRPC健康检查服务已启动",
+		"grpchealth.serve_failed":    "gRPC健康检查服务错误: %v",
+
+		// StatsD
+		"statsd.dial_failed": "连接StatsD接收端失败: %v",
+		"statsd.started":     "StatsD发送器已启动",
+		"statsd.send_failed": "发送StatsD指标失败",
+
+		// OpenTelemetry
+		"telemetry.trace_exporter_failed":  "创建OTLP追踪导出器失败: %v",
+		"telemetry.endpoint_invalid":       "解析OTLP接收端地址失败: %v",
+		"telemetry.endpoint_scheme":        "OTLP接收端地址%q必须是http://或https://开头的地址",
+		"telemetry.metric_exporter_failed": "创建OTLP指标导出器失败: %v",
+
+		// 指标说明
+		"metric.kube_request_duration":  "Kubernetes API请求的耗时（秒）",
+		"metric.kube_requests":          "Kubernetes API请求次数，code为HTTP状态码，网络错误时为<error>",
+		"metric.kube_request_errors":    "失败的Kubernetes API请求次数（网络错误或状态码不低于400）",
+		"metric.kube_rate_limiter_wait": "请求在client-go客户端限流器中等待的时间（秒）",
+		"metric.kube_retries":           "client-go重试Kubernetes API请求的次数",
+		"metric.decisions":              "按结果状态统计的决策次数",
+		"metric.random_draws":           "随机退避抽取随机值的次数，result为shed（拒绝流量）或keep（保持服务）",
+		"metric.shedding_seconds":       "处于拒绝流量状态的累计时间（秒）",
+		"metric.shedding_episodes":      "进入拒绝流量状态的次数",
+		"metric.state":                  "当前状态，当前状态为1，其他状态为0",
+		"metric.state_since":            "进入当前状态的Unix时间戳",
+		"metric.weight":                 "当前的流量权重(0-100)",
+		"metric.last_decision":          "最近一次决策的Unix时间戳",
+		"metric.cpu_usage":              "目标容器的CPU使用量（核）",
+		"metric.cpu_limit":              "计算CPU使用率的分母（核），basis为其来源",
+		"metric.cpu_utilization":        "CPU使用量与分母的比值，可能大于1",
+		"metric.memory_utilization":     "内存使用量与分母的比值",
+		"metric.availability":           "Deployment可用副本数与期望副本数的比值",
+		"metric.memory_usage":           "目标容器的内存使用量",
+		"metric.memory_limit":           "计算内存使用率的分母，basis为其来源",
+		"metric.ready":                  "目标容器是否就绪，就绪为1",
+		"metric.replicas":               "Deployment的期望副本数",
+		"metric.available_replicas":     "Deployment的可用副本数",
+
+		// 日志
+		"logger.sampled":            "重复日志已被采样丢弃",
+		"logger.initialized":        "日志系统初始化完成",
+		"logger.level_reverted":     "日志级别修改已到期，恢复原级别",
+		"logger.method_not_allowed": "只支持GET和PUT请求",
+		"logger.invalid_json":       "请求体不是有效的JSON: %v",
+		"logger.invalid_level":      "level=%q无效，可选值: debug, info, warn, error",
+		"logger.unknown_component":  "component=%q不存在，可选值: %s",
+		"logger.invalid_timeout":    "timeout=%q不是有效的正时长",
+		"logger.level_changed":      "日志级别已修改",
+
+		// sidecar注入器
+		"injector.container_not_found":     "Pod中不存在容器%q",
+		"injector.probe_unsupported":       "容器%s原有的就绪探针无法由sidecar执行（%v），已改用readinessGates并保留原探针",
+		"injector.probe_handler":           "仅支持httpGet和tcpSocket探针",
+		"injector.port_not_found":          "容器中不存在名为%q的端口",
+		"injector.deployment_unknown":      "无法推断Pod所属的Deployment，请设置注解%s",
+		"injector.post_only":               "仅支持POST请求",
+		"injector.read_failed":             "读取请求失败: %v",
+		"injector.invalid_review_received": "收到无效的AdmissionReview请求",
+		"injector.invalid_review":          "无效的AdmissionReview请求",
+		"injector.write_failed":            "写入AdmissionReview响应失败",
+		"injector.decode_pod_failed":       "解析Pod失败，跳过注入",
+		"injector.skipped":                 "Pod未启用注入或已注入，跳过",
+		"injector.inject_failed":           "注入sidecar失败",
+		"injector.inject_warning":          "metrics-sidecar注入失败: %v",
+		"injector.patch_failed":            "序列化JSON Patch失败",
+		"injector.injected":                "已注入metrics-sidecar",
+		"injector.sidecar_port":            "SIDECAR_PORT=%q不是有效的端口",
+		"injector.sidecar_image_required":  "缺少必填配置: SIDECAR_IMAGE",
+
+		// 启动和关闭
+		"main.starting":                    "指标采集服务启动中...",
+		"main.in_cluster":                  "使用集群内配置模式 (InCluster)",
+		"main.kubeconfig":                  "使用外部配置文件模式",
+		"main.config_loaded":               "加载配置完成",
+		"main.index":                       "指标采集服务正在运行\n\n可用接口:\n- /healthz: 健康检查\n- /metrics: 资源指标\n- /weight: 流量权重\n- /metrics/prometheus: 自身指标",
+		"main.metrics_api_checking":        "正在检查metrics.k8s.io API是否可用...",
+		"main.metrics_api_unavailable":     "metrics.k8s.io API不可用: %v",
+		"main.metrics_api_available":       "metrics.k8s.io API检查通过",
+		"main.tracing_failed":              "启用OpenTelemetry追踪失败",
+		"main.creating_client":             "正在创建Kubernetes客户端...",
+		"main.fatal":                       "致命错误",
+		"main.client_created":              "Kubernetes客户端创建成功",
+		"main.warning":                     "警告",
+		"main.metrics_server_missing":      "metrics-server未安装或不可用，程序无法继续运行",
+		"main.creating_collector":          "正在创建指标收集器...",
+		"main.collector_created":           "指标收集器创建成功",
+		"main.creating_handlers":           "正在创建HTTP处理器...",
+		"main.handlers_created":            "HTTP处理器创建成功",
+		"main.config_updated":              "生效配置已更新",
+		"main.reload_unavailable":          "配置文件监听失败，热更新不可用",
+		"main.events_failed":               "启用Kubernetes事件失败，将不会记录事件",
+		"main.otlp_metrics_failed":         "启用OTLP指标推送失败",
+		"main.otlp_instruments_failed":     "创建OTLP指标失败",
+		"main.statsd_failed":               "StatsD发送器失败",
+		"main.orca_http_only":              "未设置GRPC_HEALTH_PORT，ORCA负载报告只通过/load接口的响应头提供",
+		"main.grpchealth_failed":           "gRPC健康检查服务失败",
+		"main.agentcheck_failed":           "agent-check服务失败",
+		"main.http_starting":               "启动HTTP服务器",
+		"main.started":                     "指标采集服务已启动，等待请求...",
+		"main.http_failed":                 "HTTP服务器错误",
+		"main.shutting_down":               "收到终止信号，开始优雅关闭...",
+		"main.endpoint_terminating_failed": "将EndpointSlice端点标记为terminating失败",
+		"main.shutdown_failed":             "服务器关闭错误",
+		"main.trace_flush_failed":          "导出剩余的追踪数据失败",
+		"main.metrics_flush_failed":        "推送最后一次OTLP指标失败",
+		"main.stopped":                     "服务器已安全关闭",
+		"main.injector_started":            "sidecar注入器已启动",
+		"main.https_failed":                "HTTPS服务器错误",
+		"main.config_failed":               "加载配置失败: %v",
+	},
+	English: {
+		"decision.container_not_ready": "container %s is not ready",
+		"decision.app_probe_failed":    "container %s is not ready: %s",
+		"decision.pod_shortage":        "available pods (%d/%d = %.2f%%) below minimum threshold (%.2f%%)",
+		"decision.healthy":             "health check passed: memory usage %.2f%%, CPU usage %.2f%%, pod availability %.2f%%",
+		"decision.resource_exhausted":  "resource usage too high: memory %dMB/%.2f%% (threshold: %s), CPU %dm/%.2f%% (threshold: %s)",
+		"decision.overloaded_keeping":  "resource usage too high but kept by random backoff: memory usage %.2f%%, CPU usage %.2f%%, random value %.2f",
+
+		"health.state_changed":          "health status changed",
+		"health.periodic_failed":        "periodic health check failed",
+		"health.request_started":        "handling health check request",
+		"health.failed":                 "health check failed",
+		"health.failed_response":        "health check failed: unable to collect resource metrics - %v",
+		"health.request_completed":      "health check request completed",
+		"health.not_ready":              "health check result: container is not ready",
+		"health.pod_shortage":           "health check result: available pods below minimum threshold, keeping traffic",
+		"health.shedding_previously":    "health check result: shedding traffic as previously decided",
+		"health.random_shed":            "random draw: shedding traffic until resources recover",
+		"health.shedding":               "health check result: shedding traffic",
+		"health.random_keep":            "random draw: keeping traffic, will draw again",
+		"health.collect_started":        "collecting resource metrics",
+		"health.collect_completed":      "resource metrics collected",
+		"health.get_deployment":         "getting deployment info",
+		"health.get_deployment_failed":  "failed to get deployment info",
+		"health.deployment_info":        "deployment info",
+		"health.get_limits":             "getting container resource limits",
+		"health.get_limits_failed":      "failed to get container resource limits",
+		"health.limits_error":           "unable to get container resource limits: %v",
+		"health.limits":                 "container resource limits",
+		"health.app_probe":              "running application readiness probe",
+		"health.app_probe_failed":       "application readiness probe failed",
+		"health.get_pod":                "getting pod info",
+		"health.get_pod_failed":         "failed to get pod info",
+		"health.container_ready":        "container readiness",
+		"health.get_pod_metrics":        "getting pod metrics",
+		"health.get_pod_metrics_failed": "failed to get pod metrics",
+		"health.container_usage":        "container resource usage",
+		"health.json_failed":            "failed to encode JSON",
+
+		"weight.failed":              "failed to compute weight",
+		"weight.failed_response":     "failed to compute weight: unable to collect resource metrics - %v",
+		"weight.request_completed":   "weight request completed",
+		"metrics.request_started":    "handling metrics API request",
+		"metrics.failed":             "failed to collect metrics",
+		"metrics.failed_response":    "failed to collect metrics: %v",
+		"metrics.collected":          "metrics collected, writing JSON response",
+		"metrics.request_completed":  "metrics API request completed",
+		"metrics.pod_metrics_failed": "failed to get pod metrics: %w",
+
+		"event.shedding_stopped":    "accepting traffic again, current status: %s",
+		"event.metrics_unavailable": "unable to get pod metrics, resource usage treated as 0: %s",
+		"event.metrics_recovered":   "pod metrics recovered",
+
+		"http.request_completed": "HTTP request completed",
+
+		"common.none":             "none",
+		"common.invalid_quantity": "%s=%q is not a valid quantity: %v",
+		"common.invalid_port":     "%s=%q is not a valid port",
+
+		"config.read_file_failed":               "failed to read config file: %v",
+		"config.parse_file_failed":              "failed to parse config file %s: %v",
+		"config.file_not_object":                "config file %s must have an object at the top level: %v",
+		"config.file_type_error":                "config file field has the wrong type: %v",
+		"config.unknown_field":                  "unknown config field: %s%s",
+		"config.file_evaluation_interval":       "podStatus.evaluationInterval=%q is not a valid duration",
+		"config.file_otlp_metrics_interval":     "otlpMetrics.interval=%q is not a valid duration",
+		"config.file_statsd_flush_interval":     "statsd.flushInterval=%q is not a valid duration",
+		"config.file_log_sample_window":         "log.sampling.window=%q is not a valid duration",
+		"config.invalid":                        "invalid configuration, %d problem(s):",
+		"config.required":                       "missing required setting: %s",
+		"config.percent_range":                  "%s=%.2f is out of range, must be between 0 and 100",
+		"config.weight_drain_start_range":       "WEIGHT_DRAIN_START_PERCENT (weight.drainStartPercent)=%.2f is out of range, must be at least 0 and below 100",
+		"config.weight_min_range":               "WEIGHT_MIN (weight.min)=%d is out of range, must be between 0 and 100",
+		"config.history_size_negative":          "DECISION_HISTORY_SIZE (debug.decisionHistorySize) must not be negative: %d",
+		"config.memory_threshold_negative":      "RESOURCE_THRESHOLD_MEMORY (thresholds.memory) must not be negative: %dMB",
+		"config.cpu_threshold_negative":         "RESOURCE_THRESHOLD_CPU (thresholds.cpu) must not be negative: %dm",
+		"config.overload_policy":                "OVERLOAD_POLICY (policy.overload)=%q is invalid, valid values: %s, %s",
+		"config.state_label":                    "POD_STATE_LABEL (podStatus.stateLabel)=%q is not a valid label name: %s",
+		"config.evaluation_interval":            "EVALUATION_INTERVAL (podStatus.evaluationInterval)=%s must be greater than 0",
+		"config.http_port":                      "HTTP_PORT (http.port)=%q is not a valid port",
+		"config.log_level":                      "LOG_LEVEL (log.level)=%q is invalid, valid values: debug, info, warn, error",
+		"config.log_component_level":            "LOG_COMPONENT_LEVELS (log.levels) has invalid %s=%q, valid values: debug, info, warn, error",
+		"config.log_format":                     "LOG_FORMAT (log.format)=%q is invalid, valid values: %s, %s, %s",
+		"config.log_timestamp_format":           "LOG_TIMESTAMP_FORMAT (log.timestampFormat) must not be empty, set it to none to omit timestamps",
+		"config.message_lang":                   "MESSAGE_LANG (messages.lang)=%q is invalid, valid values: zh, en",
+		"config.log_sample_burst":               "LOG_SAMPLE_BURST (log.sampling.burst)=%d must not be negative",
+		"config.log_sample_window":              "LOG_SAMPLE_WINDOW (log.sampling.window)=%s must be greater than 0",
+		"config.basis_value":                    "%s is absolute and requires a basis value greater than 0",
+		"config.basis":                          "%s=%q is invalid, valid values: %s, %s, %s, %s",
+		"config.endpointslice_service":          "ENDPOINTSLICE_SERVICE (endpointSlice.service)=%q is not a valid Service name: %s",
+		"config.endpointslice_ports_required":   "ENDPOINTSLICE_PORTS (endpointSlice.ports) is required when EndpointSlice control is enabled",
+		"config.endpointslice_port_range":       "ENDPOINTSLICE_PORTS (endpointSlice.ports) port %s is out of range",
+		"config.endpointslice_protocol":         "ENDPOINTSLICE_PORTS (endpointSlice.ports) port %s has an invalid protocol, valid values: TCP, UDP, SCTP",
+		"config.otlp_endpoint":                  "OTLP_ENDPOINT (otlp.endpoint)=%q is not a valid address, e.g. http://otel-collector:4318",
+		"config.tracing_endpoint_required":      "OTLP_ENDPOINT (otlp.endpoint) is required when TRACING_ENABLED (tracing.enabled) is set",
+		"config.otlp_metrics_endpoint_required": "OTLP_ENDPOINT (otlp.endpoint) is required when OTLP_METRICS_ENABLED (otlpMetrics.enabled) is set",
+		"config.otlp_metrics_interval":          "OTLP_METRICS_INTERVAL (otlpMetrics.interval)=%s must be greater than 0",
+		"config.tracing_sample_ratio":           "TRACING_SAMPLE_RATIO (tracing.sampleRatio)=%.2f is out of range, must be between 0 and 1",
+		"config.statsd_address":                 "STATSD_ADDRESS (statsd.address)=%q is not a valid address, e.g. 127.0.0.1:8125",
+		"config.statsd_flush_interval":          "STATSD_FLUSH_INTERVAL (statsd.flushInterval)=%s must be greater than 0",
+		"config.statsd_tag":                     "STATSD_TAGS (statsd.tags) tag %q is invalid, it must be non-empty and must not contain |, # or newlines",
+		"config.port_conflict":                  "%s=%s conflicts with %s",
+		"config.port_not_number":                "port %q is not a valid number",
+		"config.env_float":                      "environment variable %s=%q is not a valid number",
+		"config.env_int":                        "environment variable %s=%q is not a valid integer",
+		"config.env_bool":                       "environment variable %s=%q is not a valid boolean",
+		"config.env_cpu":                        "environment variable %s=%q is not a valid CPU quantity: %v",
+		"config.env_memory":                     "environment variable %s=%q is not a valid memory quantity: %v",
+		"config.env_duration":                   "environment variable %s=%q is not a valid duration",
+		"config.env_map":                        "environment variable %s has %q, which is not in key=value form",
+		"config.env_invalid":                    "environment variable %s=%q is invalid: %v",
+		"config.env_probe":                      "environment variable %s is not a valid probe definition: %v",
+
+		"reload.watcher_failed":   "failed to create file watcher: %v",
+		"reload.watch_dir_failed": "failed to watch directory %s: %v",
+		"reload.started":          "watching config file changes",
+		"reload.changed":          "config directory changed",
+		"reload.watch_error":      "config file watcher error",
+		"reload.failed":           "config reload failed, keeping the last valid configuration",
+		"reload.env_pinned":       "setting is pinned by an environment variable, the config file change is overridden and has no effect",
+		"reload.restart_required": "setting cannot be reloaded, restart to apply it",
+		"reload.unchanged":        "config file changed, but no reloadable setting changed",
+		"reload.applied":          "configuration reloaded",
+
+		"k8s.watch_pod_started":      "watching pod changes",
+		"k8s.in_cluster":             "connecting to Kubernetes with in-cluster config",
+		"k8s.in_cluster_failed":      "failed to create in-cluster config: %v",
+		"k8s.kubeconfig":             "connecting to Kubernetes with kubeconfig file",
+		"k8s.kubeconfig_failed":      "failed to build Kubernetes config: %v",
+		"k8s.clientset_failed":       "failed to create Kubernetes client: %v",
+		"k8s.metrics_client_failed":  "failed to create metrics client: %v",
+		"k8s.dynamic_client_failed":  "failed to create dynamic client: %v",
+		"k8s.limits_failed":          "failed to get container resource limits: %v",
+		"k8s.init_limits":            "initializing: fetching container resource limits",
+		"k8s.limits_attempt":         "trying to get container resource limits",
+		"k8s.get_deployment_failed":  "failed to get deployment: %v",
+		"k8s.get_deployment_retry":   "failed to get deployment, will retry",
+		"k8s.container_not_found":    "deployment [%s] has no container [%s]",
+		"k8s.container_missing":      "container not found",
+		"k8s.invalid_limits":         "invalid resource limit values",
+		"k8s.limits_loaded":          "container resource limits loaded",
+		"k8s.limits_exhausted":       "failed to get container resource limits after %d attempts: %v",
+		"k8s.unknown_basis":          "unknown %s utilization basis: %s",
+		"k8s.invalid_denominator":    "invalid %s denominator: basis=%s, value=%d",
+		"k8s.get_pod_failed":         "failed to get pod: %v",
+		"k8s.pod_unscheduled":        "pod [%s] is not scheduled to a node yet",
+		"k8s.get_node_failed":        "failed to get node [%s]: %v",
+		"k8s.limits_uninitialized":   "container resource limits are not initialized",
+		"k8s.patch_condition_failed": "failed to update pod status condition: %v",
+		"k8s.patch_label_failed":     "failed to update pod label: %v",
+		"k8s.pod_reference_failed":   "failed to build pod reference: %v",
+		"k8s.events_enabled":         "Kubernetes events enabled",
+
+		"probe.handler_missing":  "%s must set httpGet or tcpSocket",
+		"probe.handler_conflict": "%s can only set one of httpGet and tcpSocket",
+		"probe.invalid_scheme":   "%s.httpGet.scheme=%q is invalid, valid values: HTTP, HTTPS",
+		"probe.negative_timeout": "%s.timeoutSeconds must not be negative",
+		"probe.invalid_port":     "%s=%d is not a valid port",
+		"probe.no_handler":       "probe has neither httpGet nor tcpSocket",
+		"probe.request_failed":   "failed to build probe request: %v",
+		"probe.http_failed":      "HTTP probe failed: %v",
+		"probe.http_status":      "HTTP probe returned status code %d",
+		"probe.tcp_failed":       "TCP probe failed: %v",
+
+		"policy.crd_unavailable":               "cannot access SheddingPolicy, make sure the CRD is installed and permissions are granted; SheddingPolicy is disabled",
+		"policy.watch_started":                 "watching SheddingPolicy changes",
+		"policy.unparsable":                    "ignoring SheddingPolicy that cannot be parsed",
+		"policy.some_invalid":                  "some SheddingPolicies are invalid and were ignored",
+		"policy.multiple_matched":              "pod matches several SheddingPolicies, using the oldest one",
+		"policy.remove_failed":                 "failed to remove SheddingPolicy, keeping the last valid configuration",
+		"policy.none_matched":                  "pod matches no SheddingPolicy",
+		"policy.invalid":                       "SheddingPolicy is invalid, keeping the last valid configuration",
+		"policy.applied":                       "SheddingPolicy applied",
+		"policy.status_remove_failed":          "failed to remove pod from SheddingPolicy status",
+		"policy.status_update_failed":          "failed to update SheddingPolicy status",
+		"policy.annotation_percent":            "annotation %s=%q is not a valid percentage",
+		"policy.annotation_quantity":           "annotation %s=%q is not a valid quantity: %v",
+		"policy.annotation_unknown":            "unknown annotation: %s",
+		"policy.annotations_partially_invalid": "some pod annotations are invalid and were ignored",
+		"policy.annotations_invalid":           "pod annotations are invalid, keeping the last valid configuration",
+		"policy.annotations_applied":           "pod annotations applied",
+		"policy.parse_failed":                  "failed to parse SheddingPolicy %s: %v",
+		"policy.invalid_selector":              "SheddingPolicy %s has an invalid selector: %v",
+		"policy.layer_invalid":                 "overlay %s makes the configuration invalid: %v",
+
+		"podstatus.update_failed": "failed to update pod status, will retry",
+		"podstatus.updated":       "pod status updated",
+
+		"endpointslice.sync_failed":   "failed to update EndpointSlice, will retry",
+		"endpointslice.create_failed": "failed to create EndpointSlice [%s]: %v",
+		"endpointslice.created":       "EndpointSlice created",
+		"endpointslice.get_failed":    "failed to get EndpointSlice [%s]: %v",
+		"endpointslice.foreign":       "EndpointSlice [%s] is managed by %q, refusing to overwrite it",
+		"endpointslice.delete_failed": "failed to delete EndpointSlice [%s]: %v",
+		"endpointslice.recreated":     "EndpointSlice recreated",
+		"endpointslice.update_failed": "failed to update EndpointSlice [%s]: %v",
+		"endpointslice.updated":       "EndpointSlice updated",
+
+		"history.invalid_since": "invalid since parameter: %v",
+		"history.invalid_until": "invalid until parameter: %v",
+		"history.invalid_limit": "limit=%q is not a valid non-negative integer",
+		"history.invalid_time":  "%q is neither an RFC3339 time nor a valid duration",
+
+		"loadreport.no_decision":   "no decision yet, no load report available",
+		"loadreport.encode_failed": "failed to encode load report",
+
+		"agentcheck.listen_failed":   "failed to listen on agent-check port: %v",
+		"agentcheck.started":         "agent-check server started",
+		"agentcheck.accept_failed":   "failed to accept agent-check connection",
+		"agentcheck.decision_failed": "agent-check decision failed",
+		"agentcheck.write_failed":    "failed to write agent-check response",
+		"agentcheck.responded":       "agent-check answered",
+
+		"grpchealth.decision_failed": "gRPC health check decision failed",
+		"grpchealth.responded":       "gRPC health check answered",
+		"grpchealth.listen_failed":   "failed to listen on gRPC health port: %v",
+		"grpchealth.register_failed": "failed to register gRPC service: %v",
+		"grpchealth.started":         "gRPC health server started",
+		"grpchealth.serve_failed":    "gRPC health server error: %v",
+
+		"statsd.dial_failed": "failed to connect to StatsD receiver: %v",
+		"statsd.started":     "StatsD emitter started",
+		"statsd.send_failed": "failed to send StatsD metrics",
+
+		"telemetry.trace_exporter_failed":  "failed to create OTLP trace exporter: %v",
+		"telemetry.endpoint_invalid":       "failed to parse OTLP endpoint: %v",
+		"telemetry.endpoint_scheme":        "OTLP endpoint %q must start with http:// or https://",
+		"telemetry.metric_exporter_failed": "failed to create OTLP metric exporter: %v",
+
+		"metric.kube_request_duration":  "Kubernetes API request latency in seconds",
+		"metric.kube_requests":          "Kubernetes API requests; code is the HTTP status code, or <error> on network errors",
+		"metric.kube_request_errors":    "failed Kubernetes API requests (network errors or status code >= 400)",
+		"metric.kube_rate_limiter_wait": "time requests waited in the client-go rate limiter in seconds",
+		"metric.kube_retries":           "Kubernetes API requests retried by client-go",
+		"metric.decisions":              "decisions by resulting status",
+		"metric.random_draws":           "random backoff draws; result is shed (reject traffic) or keep (keep serving)",
+		"metric.shedding_seconds":       "total time spent shedding traffic in seconds",
+		"metric.shedding_episodes":      "times the sidecar started shedding traffic",
+		"metric.state":                  "current status; 1 for the current status, 0 for the others",
+		"metric.state_since":            "Unix timestamp when the current status was entered",
+		"metric.weight":                 "current traffic weight (0-100)",
+		"metric.last_decision":          "Unix timestamp of the latest decision",
+		"metric.cpu_usage":              "CPU usage of the target container in cores",
+		"metric.cpu_limit":              "denominator of the CPU utilization in cores; basis is its source",
+		"metric.cpu_utilization":        "CPU usage divided by the denominator; may exceed 1",
+		"metric.memory_utilization":     "memory usage divided by the denominator",
+		"metric.availability":           "available replicas divided by desired replicas of the deployment",
+		"metric.memory_usage":           "memory usage of the target container",
+		"metric.memory_limit":           "denominator of the memory utilization; basis is its source",
+		"metric.ready":                  "whether the target container is ready; 1 when ready",
+		"metric.replicas":               "desired replicas of the deployment",
+		"metric.available_replicas":     "available replicas of the deployment",
+
+		"logger.sampled":            "repeated log entries were dropped by sampling",
+		"logger.initialized":        "logging initialized",
+		"logger.level_reverted":     "log level change expired, original level restored",
+		"logger.method_not_allowed": "only GET and PUT requests are supported",
+		"logger.invalid_json":       "request body is not valid JSON: %v",
+		"logger.invalid_level":      "level=%q is invalid, valid values: debug, info, warn, error",
+		"logger.unknown_component":  "component=%q does not exist, valid values: %s",
+		"logger.invalid_timeout":    "timeout=%q is not a valid positive duration",
+		"logger.level_changed":      "log level changed",
+
+		"injector.container_not_found":     "pod has no container %q",
+		"injector.probe_unsupported":       "the existing readiness probe of container %s cannot be run by the sidecar (%v); using readiness gates and keeping the original probe",
+		"injector.probe_handler":           "only httpGet and tcpSocket probes are supported",
+		"injector.port_not_found":          "container has no port named %q",
+		"injector.deployment_unknown":      "cannot infer the deployment of the pod, set the %s annotation",
+		"injector.post_only":               "only POST requests are supported",
+		"injector.read_failed":             "failed to read request: %v",
+		"injector.invalid_review_received": "received an invalid AdmissionReview request",
+		"injector.invalid_review":          "invalid AdmissionReview request",
+		"injector.write_failed":            "failed to write AdmissionReview response",
+		"injector.decode_pod_failed":       "failed to decode pod, skipping injection",
+		"injector.skipped":                 "pod has injection disabled or is already injected, skipping",
+		"injector.inject_failed":           "failed to inject sidecar",
+		"injector.inject_warning":          "metrics-sidecar injection failed: %v",
+		"injector.patch_failed":            "failed to encode JSON patch",
+		"injector.injected":                "metrics-sidecar injected",
+		"injector.sidecar_port":            "SIDECAR_PORT=%q is not a valid port",
+		"injector.sidecar_image_required":  "missing required setting: SIDECAR_IMAGE",
+
+		"main.starting":                    "metrics sidecar starting...",
+		"main.in_cluster":                  "using in-cluster config",
+		"main.kubeconfig":                  "using kubeconfig file",
+		"main.config_loaded":               "configuration loaded",
+		"main.index":                       "metrics sidecar is running\n\nendpoints:\n- /healthz: health check\n- /metrics: resource metrics\n- /weight: traffic weight\n- /metrics/prometheus: sidecar metrics",
+		"main.metrics_api_checking":        "checking whether the metrics.k8s.io API is available...",
+		"main.metrics_api_unavailable":     "metrics.k8s.io API is unavailable: %v",
+		"main.metrics_api_available":       "metrics.k8s.io API is available",
+		"main.tracing_failed":              "failed to enable OpenTelemetry tracing",
+		"main.creating_client":             "creating Kubernetes client...",
+		"main.fatal":                       "fatal error",
+		"main.client_created":              "Kubernetes client created",
+		"main.warning":                     "warning",
+		"main.metrics_server_missing":      "metrics-server is not installed or unavailable, cannot continue",
+		"main.creating_collector":          "creating metrics collector...",
+		"main.collector_created":           "metrics collector created",
+		"main.creating_handlers":           "creating HTTP handlers...",
+		"main.handlers_created":            "HTTP handlers created",
+		"main.config_updated":              "effective configuration updated",
+		"main.reload_unavailable":          "failed to watch the config file, reloading is unavailable",
+		"main.events_failed":               "failed to enable Kubernetes events, no events will be recorded",
+		"main.otlp_metrics_failed":         "failed to enable OTLP metric export",
+		"main.otlp_instruments_failed":     "failed to create OTLP metrics",
+		"main.statsd_failed":               "StatsD emitter failed",
+		"main.orca_http_only":              "GRPC_HEALTH_PORT is not set, ORCA load reports are only served as /load response headers",
+		"main.grpchealth_failed":           "gRPC health server failed",
+		"main.agentcheck_failed":           "agent-check server failed",
+		"main.http_starting":               "starting HTTP server",
+		"main.started":                     "metrics sidecar started, waiting for requests...",
+		"main.http_failed":                 "HTTP server error",
+		"main.shutting_down":               "received termination signal, shutting down gracefully...",
+		"main.endpoint_terminating_failed": "failed to mark EndpointSlice endpoint as terminating",
+		"main.shutdown_failed":             "server shutdown error",
+		"main.trace_flush_failed":          "failed to export remaining traces",
+		"main.metrics_flush_failed":        "failed to push the final OTLP metrics",
+		"main.stopped":                     "server stopped",
+		"main.injector_started":            "sidecar injector started",
+		"main.https_failed":                "HTTPS server error",
+		"main.config_failed":               "failed to load configuration: %v",
+	},
+}
@@ -0,0 +1,100 @@
+package injector
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"metrics-sidecar/pkg/config"
+	"metrics-sidecar/pkg/i18n"
+)
+
+// Config 注入器配置
+type Config struct {
+	// Webhook服务配置
+	Port        string // HTTPS服务端口
+	TLSCertFile string // TLS证书路径
+	TLSKeyFile  string // TLS私钥路径
+
+	// 注入的sidecar容器配置
+	SidecarName            string // sidecar容器名称
+	SidecarImage           string // sidecar镜像
+	SidecarImagePullPolicy string // 镜像拉取策略
+	SidecarPort            int32  // sidecar的HTTP端口
+	SidecarCPURequest      string // CPU请求量
+	SidecarCPULimit        string // CPU限制
+	SidecarMemoryRequest   string // 内存请求量
+	SidecarMemoryLimit     string // 内存限制
+
+	// 日志配置，与sidecar使用相同的默认值和LOG_*、MESSAGE_LANG环境变量
+	Log *config.Config
+}
+
+// 获取环境变量，如果不存在则返回默认值
+func getEnvWithDefault(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// LoadConfig 从环境变量加载注入器配置并校验
+func LoadConfig() (*Config, error) {
+	cfg := &Config{
+		Port:                   getEnvWithDefault("INJECTOR_PORT", "8443"),
+		TLSCertFile:            getEnvWithDefault("TLS_CERT_FILE", "/etc/webhook/certs/tls.crt"),
+		TLSKeyFile:             getEnvWithDefault("TLS_KEY_FILE", "/etc/webhook/certs/tls.key"),
+		SidecarName:            getEnvWithDefault("SIDECAR_NAME", "metrics-sidecar"),
+		SidecarImage:           getEnvWithDefault("SIDECAR_IMAGE", "metrics-sidecar:latest"),
+		SidecarImagePullPolicy: getEnvWithDefault("SIDECAR_IMAGE_PULL_POLICY", "IfNotPresent"),
+		SidecarCPURequest:      getEnvWithDefault("SIDECAR_CPU_REQUEST", "100m"),
+		SidecarCPULimit:        getEnvWithDefault("SIDECAR_CPU_LIMIT", "200m"),
+		SidecarMemoryRequest:   getEnvWithDefault("SIDECAR_MEMORY_REQUEST", "128Mi"),
+		SidecarMemoryLimit:     getEnvWithDefault("SIDECAR_MEMORY_LIMIT", "256Mi"),
+	}
+
+	var problems []string
+
+	log, err := config.LoadLogConfig()
+	var invalid *config.ValidationError
+	if errors.As(err, &invalid) {
+		problems = append(problems, invalid.Problems...)
+	}
+	cfg.Log = log
+
+	port := getEnvWithDefault("SIDECAR_PORT", "8333")
+	if parsed, err := strconv.ParseInt(port, 10, 32); err != nil || parsed <= 0 || parsed > 65535 {
+		problems = append(problems, i18n.T("injector.sidecar_port", port))
+	} else {
+		cfg.SidecarPort = int32(parsed)
+	}
+
+	if cfg.SidecarImage == "" {
+		problems = append(problems, i18n.T("injector.sidecar_image_required"))
+	}
+
+	quantities := []struct {
+		name  string
+		value string
+	}{
+		{"SIDECAR_CPU_REQUEST", cfg.SidecarCPURequest},
+		{"SIDECAR_CPU_LIMIT", cfg.SidecarCPULimit},
+		{"SIDECAR_MEMORY_REQUEST", cfg.SidecarMemoryRequest},
+		{"SIDECAR_MEMORY_LIMIT", cfg.SidecarMemoryLimit},
+	}
+	for _, q := range quantities {
+		if q.value == "" {
+			continue
+		}
+		if _, err := resource.ParseQuantity(q.value); err != nil {
+			problems = append(problems, i18n.T("common.invalid_quantity", q.name, q.value, err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &config.ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,275 @@
+package injector
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/podstatus"
+	"metrics-sidecar/pkg/policy"
+	"metrics-sidecar/pkg/probe"
+)
+
+// 写入AnnotationInjectStatus的值
+const injectedStatus = "injected"
+
+// 主容器原本没有就绪探针时使用的默认参数
+const (
+	defaultProbeInitialDelaySeconds = 10
+	defaultProbePeriodSeconds       = 15
+	defaultProbeTimeoutSeconds      = 5
+	defaultProbeFailureThreshold    = 2
+)
+
+// PatchOperation RFC 6902 JSON Patch中的一项操作
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Injector 为带有注入注解的Pod生成注入sidecar的补丁
+type Injector struct {
+	cfg *Config
+}
+
+// NewInjector 创建注入器
+func NewInjector(cfg *Config) *Injector {
+	return &Injector{cfg: cfg}
+}
+
+// ShouldInject 判断Pod是否需要注入：带有注入注解且尚未注入
+func (i *Injector) ShouldInject(pod *corev1.Pod) bool {
+	enabled, _ := strconv.ParseBool(pod.Annotations[policy.AnnotationInject])
+	if !enabled || pod.Annotations[policy.AnnotationInjectStatus] == injectedStatus {
+		return false
+	}
+	return findContainer(pod, i.cfg.SidecarName) < 0
+}
+
+// Patch 生成注入sidecar的JSON Patch：追加sidecar容器，
+// 并将被监控容器的就绪探针改为指向sidecar的/healthz，原有探针交给sidecar代为执行；
+// readinessGates模式下改为添加就绪门控，主容器的就绪探针保持不变。
+// warnings为不影响注入但需要告知用户的问题
+func (i *Injector) Patch(pod *corev1.Pod) (patch []PatchOperation, warnings []string, err error) {
+	containerName := pod.Annotations[policy.AnnotationInjectContainer]
+	if containerName == "" && len(pod.Spec.Containers) > 0 {
+		containerName = pod.Spec.Containers[0].Name
+	}
+	index := findContainer(pod, containerName)
+	if index < 0 {
+		return nil, nil, i18n.Errorf("injector.container_not_found", containerName)
+	}
+
+	deploymentName, err := deploymentOf(pod)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sidecar := i.sidecarContainer(deploymentName, containerName)
+
+	// sidecar无法代为执行原有的就绪探针时改用readinessGates，避免丢失应用自身的就绪检查
+	gate, _ := strconv.ParseBool(pod.Annotations[policy.AnnotationInjectReadinessGate])
+	var appProbe *probe.Spec
+	if !gate {
+		if appProbe, err = appProbeSpec(&pod.Spec.Containers[index]); err != nil {
+			warnings = append(warnings, i18n.T("injector.probe_unsupported", containerName, err))
+			gate = true
+		}
+	}
+
+	// readinessGates模式下主容器的就绪探针保持不变，sidecar通过Pod状态条件控制就绪
+	if gate {
+		sidecar.Env = append(sidecar.Env, corev1.EnvVar{Name: "POD_CONDITION_ENABLED", Value: "true"})
+		readinessGate := corev1.PodReadinessGate{ConditionType: podstatus.ConditionNotOverloaded}
+		patch = append(patch, PatchOperation{Op: "add", Path: "/spec/containers/-", Value: sidecar})
+		if pod.Spec.ReadinessGates == nil {
+			patch = append(patch, PatchOperation{Op: "add", Path: "/spec/readinessGates",
+				Value: []corev1.PodReadinessGate{readinessGate}})
+		} else {
+			patch = append(patch, PatchOperation{Op: "add", Path: "/spec/readinessGates/-", Value: readinessGate})
+		}
+	} else {
+		if appProbe != nil {
+			data, _ := json.Marshal(appProbe)
+			sidecar.Env = append(sidecar.Env, corev1.EnvVar{Name: "APP_READINESS_PROBE", Value: string(data)})
+		}
+
+		patch = append(patch,
+			PatchOperation{Op: "add", Path: "/spec/containers/-", Value: sidecar},
+			// JSON Patch的add作用于已存在的字段时会替换原值
+			PatchOperation{Op: "add", Path: fmt.Sprintf("/spec/containers/%d/readinessProbe", index),
+				Value: i.readinessProbe(pod.Spec.Containers[index].ReadinessProbe)},
+		)
+	}
+
+	if pod.Annotations == nil {
+		patch = append(patch, PatchOperation{Op: "add", Path: "/metadata/annotations",
+			Value: map[string]string{policy.AnnotationInjectStatus: injectedStatus}})
+	} else {
+		patch = append(patch, PatchOperation{Op: "add", Path: "/metadata/annotations/" + escapeJSONPointer(policy.AnnotationInjectStatus),
+			Value: injectedStatus})
+	}
+
+	return patch, warnings, nil
+}
+
+// sidecarContainer 构造sidecar容器，命名空间和Pod名称通过Downward API获取
+func (i *Injector) sidecarContainer(deploymentName, containerName string) corev1.Container {
+	port := i.cfg.SidecarPort
+	return corev1.Container{
+		Name:            i.cfg.SidecarName,
+		Image:           i.cfg.SidecarImage,
+		ImagePullPolicy: corev1.PullPolicy(i.cfg.SidecarImagePullPolicy),
+		Ports: []corev1.ContainerPort{
+			{Name: "sidecar-http", ContainerPort: port, Protocol: corev1.ProtocolTCP},
+		},
+		Env: []corev1.EnvVar{
+			{Name: "NAMESPACE", ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"}}},
+			{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
+			{Name: "DEPLOYMENT_NAME", Value: deploymentName},
+			{Name: "CONTAINER_NAME", Value: containerName},
+			{Name: "HTTP_PORT", Value: strconv.Itoa(int(port))},
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: resourceList(i.cfg.SidecarCPURequest, i.cfg.SidecarMemoryRequest),
+			Limits:   resourceList(i.cfg.SidecarCPULimit, i.cfg.SidecarMemoryLimit),
+		},
+		// 存活探针只检查端口，/healthz在过载时返回503，不能用于存活判断
+		LivenessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(port)},
+			},
+			InitialDelaySeconds: 10,
+			PeriodSeconds:       30,
+			TimeoutSeconds:      5,
+			FailureThreshold:    5,
+		},
+	}
+}
+
+// readinessProbe 构造指向sidecar的就绪探针，沿用原探针的时间参数。
+// sidecar需要调用API Server和执行原有探针，超时时间不低于默认值
+func (i *Injector) readinessProbe(original *corev1.Probe) *corev1.Probe {
+	rewritten := &corev1.Probe{
+		InitialDelaySeconds: defaultProbeInitialDelaySeconds,
+		PeriodSeconds:       defaultProbePeriodSeconds,
+		TimeoutSeconds:      defaultProbeTimeoutSeconds,
+		FailureThreshold:    defaultProbeFailureThreshold,
+		SuccessThreshold:    1,
+	}
+	if original != nil {
+		rewritten.InitialDelaySeconds = original.InitialDelaySeconds
+		rewritten.PeriodSeconds = original.PeriodSeconds
+		rewritten.FailureThreshold = original.FailureThreshold
+		rewritten.SuccessThreshold = original.SuccessThreshold
+		if original.TimeoutSeconds > rewritten.TimeoutSeconds {
+			rewritten.TimeoutSeconds = original.TimeoutSeconds
+		}
+	}
+	rewritten.HTTPGet = &corev1.HTTPGetAction{
+		Path: "/healthz",
+		Port: intstr.FromInt32(i.cfg.SidecarPort),
+	}
+	return rewritten
+}
+
+// appProbeSpec 将容器原有的就绪探针转换为sidecar可执行的定义，容器没有就绪探针时返回nil。
+// 命名端口会根据容器的端口定义解析为数字
+func appProbeSpec(container *corev1.Container) (*probe.Spec, error) {
+	original := container.ReadinessProbe
+	if original == nil {
+		return nil, nil
+	}
+
+	spec := &probe.Spec{TimeoutSeconds: int(original.TimeoutSeconds)}
+	switch {
+	case original.HTTPGet != nil:
+		port, err := resolvePort(container, original.HTTPGet.Port)
+		if err != nil {
+			return nil, err
+		}
+		spec.HTTPGet = &probe.HTTPGetAction{
+			Host:   original.HTTPGet.Host,
+			Path:   original.HTTPGet.Path,
+			Port:   port,
+			Scheme: string(original.HTTPGet.Scheme),
+		}
+		for _, header := range original.HTTPGet.HTTPHeaders {
+			spec.HTTPGet.HTTPHeaders = append(spec.HTTPGet.HTTPHeaders, probe.HTTPHeader{Name: header.Name, Value: header.Value})
+		}
+	case original.TCPSocket != nil:
+		port, err := resolvePort(container, original.TCPSocket.Port)
+		if err != nil {
+			return nil, err
+		}
+		spec.TCPSocket = &probe.TCPSocketAction{Host: original.TCPSocket.Host, Port: port}
+	default:
+		return nil, i18n.Errorf("injector.probe_handler")
+	}
+	return spec, nil
+}
+
+// resolvePort 将探针端口解析为数字，命名端口从容器的端口定义中查找
+func resolvePort(container *corev1.Container, port intstr.IntOrString) (int, error) {
+	if port.Type == intstr.Int {
+		return port.IntValue(), nil
+	}
+	for _, p := range container.Ports {
+		if p.Name == port.StrVal {
+			return int(p.ContainerPort), nil
+		}
+	}
+	return 0, i18n.Errorf("injector.port_not_found", port.StrVal)
+}
+
+// deploymentOf 确定Pod所属的Deployment名称：优先使用注解，
+// 否则根据所属ReplicaSet的名称去掉pod-template-hash后缀推断
+func deploymentOf(pod *corev1.Pod) (string, error) {
+	if name := pod.Annotations[policy.AnnotationInjectDeployment]; name != "" {
+		return name, nil
+	}
+
+	hash := pod.Labels["pod-template-hash"]
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "ReplicaSet" && hash != "" && strings.HasSuffix(owner.Name, "-"+hash) {
+			return strings.TrimSuffix(owner.Name, "-"+hash), nil
+		}
+	}
+	return "", i18n.Errorf("injector.deployment_unknown", policy.AnnotationInjectDeployment)
+}
+
+// findContainer 返回指定名称容器的下标，不存在时返回-1
+func findContainer(pod *corev1.Pod, name string) int {
+	for i, container := range pod.Spec.Containers {
+		if container.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// resourceList 构造资源列表，忽略为空的值
+func resourceList(cpu, memory string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	if cpu != "" {
+		list[corev1.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if memory != "" {
+		list[corev1.ResourceMemory] = resource.MustParse(memory)
+	}
+	return list
+}
+
+// escapeJSONPointer 按RFC 6901转义JSON Pointer中的特殊字符
+func escapeJSONPointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
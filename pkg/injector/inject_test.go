@@ -0,0 +1,274 @@
+package injector
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"metrics-sidecar/pkg/config"
+	"metrics-sidecar/pkg/podstatus"
+	"metrics-sidecar/pkg/policy"
+	"metrics-sidecar/pkg/probe"
+)
+
+// 测试用的注入器配置
+func testConfig() *Config {
+	return &Config{
+		SidecarName:            "metrics-sidecar",
+		SidecarImage:           "metrics-sidecar:test",
+		SidecarImagePullPolicy: "IfNotPresent",
+		SidecarPort:            8333,
+		SidecarCPURequest:      "100m",
+		SidecarMemoryRequest:   "128Mi",
+	}
+}
+
+// 由Deployment创建的Pod
+func testPod(annotations map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "example-app-6c7687ddb-",
+			Annotations:  annotations,
+			Labels:       map[string]string{"app": "example-app", "pod-template-hash": "6c7687ddb"},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ReplicaSet", Name: "example-app-6c7687ddb"},
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "init-proxy"},
+				{
+					Name:  "main-app",
+					Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+					ReadinessProbe: &corev1.Probe{
+						ProbeHandler: corev1.ProbeHandler{
+							HTTPGet: &corev1.HTTPGetAction{Path: "/ready", Port: intstr.FromString("http")},
+						},
+						PeriodSeconds:    7,
+						FailureThreshold: 4,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestShouldInject(t *testing.T) {
+	injector := NewInjector(testConfig())
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    bool
+	}{
+		{"未设置注解", nil, false},
+		{"注解为false", map[string]string{policy.AnnotationInject: "false"}, false},
+		{"注解为true", map[string]string{policy.AnnotationInject: "true"}, true},
+		{"已注入", map[string]string{policy.AnnotationInject: "true", policy.AnnotationInjectStatus: injectedStatus}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := injector.ShouldInject(testPod(tt.annotations)); got != tt.expected {
+				t.Errorf("ShouldInject() = %v; 期望 %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPatch(t *testing.T) {
+	injector := NewInjector(testConfig())
+	pod := testPod(map[string]string{
+		policy.AnnotationInject:          "true",
+		policy.AnnotationInjectContainer: "main-app",
+	})
+
+	patch, warnings, err := injector.Patch(pod)
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("Patch失败: %v, 警告: %v", err, warnings)
+	}
+	if len(patch) != 3 {
+		t.Fatalf("patch包含%d项操作; 期望 3", len(patch))
+	}
+
+	sidecar, ok := patch[0].Value.(corev1.Container)
+	if !ok || patch[0].Path != "/spec/containers/-" {
+		t.Fatalf("第一项操作 = %+v; 期望追加sidecar容器", patch[0])
+	}
+	env := make(map[string]corev1.EnvVar)
+	for _, e := range sidecar.Env {
+		env[e.Name] = e
+	}
+	if env["DEPLOYMENT_NAME"].Value != "example-app" {
+		t.Errorf("DEPLOYMENT_NAME = %q; 期望 example-app", env["DEPLOYMENT_NAME"].Value)
+	}
+	if env["CONTAINER_NAME"].Value != "main-app" {
+		t.Errorf("CONTAINER_NAME = %q; 期望 main-app", env["CONTAINER_NAME"].Value)
+	}
+	if ref := env["POD_NAME"].ValueFrom; ref == nil || ref.FieldRef.FieldPath != "metadata.name" {
+		t.Errorf("POD_NAME应通过fieldRef获取metadata.name")
+	}
+
+	// 原有探针的命名端口被解析为数字后交给sidecar执行
+	var appProbe probe.Spec
+	if err := json.Unmarshal([]byte(env["APP_READINESS_PROBE"].Value), &appProbe); err != nil {
+		t.Fatalf("解析APP_READINESS_PROBE失败: %v", err)
+	}
+	if appProbe.HTTPGet == nil || appProbe.HTTPGet.Path != "/ready" || appProbe.HTTPGet.Port != 8080 {
+		t.Errorf("APP_READINESS_PROBE = %s; 期望 /ready:8080", env["APP_READINESS_PROBE"].Value)
+	}
+
+	rewritten, ok := patch[1].Value.(*corev1.Probe)
+	if !ok || patch[1].Path != "/spec/containers/1/readinessProbe" {
+		t.Fatalf("第二项操作 = %+v; 期望改写main-app的就绪探针", patch[1])
+	}
+	if rewritten.HTTPGet == nil || rewritten.HTTPGet.Path != "/healthz" || rewritten.HTTPGet.Port.IntValue() != 8333 {
+		t.Errorf("就绪探针 = %+v; 期望指向sidecar的/healthz", rewritten.HTTPGet)
+	}
+	if rewritten.PeriodSeconds != 7 || rewritten.FailureThreshold != 4 {
+		t.Errorf("就绪探针未沿用原有的时间参数: %+v", rewritten)
+	}
+
+	if patch[2].Path != "/metadata/annotations/metrics-sidecar.io~1injected" {
+		t.Errorf("第三项操作路径 = %s; 期望转义后的注入状态注解", patch[2].Path)
+	}
+
+	// 无法推断Deployment时返回错误
+	pod.OwnerReferences = nil
+	if _, _, err := injector.Patch(pod); err == nil {
+		t.Error("缺少Deployment信息时Patch应返回错误")
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	handler := NewWebhookHandler(NewInjector(testConfig()))
+
+	raw, _ := json.Marshal(testPod(map[string]string{policy.AnnotationInject: "true"}))
+	review := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:    "test-uid",
+			Kind:   metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			Object: runtime.RawExtension{Raw: raw},
+		},
+	}
+	body, _ := json.Marshal(review)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/mutate", bytes.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d; 期望 200", rr.Code)
+	}
+
+	var result admissionv1.AdmissionReview
+	if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if result.Response == nil || result.Response.UID != "test-uid" || !result.Response.Allowed {
+		t.Fatalf("Response = %+v; 期望放行并带有相同的UID", result.Response)
+	}
+	if result.Response.PatchType == nil || len(result.Response.Patch) == 0 {
+		t.Error("响应中缺少JSON Patch")
+	}
+}
+
+func TestPatchReadinessGate(t *testing.T) {
+	injector := NewInjector(testConfig())
+	pod := testPod(map[string]string{
+		policy.AnnotationInject:              "true",
+		policy.AnnotationInjectContainer:     "main-app",
+		policy.AnnotationInjectReadinessGate: "true",
+	})
+
+	patch, _, err := injector.Patch(pod)
+	if err != nil {
+		t.Fatalf("Patch失败: %v", err)
+	}
+
+	for _, op := range patch {
+		if op.Path == "/spec/containers/1/readinessProbe" {
+			t.Error("readinessGates模式下不应改写主容器的就绪探针")
+		}
+	}
+
+	gates, ok := patch[1].Value.([]corev1.PodReadinessGate)
+	if !ok || patch[1].Path != "/spec/readinessGates" || gates[0].ConditionType != podstatus.ConditionNotOverloaded {
+		t.Fatalf("第二项操作 = %+v; 期望添加就绪门控", patch[1])
+	}
+
+	sidecar := patch[0].Value.(corev1.Container)
+	enabled := false
+	for _, e := range sidecar.Env {
+		if e.Name == "POD_CONDITION_ENABLED" && e.Value == "true" {
+			enabled = true
+		}
+	}
+	if !enabled {
+		t.Error("readinessGates模式下sidecar应启用POD_CONDITION_ENABLED")
+	}
+}
+
+func TestPatchUnsupportedProbe(t *testing.T) {
+	injector := NewInjector(testConfig())
+	pod := testPod(map[string]string{
+		policy.AnnotationInject:          "true",
+		policy.AnnotationInjectContainer: "main-app",
+	})
+	original := &corev1.Probe{ProbeHandler: corev1.ProbeHandler{
+		Exec: &corev1.ExecAction{Command: []string{"cat", "/tmp/ready"}}}}
+	pod.Spec.Containers[1].ReadinessProbe = original
+
+	patch, warnings, err := injector.Patch(pod)
+	if err != nil {
+		t.Fatalf("Patch失败: %v", err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("警告 = %v; 期望1条说明原探针无法执行", warnings)
+	}
+	for _, op := range patch {
+		if op.Path == "/spec/containers/1/readinessProbe" {
+			t.Error("sidecar无法执行原探针时不应改写主容器的就绪探针")
+		}
+	}
+	if patch[1].Path != "/spec/readinessGates" {
+		t.Errorf("第二项操作路径 = %s; 期望改用就绪门控", patch[1].Path)
+	}
+	sidecar := patch[0].Value.(corev1.Container)
+	for _, e := range sidecar.Env {
+		if e.Name == "APP_READINESS_PROBE" {
+			t.Error("sidecar无法执行原探针时不应设置APP_READINESS_PROBE")
+		}
+	}
+}
+
+func TestLoadConfigLogSettings(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("LOG_FORMAT", "json")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig()返回错误: %v", err)
+	}
+	if cfg.Log.LogLevel != "warn" || cfg.Log.LogFormat != config.LogFormatJSON {
+		t.Errorf("日志配置 = {%s %s}; 期望 {warn %s}", cfg.Log.LogLevel, cfg.Log.LogFormat, config.LogFormatJSON)
+	}
+	if !cfg.Log.LogCaller {
+		t.Error("未设置LOG_CALLER时应与sidecar一样默认输出调用位置")
+	}
+
+	// 日志配置的问题与注入器自身的问题一起报告
+	t.Setenv("LOG_FORMAT", "xml")
+	t.Setenv("SIDECAR_PORT", "0")
+	_, err = LoadConfig()
+	invalid, ok := err.(*config.ValidationError)
+	if !ok || len(invalid.Problems) != 2 {
+		t.Errorf("无效的LOG_FORMAT和SIDECAR_PORT时LoadConfig()错误 = %v; 期望包含2个问题", err)
+	}
+}
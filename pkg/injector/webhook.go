@@ -0,0 +1,114 @@
+package injector
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/logger"
+)
+
+var (
+	// 注入器的日志器
+	injectorLog = logger.GetLogger("injector")
+)
+
+// 请求体的最大长度
+const maxRequestBytes = 4 << 20
+
+// WebhookHandler 处理Mutating Admission Webhook请求
+type WebhookHandler struct {
+	injector *Injector
+}
+
+// NewWebhookHandler 创建Webhook处理器
+func NewWebhookHandler(injector *Injector) *WebhookHandler {
+	return &WebhookHandler{injector: injector}
+}
+
+// ServeHTTP 解析AdmissionReview请求并返回带有JSON Patch的响应
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, i18n.T("injector.post_only"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBytes))
+	if err != nil {
+		http.Error(w, i18n.T("injector.read_failed", err), http.StatusBadRequest)
+		return
+	}
+
+	var review admissionv1.AdmissionReview
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		injectorLog.WithError(err).Warn(i18n.T("injector.invalid_review_received"))
+		http.Error(w, i18n.T("injector.invalid_review"), http.StatusBadRequest)
+		return
+	}
+
+	review.Response = h.review(review.Request)
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		injectorLog.WithError(err).Error(i18n.T("injector.write_failed"))
+	}
+}
+
+// review 处理单个准入请求。注入失败时仍然放行Pod，避免阻塞工作负载的发布，
+// 失败原因通过警告返回给客户端
+func (h *WebhookHandler) review(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	response := &admissionv1.AdmissionResponse{UID: req.UID, Allowed: true}
+	if req.Kind.Kind != "Pod" {
+		return response
+	}
+
+	var pod corev1.Pod
+	if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+		injectorLog.WithError(err).Warn(i18n.T("injector.decode_pod_failed"))
+		return response
+	}
+
+	name := pod.Name
+	if name == "" {
+		name = pod.GenerateName
+	}
+	log := injectorLog.WithFields(logrus.Fields{
+		"namespace": req.Namespace,
+		"pod":       name,
+	})
+
+	if !h.injector.ShouldInject(&pod) {
+		log.Debug(i18n.T("injector.skipped"))
+		return response
+	}
+
+	patch, warnings, err := h.injector.Patch(&pod)
+	response.Warnings = warnings
+	if err != nil {
+		log.WithError(err).Warn(i18n.T("injector.inject_failed"))
+		response.Warnings = append(response.Warnings, i18n.T("injector.inject_warning", err))
+		return response
+	}
+	for _, warning := range warnings {
+		log.Warn(warning)
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		log.WithError(err).Error(i18n.T("injector.patch_failed"))
+		response.Warnings = append(response.Warnings, i18n.T("injector.inject_warning", err))
+		return response
+	}
+
+	patchType := admissionv1.PatchTypeJSONPatch
+	response.Patch = data
+	response.PatchType = &patchType
+	log.Info(i18n.T("injector.injected"))
+	return response
+}
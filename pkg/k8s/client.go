@@ -2,18 +2,22 @@ package k8s
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/transport"
 	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
 
 	"metrics-sidecar/pkg/config"
+	"metrics-sidecar/pkg/i18n"
 	"metrics-sidecar/pkg/logger"
 	"metrics-sidecar/pkg/metrics"
 )
@@ -27,35 +31,45 @@ var (
 type Client struct {
 	KubeClient      *kubernetes.Clientset
 	MetricsClient   *metricsclient.Clientset
+	DynamicClient   dynamic.Interface // 用于访问SheddingPolicy等自定义资源
 	Config          *config.Config
 	ContainerLimits *metrics.ContainerLimits // 存储容器资源限制
+
+	// Kubernetes事件，由StartEventRecorder初始化
+	eventBroadcaster record.EventBroadcaster
+	eventRecorder    record.EventRecorder
+	podRef           *corev1.ObjectReference
 }
 
-// NewClient 创建并返回一个新的Client
-func NewClient(cfg *config.Config) (*Client, error) {
+// NewClient 创建并返回一个新的Client，wrap不为nil时用于包装所有客户端的RoundTripper
+func NewClient(cfg *config.Config, wrap transport.WrapperFunc) (*Client, error) {
 	var kubeConfig *rest.Config
 	var err error
 
 	if cfg.InClusterConfig {
 		// 使用集群内配置
-		k8sLog.Info("使用InCluster配置连接Kubernetes集群")
+		k8sLog.Info(i18n.T("k8s.in_cluster"))
 		kubeConfig, err = rest.InClusterConfig()
 		if err != nil {
-			return nil, fmt.Errorf("创建InCluster配置失败: %v", err)
+			return nil, i18n.Errorf("k8s.in_cluster_failed", err)
 		}
 	} else {
 		// 使用外部配置文件
-		k8sLog.WithField("kubeconfig", cfg.KubeconfigPath).Info("使用kubeconfig文件连接Kubernetes集群")
+		k8sLog.WithField("kubeconfig", cfg.KubeconfigPath).Info(i18n.T("k8s.kubeconfig"))
 		kubeConfig, err = clientcmd.BuildConfigFromFlags("", cfg.KubeconfigPath)
 		if err != nil {
-			return nil, fmt.Errorf("构建K8s配置失败: %v", err)
+			return nil, i18n.Errorf("k8s.kubeconfig_failed", err)
 		}
 	}
 
+	if wrap != nil {
+		kubeConfig.Wrap(wrap)
+	}
+
 	// 创建Kubernetes客户端
 	clientSet, err := kubernetes.NewForConfig(kubeConfig)
 	if err != nil {
-		return nil, fmt.Errorf("创建Kubernetes客户端失败: %v", err)
+		return nil, i18n.Errorf("k8s.clientset_failed", err)
 	}
 
 	// 创建Metrics客户端
@@ -67,28 +81,35 @@ func NewClient(cfg *config.Config) (*Client, error) {
 
 	metricsClient, err := metricsclient.NewForConfig(&metricsConfig)
 	if err != nil {
-		return nil, fmt.Errorf("创建Metrics客户端失败: %v", err)
+		return nil, i18n.Errorf("k8s.metrics_client_failed", err)
+	}
+
+	// 创建Dynamic客户端
+	dynamicClient, err := dynamic.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, i18n.Errorf("k8s.dynamic_client_failed", err)
 	}
 
 	client := &Client{
 		KubeClient:    clientSet,
 		MetricsClient: metricsClient,
+		DynamicClient: dynamicClient,
 		Config:        cfg,
 	}
 
 	// 初始化时立即获取容器资源限制
 	containerLimits, err := client.initContainerLimits()
 	if err != nil {
-		return nil, fmt.Errorf("获取容器资源限制失败: %v", err)
+		return nil, i18n.Errorf("k8s.limits_failed", err)
 	}
 	client.ContainerLimits = containerLimits
 
 	return client, nil
 }
 
-// initContainerLimits 初始化时获取容器资源限制
+// initContainerLimits 初始化时按配置的基准获取容器资源分母
 func (c *Client) initContainerLimits() (*metrics.ContainerLimits, error) {
-	k8sLog.WithField("container", c.Config.ContainerName).Info("初始化: 获取容器资源限制")
+	k8sLog.WithField("container", c.Config.ContainerName).Info(i18n.T("k8s.init_limits"))
 
 	// 设置超时上下文
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -101,60 +122,149 @@ func (c *Client) initContainerLimits() (*metrics.ContainerLimits, error) {
 	var lastErr error
 	for i := 0; i < maxRetries; i++ {
 		if i > 0 {
-			k8sLog.WithField("retry", i+1).Info("尝试获取容器资源限制")
+			k8sLog.WithField("retry", i+1).Info(i18n.T("k8s.limits_attempt"))
 			time.Sleep(retryInterval)
 		}
 
 		deploy, err := c.KubeClient.AppsV1().Deployments(c.Config.Namespace).Get(ctx, c.Config.DeploymentName, metav1.GetOptions{})
 		if err != nil {
-			lastErr = fmt.Errorf("获取Deployment失败: %v", err)
-			k8sLog.WithError(err).Warn("获取Deployment信息失败，将重试")
+			lastErr = i18n.Errorf("k8s.get_deployment_failed", err)
+			k8sLog.WithError(err).Warn(i18n.T("k8s.get_deployment_retry"))
 			continue
 		}
 
-		// 查找容器并获取资源限制
-		for _, container := range deploy.Spec.Template.Spec.Containers {
-			if container.Name == c.Config.ContainerName {
-				cpuLimit := container.Resources.Limits.Cpu().MilliValue()
-				memLimit := container.Resources.Limits.Memory().Value() / (1024 * 1024)
-
-				// 验证资源限制是否有效
-				if cpuLimit <= 0 || memLimit <= 0 {
-					lastErr = fmt.Errorf("无效的资源限制值: CPU=%dm, Memory=%dMi", cpuLimit, memLimit)
-					k8sLog.WithFields(logrus.Fields{
-						"cpu":    cpuLimit,
-						"memory": memLimit,
-					}).Warn("警告: 资源限制值无效")
-					continue
-				}
-
-				k8sLog.WithFields(logrus.Fields{
-					"cpu":    cpuLimit,
-					"memory": memLimit,
-				}).Info("成功获取容器资源限制")
-				return &metrics.ContainerLimits{
-					CPULimit: cpuLimit,
-					MemLimit: memLimit,
-				}, nil
-			}
+		// 查找容器并按配置的基准计算资源分母
+		container := findContainer(deploy.Spec.Template.Spec.Containers, c.Config.ContainerName)
+		if container == nil {
+			lastErr = i18n.Errorf("k8s.container_not_found", c.Config.DeploymentName, c.Config.ContainerName)
+			k8sLog.WithFields(logrus.Fields{
+				"deployment": c.Config.DeploymentName,
+				"container":  c.Config.ContainerName,
+			}).Warn(i18n.T("k8s.container_missing"))
+			continue
+		}
+
+		limits, err := c.resolveContainerLimits(ctx, container)
+		if err != nil {
+			lastErr = err
+			k8sLog.WithError(err).Warn(i18n.T("k8s.invalid_limits"))
+			continue
 		}
 
-		lastErr = fmt.Errorf("在Deployment[%s]中未找到容器[%s]", c.Config.DeploymentName, c.Config.ContainerName)
 		k8sLog.WithFields(logrus.Fields{
-			"deployment": c.Config.DeploymentName,
-			"container":  c.Config.ContainerName,
-		}).Warn("警告: 未找到指定容器")
+			"cpu":          limits.CPULimit,
+			"cpu_basis":    limits.CPUBasis,
+			"memory":       limits.MemLimit,
+			"memory_basis": limits.MemBasis,
+		}).Info(i18n.T("k8s.limits_loaded"))
+		return limits, nil
 	}
 
 	// 所有重试都失败了
-	return nil, fmt.Errorf("经过%d次尝试，无法获取容器资源限制: %v", maxRetries, lastErr)
+	return nil, i18n.Errorf("k8s.limits_exhausted", maxRetries, lastErr)
+}
+
+// findContainer 按名称查找容器
+func findContainer(containers []corev1.Container, name string) *corev1.Container {
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i]
+		}
+	}
+	return nil
+}
+
+// resolveContainerLimits 按配置的基准计算CPU和内存使用率的分母
+func (c *Client) resolveContainerLimits(ctx context.Context, container *corev1.Container) (*metrics.ContainerLimits, error) {
+	// 节点可分配资源只在需要时获取一次
+	var allocatable corev1.ResourceList
+	getAllocatable := func() (corev1.ResourceList, error) {
+		if allocatable != nil {
+			return allocatable, nil
+		}
+		var err error
+		allocatable, err = c.getNodeAllocatable(ctx)
+		return allocatable, err
+	}
+
+	cpuLimit, err := resolveBasis(c.Config.CPUBasis, corev1.ResourceCPU, container, c.Config.CPUBasisValue, getAllocatable)
+	if err != nil {
+		return nil, err
+	}
+	memLimit, err := resolveBasis(c.Config.MemoryBasis, corev1.ResourceMemory, container, c.Config.MemoryBasisValue, getAllocatable)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metrics.ContainerLimits{
+		CPULimit: cpuLimit,
+		MemLimit: memLimit,
+		CPUBasis: c.Config.CPUBasis,
+		MemBasis: c.Config.MemoryBasis,
+	}, nil
+}
+
+// resolveBasis 计算单个资源的分母，CPU返回毫核，内存返回MB
+func resolveBasis(basis string, name corev1.ResourceName, container *corev1.Container, absolute int64,
+	getAllocatable func() (corev1.ResourceList, error)) (int64, error) {
+	var value int64
+	switch basis {
+	case config.BasisLimit:
+		value = quantityValue(name, container.Resources.Limits)
+	case config.BasisRequest:
+		value = quantityValue(name, container.Resources.Requests)
+	case config.BasisNodeAllocatable:
+		allocatable, err := getAllocatable()
+		if err != nil {
+			return 0, err
+		}
+		value = quantityValue(name, allocatable)
+	case config.BasisAbsolute:
+		value = absolute
+	default:
+		return 0, i18n.Errorf("k8s.unknown_basis", name, basis)
+	}
+
+	if value <= 0 {
+		return 0, i18n.Errorf("k8s.invalid_denominator", name, basis, value)
+	}
+	return value, nil
+}
+
+// quantityValue 从资源列表中取出指定资源，CPU返回毫核，内存返回MB
+func quantityValue(name corev1.ResourceName, list corev1.ResourceList) int64 {
+	quantity, ok := list[name]
+	if !ok {
+		return 0
+	}
+	if name == corev1.ResourceCPU {
+		return quantity.MilliValue()
+	}
+	return quantity.Value() / (1024 * 1024)
+}
+
+// getNodeAllocatable 获取当前Pod所在节点的可分配资源
+func (c *Client) getNodeAllocatable(ctx context.Context) (corev1.ResourceList, error) {
+	pod, err := c.KubeClient.CoreV1().Pods(c.Config.Namespace).Get(ctx, c.Config.PodName, metav1.GetOptions{})
+	if err != nil {
+		return nil, i18n.Errorf("k8s.get_pod_failed", err)
+	}
+	if pod.Spec.NodeName == "" {
+		return nil, i18n.Errorf("k8s.pod_unscheduled", c.Config.PodName)
+	}
+
+	node, err := c.KubeClient.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, i18n.Errorf("k8s.get_node_failed", pod.Spec.NodeName, err)
+	}
+	return node.Status.Allocatable, nil
 }
 
 // GetDeploymentInfo 获取Deployment信息
 func (c *Client) GetDeploymentInfo(ctx context.Context) (*metrics.DeploymentMetrics, error) {
 	deploy, err := c.KubeClient.AppsV1().Deployments(c.Config.Namespace).Get(ctx, c.Config.DeploymentName, metav1.GetOptions{})
 	if err != nil {
-		return nil, fmt.Errorf("获取Deployment失败: %v", err)
+		return nil, i18n.Errorf("k8s.get_deployment_failed", err)
 	}
 
 	return &metrics.DeploymentMetrics{
@@ -168,7 +278,7 @@ func (c *Client) GetDeploymentInfo(ctx context.Context) (*metrics.DeploymentMetr
 // GetContainerLimits 获取容器资源限制 (现在直接返回初始化时存储的值)
 func (c *Client) GetContainerLimits(ctx context.Context) (*metrics.ContainerLimits, error) {
 	if c.ContainerLimits == nil {
-		return nil, fmt.Errorf("容器资源限制未初始化")
+		return nil, i18n.Errorf("k8s.limits_uninitialized")
 	}
 	return c.ContainerLimits, nil
 }
@@ -177,7 +287,7 @@ func (c *Client) GetContainerLimits(ctx context.Context) (*metrics.ContainerLimi
 func (c *Client) GetPodInfo(ctx context.Context) (*metrics.PodMetrics, error) {
 	pod, err := c.KubeClient.CoreV1().Pods(c.Config.Namespace).Get(ctx, c.Config.PodName, metav1.GetOptions{})
 	if err != nil {
-		return nil, fmt.Errorf("获取Pod失败: %v", err)
+		return nil, i18n.Errorf("k8s.get_pod_failed", err)
 	}
 
 	result := &metrics.PodMetrics{
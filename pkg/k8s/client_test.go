@@ -0,0 +1,116 @@
+package k8s
+
+import (
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"metrics-sidecar/pkg/config"
+)
+
+func TestResolveBasis(t *testing.T) {
+	container := &corev1.Container{
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("1536Mi"),
+			},
+		},
+	}
+	allocatable := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("7910m"),
+		corev1.ResourceMemory: resource.MustParse("30Gi"),
+	}
+
+	tests := []struct {
+		name        string
+		basis       string
+		resource    corev1.ResourceName
+		container   *corev1.Container
+		absolute    int64
+		expected    int64
+		wantErr     bool
+		allocatable bool // 是否应获取节点可分配资源
+	}{
+		{"CPU limit（毫核）", config.BasisLimit, corev1.ResourceCPU, container, 0, 2000, false, false},
+		{"内存limit（MB）", config.BasisLimit, corev1.ResourceMemory, container, 0, 4096, false, false},
+		{"CPU request", config.BasisRequest, corev1.ResourceCPU, container, 0, 500, false, false},
+		{"内存request", config.BasisRequest, corev1.ResourceMemory, container, 0, 1536, false, false},
+		{"CPU节点可分配", config.BasisNodeAllocatable, corev1.ResourceCPU, container, 0, 7910, false, true},
+		{"内存节点可分配", config.BasisNodeAllocatable, corev1.ResourceMemory, container, 0, 30720, false, true},
+		{"CPU绝对值", config.BasisAbsolute, corev1.ResourceCPU, container, 1500, 1500, false, false},
+		{"内存绝对值", config.BasisAbsolute, corev1.ResourceMemory, container, 2048, 2048, false, false},
+		{"未设置limit", config.BasisLimit, corev1.ResourceCPU, &corev1.Container{}, 0, 0, true, false},
+		{"未设置request", config.BasisRequest, corev1.ResourceMemory, &corev1.Container{}, 0, 0, true, false},
+		{"绝对值为0", config.BasisAbsolute, corev1.ResourceCPU, container, 0, 0, true, false},
+		{"未知的基准", "unknown", corev1.ResourceCPU, container, 0, 0, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			getAllocatable := func() (corev1.ResourceList, error) {
+				calls++
+				return allocatable, nil
+			}
+
+			value, err := resolveBasis(tt.basis, tt.resource, tt.container, tt.absolute, getAllocatable)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveBasis()错误 = %v; 期望错误 %v", err, tt.wantErr)
+			}
+			if value != tt.expected {
+				t.Errorf("resolveBasis() = %d; 期望 %d", value, tt.expected)
+			}
+			expectedCalls := 0
+			if tt.allocatable {
+				expectedCalls = 1
+			}
+			if calls != expectedCalls {
+				t.Errorf("获取节点可分配资源%d次; 期望 %d次", calls, expectedCalls)
+			}
+		})
+	}
+}
+
+func TestResolveBasisAllocatableErrors(t *testing.T) {
+	container := &corev1.Container{}
+
+	// 获取节点失败时返回该错误
+	failure := errors.New("节点不存在")
+	_, err := resolveBasis(config.BasisNodeAllocatable, corev1.ResourceCPU, container, 0,
+		func() (corev1.ResourceList, error) { return nil, failure })
+	if !errors.Is(err, failure) {
+		t.Errorf("resolveBasis()错误 = %v; 期望 %v", err, failure)
+	}
+
+	// 节点上没有该资源时分母无效
+	_, err = resolveBasis(config.BasisNodeAllocatable, corev1.ResourceMemory, container, 0,
+		func() (corev1.ResourceList, error) {
+			return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")}, nil
+		})
+	if err == nil {
+		t.Error("节点缺少内存时resolveBasis()没有返回错误")
+	}
+}
+
+func TestQuantityValue(t *testing.T) {
+	list := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("1.5"),
+		corev1.ResourceMemory: resource.MustParse("512Mi"),
+	}
+	if got := quantityValue(corev1.ResourceCPU, list); got != 1500 {
+		t.Errorf("CPU = %d; 期望 1500毫核", got)
+	}
+	if got := quantityValue(corev1.ResourceMemory, list); got != 512 {
+		t.Errorf("内存 = %d; 期望 512MB", got)
+	}
+	if got := quantityValue(corev1.ResourceMemory, nil); got != 0 {
+		t.Errorf("缺少资源时 = %d; 期望 0", got)
+	}
+}
@@ -0,0 +1,71 @@
+package k8s
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/tools/reference"
+
+	"metrics-sidecar/pkg/i18n"
+)
+
+// 事件来源的组件名称
+const eventComponent = "metrics-sidecar"
+
+// 事件限流参数：同一Pod连续记录的事件超过eventBurst个后，每分钟最多记录一个。
+// 相同原因的重复事件由EventCorrelator合并计数
+const (
+	eventBurst = 10
+	eventQPS   = 1.0 / 60
+)
+
+// StartEventRecorder 启动EventRecorder，之后的事件都记录在当前Pod上，
+// 可通过kubectl describe pod查看
+func (c *Client) StartEventRecorder(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// kubectl describe按UID过滤事件，需要获取完整的Pod引用
+	pod, err := c.KubeClient.CoreV1().Pods(c.Config.Namespace).Get(ctx, c.Config.PodName, metav1.GetOptions{})
+	if err != nil {
+		return i18n.Errorf("k8s.get_pod_failed", err)
+	}
+	ref, err := reference.GetReference(scheme.Scheme, pod)
+	if err != nil {
+		return i18n.Errorf("k8s.pod_reference_failed", err)
+	}
+
+	broadcaster := record.NewBroadcaster(record.WithCorrelatorOptions(record.CorrelatorOptions{
+		BurstSize: eventBurst,
+		QPS:       eventQPS,
+	}))
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{
+		Interface: c.KubeClient.CoreV1().Events(c.Config.Namespace),
+	})
+
+	c.eventBroadcaster = broadcaster
+	c.eventRecorder = broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: eventComponent})
+	c.podRef = ref
+	k8sLog.WithField("pod", c.Config.PodName).Info(i18n.T("k8s.events_enabled"))
+	return nil
+}
+
+// RecordPodEvent 在当前Pod上异步记录事件，未启用EventRecorder时忽略
+func (c *Client) RecordPodEvent(eventType, reason, message string) {
+	if c.eventRecorder == nil {
+		return
+	}
+	c.eventRecorder.Event(c.podRef, eventType, reason, message)
+}
+
+// StopEventRecorder 停止EventRecorder，尽量发送尚未写入的事件
+func (c *Client) StopEventRecorder() {
+	if c.eventBroadcaster != nil {
+		c.eventBroadcaster.Shutdown()
+	}
+}
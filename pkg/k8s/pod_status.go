@@ -0,0 +1,51 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"metrics-sidecar/pkg/i18n"
+)
+
+// PatchPodCondition 设置当前Pod的自定义状态条件，其他条件保持不变
+func (c *Client) PatchPodCondition(ctx context.Context, condition corev1.PodCondition) error {
+	// Pod的conditions以type为合并键，strategic merge patch只会替换同类型的条件
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []corev1.PodCondition{condition},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.KubeClient.CoreV1().Pods(c.Config.Namespace).Patch(ctx, c.Config.PodName,
+		types.StrategicMergePatchType, patch, metav1.PatchOptions{}, "status")
+	if err != nil {
+		return i18n.Errorf("k8s.patch_condition_failed", err)
+	}
+	return nil
+}
+
+// PatchPodLabels 设置当前Pod的标签，其他标签保持不变
+func (c *Client) PatchPodLabels(ctx context.Context, labels map[string]string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": labels,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.KubeClient.CoreV1().Pods(c.Config.Namespace).Patch(ctx, c.Config.PodName,
+		types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return i18n.Errorf("k8s.patch_label_failed", err)
+	}
+	return nil
+}
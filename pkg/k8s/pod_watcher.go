@@ -0,0 +1,46 @@
+package k8s
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+
+	"metrics-sidecar/pkg/i18n"
+)
+
+// Informer的全量同步周期
+const podResyncPeriod = 10 * time.Minute
+
+// WatchPod 通过Informer监听当前Pod，Pod创建或更新时调用onUpdate，直到ctx结束
+func (c *Client) WatchPod(ctx context.Context, onUpdate func(pod *corev1.Pod)) {
+	factory := informers.NewSharedInformerFactoryWithOptions(c.KubeClient, podResyncPeriod,
+		informers.WithNamespace(c.Config.Namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", c.Config.PodName).String()
+		}),
+	)
+
+	informer := factory.Core().V1().Pods().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				onUpdate(pod)
+			}
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				onUpdate(pod)
+			}
+		},
+	})
+
+	k8sLog.WithField("pod", c.Config.PodName).Info(i18n.T("k8s.watch_pod_started"))
+	factory.Start(ctx.Done())
+	<-ctx.Done()
+	factory.Shutdown()
+}
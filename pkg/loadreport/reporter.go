@@ -0,0 +1,163 @@
+package loadreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/orca"
+
+	"metrics-sidecar/pkg/handlers"
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/logger"
+)
+
+// HeaderName Envoy读取ORCA负载报告的响应头
+const HeaderName = "endpoint-load-metrics"
+
+// OOB负载报告的最小推送间隔，客户端可以请求更长的间隔
+const minReportingInterval = 30 * time.Second
+
+var (
+	// ORCA负载报告的日志器
+	reportLog = logger.GetLogger("loadreport")
+)
+
+// Report 一次决策对应的ORCA负载报告
+type Report struct {
+	CPUUtilization float64            `json:"cpu_utilization"` // CPU使用量/CPU分母，可能大于1
+	MemUtilization float64            `json:"mem_utilization"` // 内存使用量/内存分母，范围0-1
+	Utilization    map[string]float64 `json:"utilization"`     // 自定义使用率，范围0-1，随OOB报告发送
+	NamedMetrics   map[string]float64 `json:"named_metrics"`   // 自定义指标，只在响应头中发送
+}
+
+// Reporter 根据每次决策的ResourceMetrics更新ORCA负载报告，
+// 通过OpenRcaService的OOB流和endpoint-load-metrics响应头提供
+type Reporter struct {
+	recorder orca.ServerMetricsRecorder
+
+	mu   sync.Mutex
+	last *Report
+}
+
+// NewReporter 创建负载报告器
+func NewReporter() *Reporter {
+	return &Reporter{recorder: orca.NewServerMetricsRecorder()}
+}
+
+// Listener 返回决策监听器，用最新的指标快照更新负载报告
+func (r *Reporter) Listener() handlers.DecisionListener {
+	return func(_, current *handlers.Decision) {
+		report := BuildReport(current)
+
+		r.recorder.SetCPUUtilization(report.CPUUtilization)
+		r.recorder.SetMemoryUtilization(report.MemUtilization)
+		for name, value := range report.Utilization {
+			r.recorder.SetNamedUtilization(name, value)
+		}
+
+		r.mu.Lock()
+		r.last = report
+		r.mu.Unlock()
+	}
+}
+
+// Register 在gRPC服务器上注册OpenRcaService，客户端通过StreamCoreMetrics订阅OOB负载报告
+func (r *Reporter) Register(s *grpc.Server) error {
+	return orca.Register(s, orca.ServiceOptions{
+		ServerMetricsProvider: r.recorder,
+		MinReportingInterval:  minReportingInterval,
+	})
+}
+
+// ServeHTTP 返回最近一次的负载报告，同时写入endpoint-load-metrics响应头，
+// 应用可将该响应头原样附加到自己的响应中。尚未决策时返回503
+func (r *Reporter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	report := r.last
+	r.mu.Unlock()
+
+	if report == nil {
+		http.Error(w, i18n.T("loadreport.no_decision"), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set(HeaderName, report.Header())
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		reportLog.WithError(err).Error(i18n.T("loadreport.encode_failed"))
+	}
+}
+
+// BuildReport 根据决策中的指标快照构造负载报告
+func BuildReport(decision *handlers.Decision) *Report {
+	m := decision.Metrics
+	report := &Report{
+		CPUUtilization: ratio(m.ContainerCPUUsage, m.ContainerCPULimit),
+		MemUtilization: min(ratio(m.ContainerMemUsage, m.ContainerMemLimit), 1),
+		// 使用率越高表示越繁忙，权重越低时卸载的流量越多，因此发布1-权重/100
+		Utilization: map[string]float64{
+			"traffic_drain": 1 - float64(decision.Weight)/100,
+		},
+		NamedMetrics: map[string]float64{
+			"cpu_usage_millicores": float64(m.ContainerCPUUsage),
+			"memory_usage_mb":      float64(m.ContainerMemUsage),
+			"weight":               float64(decision.Weight),
+		},
+	}
+	// 可用副本比例描述的是整个Deployment而不是当前Pod的负载，只作为自定义指标
+	if m.DeploymentReplicas > 0 {
+		report.NamedMetrics["available_replicas_ratio"] = min(ratio(int64(m.DeploymentAvailableReplicas), int64(m.DeploymentReplicas)), 1)
+	}
+	if decision.Shedding() {
+		report.NamedMetrics["shedding"] = 1
+	} else {
+		report.NamedMetrics["shedding"] = 0
+	}
+	return report
+}
+
+// Header 返回endpoint-load-metrics响应头的TEXT格式，如
+// "TEXT cpu_utilization=0.5, mem_utilization=0.3, named_metrics.weight=100"
+func (r *Report) Header() string {
+	fields := []string{
+		fmt.Sprintf("cpu_utilization=%s", formatValue(r.CPUUtilization)),
+		fmt.Sprintf("mem_utilization=%s", formatValue(r.MemUtilization)),
+	}
+	fields = append(fields, prefixed("utilization.", r.Utilization)...)
+	fields = append(fields, prefixed("named_metrics.", r.NamedMetrics)...)
+	return "TEXT " + strings.Join(fields, ", ")
+}
+
+// prefixed 按名称排序后输出带前缀的键值对
+func prefixed(prefix string, values map[string]float64) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]string, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, fmt.Sprintf("%s%s=%s", prefix, name, formatValue(values[name])))
+	}
+	return fields
+}
+
+// formatValue 以最短形式输出数值
+func formatValue(value float64) string {
+	return fmt.Sprintf("%g", value)
+}
+
+// ratio 计算使用量与分母的比值，分母无效时为0
+func ratio(usage, limit int64) float64 {
+	if limit <= 0 {
+		return 0
+	}
+	return float64(usage) / float64(limit)
+}
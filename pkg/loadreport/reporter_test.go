@@ -0,0 +1,71 @@
+package loadreport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"metrics-sidecar/pkg/handlers"
+	"metrics-sidecar/pkg/metrics"
+)
+
+func testDecision() *handlers.Decision {
+	return &handlers.Decision{
+		Status: handlers.StatusHealthy,
+		Weight: 50,
+		Metrics: &metrics.ResourceMetrics{
+			DeploymentReplicas:          4,
+			DeploymentAvailableReplicas: 3,
+			ContainerCPULimit:           2000,
+			ContainerCPUUsage:           1000,
+			ContainerMemLimit:           1024,
+			ContainerMemUsage:           256,
+		},
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	report := BuildReport(testDecision())
+	if report.CPUUtilization != 0.5 || report.MemUtilization != 0.25 {
+		t.Errorf("使用率 = cpu %v, mem %v; 期望 0.5, 0.25", report.CPUUtilization, report.MemUtilization)
+	}
+	if len(report.Utilization) != 1 || report.Utilization["traffic_drain"] != 0.5 {
+		t.Errorf("自定义使用率 = %v; 期望只有traffic_drain=0.5", report.Utilization)
+	}
+	if report.NamedMetrics["available_replicas_ratio"] != 0.75 {
+		t.Errorf("available_replicas_ratio = %v; 期望 0.75", report.NamedMetrics["available_replicas_ratio"])
+	}
+	if report.NamedMetrics["cpu_usage_millicores"] != 1000 || report.NamedMetrics["shedding"] != 0 {
+		t.Errorf("自定义指标 = %v; 期望cpu_usage_millicores=1000, shedding=0", report.NamedMetrics)
+	}
+}
+
+func TestReportHeader(t *testing.T) {
+	expected := "TEXT cpu_utilization=0.5, mem_utilization=0.25, " +
+		"utilization.traffic_drain=0.5, " +
+		"named_metrics.available_replicas_ratio=0.75, named_metrics.cpu_usage_millicores=1000, named_metrics.memory_usage_mb=256, " +
+		"named_metrics.shedding=0, named_metrics.weight=50"
+	if header := BuildReport(testDecision()).Header(); header != expected {
+		t.Errorf("Header() = %q; 期望 %q", header, expected)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	reporter := NewReporter()
+
+	recorder := httptest.NewRecorder()
+	reporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/load", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("尚未决策时状态码 = %d; 期望 503", recorder.Code)
+	}
+
+	reporter.Listener()(nil, testDecision())
+	recorder = httptest.NewRecorder()
+	reporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/load", nil))
+	if recorder.Code != http.StatusOK || recorder.Header().Get(HeaderName) == "" {
+		t.Errorf("决策后状态码 = %d, 响应头 %q; 期望 200且包含%s", recorder.Code, recorder.Header().Get(HeaderName), HeaderName)
+	}
+	if metrics := reporter.recorder.ServerMetrics(); metrics.CPUUtilization != 0.5 {
+		t.Errorf("OOB报告的CPU使用率 = %v; 期望 0.5", metrics.CPUUtilization)
+	}
+}
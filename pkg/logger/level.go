@@ -0,0 +1,252 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"metrics-sidecar/pkg/i18n"
+)
+
+var (
+	// 运行时修改日志级别的日志器
+	levelLog = GetLogger("loglevel")
+
+	// 等待自动恢复的级别修改，键为模块名，空字符串表示全局级别，由loggersMu保护
+	pendingReverts = make(map[string]*levelRevert)
+)
+
+// levelState 全局或某个模块的级别设置，overridden为false表示模块没有覆盖全局级别
+type levelState struct {
+	level      logrus.Level
+	overridden bool
+}
+
+// levelRevert 一次带超时的级别修改，到期后恢复到修改前的设置
+type levelRevert struct {
+	previous levelState
+	at       time.Time
+	timer    *time.Timer
+}
+
+// lookupLevel 解析日志级别，无效时返回false
+func lookupLevel(level string) (logrus.Level, bool) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return logrus.DebugLevel, true
+	case "info":
+		return logrus.InfoLevel, true
+	case "warn", "warning":
+		return logrus.WarnLevel, true
+	case "error":
+		return logrus.ErrorLevel, true
+	}
+	return logrus.InfoLevel, false
+}
+
+// SetLevel 在运行时修改日志级别，component为空时修改全局级别，没有覆盖级别的模块随之改变。
+// timeout大于0时到期后恢复到修改前的设置；同一目标已有等待恢复的修改时，取消原定时器，
+// 恢复目标仍为第一次临时修改之前的设置
+func SetLevel(component string, level logrus.Level, timeout time.Duration) {
+	loggersMu.Lock()
+	defer loggersMu.Unlock()
+
+	previous := currentLevel(component)
+	if revert, exists := pendingReverts[component]; exists {
+		revert.timer.Stop()
+		previous = revert.previous
+		delete(pendingReverts, component)
+	}
+	applyLevel(component, levelState{level: level, overridden: true})
+
+	if timeout > 0 {
+		revert := &levelRevert{previous: previous, at: time.Now().Add(timeout)}
+		revert.timer = time.AfterFunc(timeout, func() { restoreLevel(component, revert) })
+		pendingReverts[component] = revert
+	}
+}
+
+// restoreLevel 超时后恢复修改前的设置，修改已被后续请求替换时忽略
+func restoreLevel(component string, revert *levelRevert) {
+	loggersMu.Lock()
+	defer loggersMu.Unlock()
+
+	if pendingReverts[component] != revert {
+		return
+	}
+	delete(pendingReverts, component)
+	applyLevel(component, revert.previous)
+	levelLog.WithFields(logrus.Fields{
+		"target": levelTarget(component),
+		"level":  revert.previous.level.String(),
+	}).Warn(i18n.T("logger.level_reverted"))
+}
+
+// currentLevel 返回全局或模块当前的级别设置，调用方需持有loggersMu
+func currentLevel(component string) levelState {
+	if component == "" {
+		return levelState{level: logrus.GetLevel(), overridden: true}
+	}
+	if level, exists := componentLevels[component]; exists {
+		return levelState{level: level, overridden: true}
+	}
+	return levelState{level: logrus.GetLevel()}
+}
+
+// applyLevel 修改全局或模块的级别设置并应用到已创建的日志器，调用方需持有loggersMu
+func applyLevel(component string, state levelState) {
+	if component == "" {
+		logrus.SetLevel(state.level)
+	} else if state.overridden {
+		componentLevels[component] = state.level
+	} else {
+		delete(componentLevels, component)
+	}
+
+	for name, l := range loggers {
+		if component == "" || name == component {
+			applyStandard(l, name)
+		}
+	}
+}
+
+// levelTarget 返回日志中修改目标的名称
+func levelTarget(component string) string {
+	if component == "" {
+		return "global"
+	}
+	return component
+}
+
+// levelRequest PUT /debug/loglevel的请求体
+type levelRequest struct {
+	Level     string `json:"level"`
+	Component string `json:"component"` // 为空表示全局级别
+	Timeout   string `json:"timeout"`   // 如10m，为空表示不自动恢复
+}
+
+// levelResponse /debug/loglevel返回的当前级别设置
+type levelResponse struct {
+	Level      string                `json:"level"`
+	Components map[string]string     `json:"components"`
+	Reverts    []levelRevertResponse `json:"reverts"`
+}
+
+// levelRevertResponse 一次等待自动恢复的修改
+type levelRevertResponse struct {
+	Component string    `json:"component,omitempty"` // 为空表示全局级别
+	Level     string    `json:"level"`               // 恢复后的级别
+	At        time.Time `json:"at"`
+}
+
+// LevelHandler 处理/debug/loglevel: GET返回当前的全局和模块级别，PUT修改级别，
+// 请求体如{"level":"debug","component":"health","timeout":"10m"}
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			if err := updateLevel(r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, i18n.T("logger.method_not_allowed"), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		jsonData, err := json.MarshalIndent(levels(), "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(jsonData)
+	})
+}
+
+// updateLevel 按PUT请求修改日志级别
+func updateLevel(r *http.Request) error {
+	var request levelRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return i18n.Errorf("logger.invalid_json", err)
+	}
+	level, ok := lookupLevel(request.Level)
+	if !ok {
+		return i18n.Errorf("logger.invalid_level", request.Level)
+	}
+	if request.Component != "" {
+		if components := registeredComponents(); !contains(components, request.Component) {
+			return i18n.Errorf("logger.unknown_component", request.Component, strings.Join(components, ", "))
+		}
+	}
+	var timeout time.Duration
+	if request.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(request.Timeout); err != nil || timeout <= 0 {
+			return i18n.Errorf("logger.invalid_timeout", request.Timeout)
+		}
+	}
+
+	SetLevel(request.Component, level, timeout)
+	fields := logrus.Fields{
+		"target":      levelTarget(request.Component),
+		"level":       level.String(),
+		"remote_addr": r.RemoteAddr,
+	}
+	if timeout > 0 {
+		fields["timeout"] = timeout
+	}
+	levelLog.WithFields(fields).Warn(i18n.T("logger.level_changed"))
+	return nil
+}
+
+// registeredComponents 返回已创建日志器的模块名，按名称排序
+func registeredComponents() []string {
+	loggersMu.Lock()
+	defer loggersMu.Unlock()
+
+	components := make([]string, 0, len(loggers))
+	for component := range loggers {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	return components
+}
+
+// contains 判断有序的字符串列表中是否包含value
+func contains(sorted []string, value string) bool {
+	i := sort.SearchStrings(sorted, value)
+	return i < len(sorted) && sorted[i] == value
+}
+
+// levels 返回当前的级别设置和等待恢复的修改
+func levels() levelResponse {
+	loggersMu.Lock()
+	defer loggersMu.Unlock()
+
+	response := levelResponse{
+		Level:      logrus.GetLevel().String(),
+		Components: make(map[string]string, len(componentLevels)),
+		Reverts:    make([]levelRevertResponse, 0, len(pendingReverts)),
+	}
+	for component, level := range componentLevels {
+		response.Components[component] = level.String()
+	}
+	for component, revert := range pendingReverts {
+		level := revert.previous.level.String()
+		if !revert.previous.overridden {
+			level = "global"
+		}
+		response.Reverts = append(response.Reverts, levelRevertResponse{Component: component, Level: level, At: revert.at})
+	}
+	sort.Slice(response.Reverts, func(i, j int) bool {
+		return response.Reverts[i].Component < response.Reverts[j].Component
+	})
+	return response
+}
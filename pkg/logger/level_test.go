@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"metrics-sidecar/pkg/config"
+)
+
+// putLevel 向/debug/loglevel发送PUT请求，返回状态码和解析后的响应
+func putLevel(t *testing.T, body string) (int, levelResponse) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/debug/loglevel", strings.NewReader(body)))
+
+	var response levelResponse
+	if recorder.Code == http.StatusOK {
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("响应不是有效的JSON: %v\n%s", err, recorder.Body.String())
+		}
+	}
+	return recorder.Code, response
+}
+
+func TestLevelHandler(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := testConfig(config.LogFormatText)
+	cfg.LogComponentLevels = map[string]string{"k8s": "warn"}
+	configure(cfg, &buf)
+	defer configure(testConfig(config.LogFormatText), &buf)
+
+	health, k8s := GetLogger("health"), GetLogger("k8s")
+
+	// 修改全局级别，覆盖了级别的模块不受影响
+	code, response := putLevel(t, `{"level":"debug"}`)
+	if code != http.StatusOK {
+		t.Fatalf("状态码 = %d; 期望 200", code)
+	}
+	if response.Level != "debug" || response.Components["k8s"] != "warning" {
+		t.Errorf("响应 = %+v; 期望全局debug且k8s保持warning", response)
+	}
+	if health.Logger.GetLevel() != logrus.DebugLevel || k8s.Logger.GetLevel() != logrus.WarnLevel {
+		t.Errorf("health级别 = %s, k8s级别 = %s; 期望 debug, warning", health.Logger.GetLevel(), k8s.Logger.GetLevel())
+	}
+
+	// 修改模块级别
+	if code, _ := putLevel(t, `{"level":"error","component":"health"}`); code != http.StatusOK {
+		t.Fatalf("状态码 = %d; 期望 200", code)
+	}
+	if health.Logger.GetLevel() != logrus.ErrorLevel {
+		t.Errorf("health级别 = %s; 期望 error", health.Logger.GetLevel())
+	}
+
+	// 未创建日志器的模块名通常是拼写错误，拒绝修改
+	for _, body := range []string{`{"level":"verbose"}`, `{"level":"debug","timeout":"-1m"}`, `not json`, `{"level":"debug","component":"helth"}`} {
+		if code, _ := putLevel(t, body); code != http.StatusBadRequest {
+			t.Errorf("请求体%s的状态码 = %d; 期望 400", body, code)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/debug/loglevel", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST的状态码 = %d; 期望 405", recorder.Code)
+	}
+}
+
+func TestLevelRevert(t *testing.T) {
+	var buf bytes.Buffer
+	configure(testConfig(config.LogFormatText), &buf)
+	defer configure(testConfig(config.LogFormatText), &buf)
+
+	statsd := GetLogger("statsd")
+	SetLevel("statsd", logrus.DebugLevel, time.Hour)
+	// 等待恢复期间再次修改，恢复目标仍为第一次修改之前的设置
+	SetLevel("statsd", logrus.WarnLevel, 50*time.Millisecond)
+
+	response := levels()
+	if len(response.Reverts) != 1 || response.Reverts[0].Component != "statsd" || response.Reverts[0].Level != "global" {
+		t.Errorf("等待恢复的修改 = %+v; 期望statsd恢复为global", response.Reverts)
+	}
+	if statsd.Logger.GetLevel() != logrus.WarnLevel {
+		t.Errorf("statsd级别 = %s; 期望 warning", statsd.Logger.GetLevel())
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(levels().Reverts) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	response = levels()
+	if len(response.Reverts) != 0 {
+		t.Fatalf("修改未在超时后恢复: %+v", response.Reverts)
+	}
+	if _, exists := response.Components["statsd"]; exists || statsd.Logger.GetLevel() != logrus.InfoLevel {
+		t.Errorf("statsd级别 = %s, 模块级别 = %v; 期望恢复为全局级别info", statsd.Logger.GetLevel(), response.Components)
+	}
+	if !strings.Contains(buf.String(), "日志级别修改已到期") {
+		t.Errorf("缺少恢复日志:\n%s", buf.String())
+	}
+}
@@ -2,88 +2,256 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"metrics-sidecar/pkg/config"
+	"metrics-sidecar/pkg/i18n"
+)
+
+// 所有日志格式中一致的字段名
+const (
+	FieldTime       = "time"
+	FieldLevel      = "level"
+	FieldMessage    = "msg"
+	FieldCaller     = "caller"
+	FieldComponent  = "component"   // 输出日志的模块，由GetLogger设置
+	FieldPod        = "pod"         // 当前Pod名称，由Setup设置后自动添加
+	FieldNamespace  = "namespace"   // 当前命名空间，由Setup设置后自动添加
+	FieldStatus     = "status"      // 最近一次健康检查状态，由SetStatus设置后自动添加
+	FieldReasonCode = "reason_code" // 稳定的原因代码，如RESOURCES_WITHIN_THRESHOLDS，不随语言变化，与接口响应中的字段名一致
+
+	FieldSuppressed     = "suppressed"  // 采样汇总中上个窗口被丢弃的条数
+	FieldSampledMessage = "sampled_msg" // 采样汇总中被丢弃的日志消息
+)
+
+var (
+	// 最近一次健康检查状态，尚未决策时为空
+	currentStatus atomic.Value
+
+	// 每个模块独立的日志器，使模块可以使用不同的级别，格式、输出和钩子与全局logrus一致
+	loggersMu       sync.Mutex
+	loggers         = make(map[string]*logrus.Logger)
+	componentLevels = make(map[string]logrus.Level) // 按模块覆盖的级别，未覆盖的模块使用全局级别
 )
 
 // 初始化 logrus 日志配置
 func Setup(cfg *config.Config) {
-	// 设置日志格式
-	logrus.SetFormatter(&logrus.TextFormatter{
-		FullTimestamp:   true,
-		TimestampFormat: "2006-01-02 15:04:05.000",
-		CallerPrettyfier: func(f *runtime.Frame) (string, string) {
-			filename := path.Base(f.File)
-			return "", fmt.Sprintf("%s:%d", filename, f.Line)
-		},
-	})
-
-	// 设置输出到标准输出
-	logrus.SetOutput(os.Stdout)
+	configure(cfg, os.Stdout)
+
+	logrus.WithFields(logrus.Fields{
+		"level":  logrus.GetLevel().String(),
+		"format": cfg.LogFormat,
+	}).Info(i18n.T("logger.initialized"))
+}
+
+// configure 按配置设置全局logrus和各模块日志器的格式、级别、调用者信息和自动添加的字段
+func configure(cfg *config.Config, output io.Writer) {
+	// 设置日志格式，同一条日志在窗口内重复过多时丢弃
+	if sampler, ok := logrus.StandardLogger().Formatter.(*samplingFormatter); ok {
+		sampler.stop()
+	}
+	var formatter logrus.Formatter = newFormatter(cfg.LogFormat, cfg.LogTimestampFormat)
+	if cfg.LogSampleBurst > 0 {
+		formatter = newSamplingFormatter(formatter, cfg.LogSampleBurst, cfg.LogSampleWindow)
+	}
+	logrus.SetFormatter(formatter)
+
+	// 设置输出
+	logrus.SetOutput(output)
 
 	// 设置日志级别
-	logLevel := strings.ToLower(cfg.LogLevel)
-	switch logLevel {
-	case "debug":
-		logrus.SetLevel(logrus.DebugLevel)
-	case "info":
-		logrus.SetLevel(logrus.InfoLevel)
-	case "warn", "warning":
-		logrus.SetLevel(logrus.WarnLevel)
-	case "error":
-		logrus.SetLevel(logrus.ErrorLevel)
+	logrus.SetLevel(parseLevel(cfg.LogLevel))
+
+	// 显示调用者信息
+	logrus.SetReportCaller(cfg.LogCaller)
+
+	// GetLogger通常在包初始化时调用，此时还没有加载配置，Pod和命名空间由钩子在写日志时添加
+	logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+	logrus.AddHook(&contextHook{pod: cfg.PodName, namespace: cfg.Namespace})
+
+	loggersMu.Lock()
+	defer loggersMu.Unlock()
+	// 重新配置后不再恢复运行时修改前的级别
+	for component, revert := range pendingReverts {
+		revert.timer.Stop()
+		delete(pendingReverts, component)
+	}
+	componentLevels = make(map[string]logrus.Level, len(cfg.LogComponentLevels))
+	for component, level := range cfg.LogComponentLevels {
+		componentLevels[component] = parseLevel(level)
+	}
+	for component, l := range loggers {
+		applyStandard(l, component)
+	}
+}
+
+// parseLevel 解析日志级别，无效时使用info
+func parseLevel(level string) logrus.Level {
+	parsed, _ := lookupLevel(level)
+	return parsed
+}
+
+// applyStandard 使模块日志器与全局logrus的设置一致，级别优先使用模块覆盖的级别，调用方需持有loggersMu
+func applyStandard(l *logrus.Logger, component string) {
+	std := logrus.StandardLogger()
+	l.SetFormatter(std.Formatter)
+	l.SetOutput(std.Out)
+	l.SetReportCaller(std.ReportCaller)
+	l.ReplaceHooks(std.Hooks)
+
+	level, exists := componentLevels[component]
+	if !exists {
+		level = std.GetLevel()
+	}
+	l.SetLevel(level)
+}
+
+// newFormatter 创建指定格式的日志格式化器，所有格式使用相同的字段名
+func newFormatter(format, timestampFormat string) logrus.Formatter {
+	disableTimestamp := false
+	switch strings.ToLower(timestampFormat) {
+	case "none":
+		disableTimestamp = true
+	case "rfc3339":
+		timestampFormat = time.RFC3339
+	case "rfc3339nano":
+		timestampFormat = time.RFC3339Nano
+	}
+
+	fieldMap := logrus.FieldMap{
+		logrus.FieldKeyTime:  FieldTime,
+		logrus.FieldKeyLevel: FieldLevel,
+		logrus.FieldKeyMsg:   FieldMessage,
+		logrus.FieldKeyFile:  FieldCaller,
+	}
+	// 调用者只输出文件名和行号，不输出函数名
+	callerPrettyfier := func(f *runtime.Frame) (string, string) {
+		filename := path.Base(f.File)
+		return "", fmt.Sprintf("%s:%d", filename, f.Line)
+	}
+
+	switch format {
+	case config.LogFormatJSON:
+		return &logrus.JSONFormatter{
+			TimestampFormat:  timestampFormat,
+			DisableTimestamp: disableTimestamp,
+			FieldMap:         fieldMap,
+			CallerPrettyfier: callerPrettyfier,
+		}
+	case config.LogFormatLogfmt:
+		return &logrus.TextFormatter{
+			DisableColors:    true,
+			FullTimestamp:    true,
+			TimestampFormat:  timestampFormat,
+			DisableTimestamp: disableTimestamp,
+			FieldMap:         fieldMap,
+			CallerPrettyfier: callerPrettyfier,
+		}
 	default:
-		logrus.SetLevel(logrus.InfoLevel)
+		return &logrus.TextFormatter{
+			FullTimestamp:    true,
+			TimestampFormat:  timestampFormat,
+			DisableTimestamp: disableTimestamp,
+			FieldMap:         fieldMap,
+			CallerPrettyfier: callerPrettyfier,
+		}
 	}
+}
 
-	// 显示调用者信息
-	logrus.SetReportCaller(true)
+// contextHook 为每条日志添加Pod、命名空间、最近一次健康检查状态和消息目录中的原因代码，已有同名字段时保留原值
+type contextHook struct {
+	pod       string
+	namespace string
+}
 
-	logrus.WithFields(logrus.Fields{
-		"level": logrus.GetLevel().String(),
-	}).Info("日志系统初始化完成")
+// Levels 实现logrus.Hook接口
+func (h *contextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
 }
 
-// GetLogger 返回一个预设了特定字段的 logger 实例
+// Fire 实现logrus.Hook接口
+func (h *contextHook) Fire(entry *logrus.Entry) error {
+	setDefault(entry.Data, FieldPod, h.pod)
+	setDefault(entry.Data, FieldNamespace, h.namespace)
+	if status, _ := currentStatus.Load().(string); status != "" {
+		setDefault(entry.Data, FieldStatus, status)
+	}
+	// 消息来自消息目录时，以键作为原因代码，使日志不依赖语言也能检索
+	if key, ok := i18n.Lookup(entry.Message); ok {
+		setDefault(entry.Data, FieldReasonCode, i18n.Code(key))
+	}
+	return nil
+}
+
+// setDefault 在字段不存在且value非空时设置字段
+func setDefault(data logrus.Fields, key, value string) {
+	if _, exists := data[key]; !exists && value != "" {
+		data[key] = value
+	}
+}
+
+// SetStatus 记录最近一次健康检查状态，之后的日志都会带上status字段
+func SetStatus(status string) {
+	currentStatus.Store(status)
+}
+
+// GetLogger 返回一个预设了component字段的 logger 实例，同一模块共用一个日志器和级别
 func GetLogger(component string) *logrus.Entry {
-	return logrus.WithField("component", component)
+	loggersMu.Lock()
+	defer loggersMu.Unlock()
+
+	l, exists := loggers[component]
+	if !exists {
+		l = logrus.New()
+		applyStandard(l, component)
+		loggers[component] = l
+	}
+	return l.WithField(FieldComponent, component)
 }
 
 // 以下是一些便捷的日志工具函数
 
-// HTTPRequestCompleted 记录 HTTP 请求完成的日志
-func HTTPRequestCompleted(method, path string, remoteAddr string, duration interface{}) {
-	logrus.WithFields(logrus.Fields{
+var (
+	// HTTP访问日志的日志器，在包初始化时创建，使/debug/loglevel在第一个请求之前就能识别该模块
+	httpLog = GetLogger("http")
+)
+
+// HTTPRequestCompleted 以指定级别记录 HTTP 请求完成的日志，模块为http
+func HTTPRequestCompleted(level logrus.Level, method, path string, remoteAddr string, duration interface{}) {
+	httpLog.WithFields(logrus.Fields{
 		"remote_addr": remoteAddr,
 		"method":      method,
 		"path":        path,
 		"duration":    duration,
-	}).Info("HTTP请求完成")
+	}).Log(level, i18n.T("http.request_completed"))
 }
 
 // StartupInfo 记录服务启动信息
 func StartupInfo(config *config.Config) {
-	logrus.Info("指标采集服务启动中...")
+	logrus.Info(i18n.T("main.starting"))
 
 	// 显示连接模式和配置信息
 	if config.InClusterConfig {
-		logrus.Info("使用集群内配置模式 (InCluster)")
+		logrus.Info(i18n.T("main.in_cluster"))
 	} else {
 		logrus.WithField("kubeconfig", config.KubeconfigPath).
-			Info("使用外部配置文件模式")
+			Info(i18n.T("main.kubeconfig"))
 	}
 
 	logrus.WithFields(logrus.Fields{
 		"namespace":       config.Namespace,
 		"deployment_name": config.DeploymentName,
 		"container_name":  config.ContainerName,
-	}).Info("加载配置完成")
+	}).Info(i18n.T("main.config_loaded"))
 }
 
 // ShutdownInfo 记录服务关闭信息
@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"metrics-sidecar/pkg/config"
+	"metrics-sidecar/pkg/i18n"
+)
+
+func testConfig(format string) *config.Config {
+	return &config.Config{
+		Namespace:          "default",
+		PodName:            "app-1",
+		LogLevel:           "info",
+		LogFormat:          format,
+		LogTimestampFormat: "rfc3339",
+		LogCaller:          true,
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	configure(testConfig(config.LogFormatJSON), &buf)
+	SetStatus("HEALTHY")
+	defer SetStatus("")
+
+	GetLogger("health").WithField("duration", "5ms").Info("健康检查请求处理完成")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("日志不是有效的JSON: %v\n%s", err, buf.String())
+	}
+	expected := map[string]string{
+		FieldLevel:     "info",
+		FieldMessage:   "健康检查请求处理完成",
+		FieldComponent: "health",
+		FieldPod:       "app-1",
+		FieldNamespace: "default",
+		FieldStatus:    "HEALTHY",
+	}
+	for key, value := range expected {
+		if entry[key] != value {
+			t.Errorf("%s = %v; 期望 %s", key, entry[key], value)
+		}
+	}
+	if caller, _ := entry[FieldCaller].(string); !strings.HasPrefix(caller, "logger_test.go:") {
+		t.Errorf("%s = %v; 期望 logger_test.go:行号", FieldCaller, entry[FieldCaller])
+	}
+	if _, exists := entry[FieldTime]; !exists {
+		t.Errorf("缺少%s字段", FieldTime)
+	}
+}
+
+func TestLogfmtFormat(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := testConfig(config.LogFormatLogfmt)
+	cfg.LogTimestampFormat = "none"
+	cfg.LogCaller = false
+	configure(cfg, &buf)
+
+	// 显式设置的字段保留原值
+	GetLogger("grpchealth").WithField(FieldStatus, "NOT_READY").Warn("done")
+
+	line := strings.TrimSpace(buf.String())
+	expected := "level=warning msg=done component=grpchealth namespace=default pod=app-1 status=NOT_READY"
+	if line != expected {
+		t.Errorf("日志 = %q; 期望 %q", line, expected)
+	}
+}
+
+func TestComponentLevels(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := testConfig(config.LogFormatLogfmt)
+	cfg.LogTimestampFormat = "none"
+	cfg.LogCaller = false
+	cfg.LogComponentLevels = map[string]string{"health": "warn", "k8s": "debug"}
+	configure(cfg, &buf)
+	defer configure(testConfig(config.LogFormatText), &buf)
+
+	health := GetLogger("health")
+	health.Info("健康检查请求处理完成")
+	health.Warn("健康检查失败")
+	GetLogger("k8s").Debug("获取Pod信息")
+	// 未覆盖级别的模块使用全局级别
+	GetLogger("statsd").Debug("发送指标")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []string{
+		"level=warning msg=\"健康检查失败\" component=health namespace=default pod=app-1 reason_code=HEALTH_FAILED",
+		"level=debug msg=\"获取Pod信息\" component=k8s namespace=default pod=app-1 reason_code=HEALTH_GET_POD",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("日志 = %q; 期望 %q", lines, expected)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := testConfig(config.LogFormatLogfmt)
+	cfg.LogTimestampFormat = "none"
+	cfg.LogCaller = false
+	cfg.LogSampleBurst = 2
+	cfg.LogSampleWindow = time.Minute
+	configure(cfg, &buf)
+	defer configure(testConfig(config.LogFormatText), &buf)
+
+	log := GetLogger("health")
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		log.WithTime(start.Add(time.Duration(i) * time.Second)).Info("开始收集资源指标")
+	}
+	log.WithTime(start).Info("获取Pod信息")
+	// 下个窗口再次出现时先输出上个窗口的汇总
+	log.WithTime(start.Add(time.Minute)).Info("开始收集资源指标")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []string{
+		"level=info msg=\"开始收集资源指标\" component=health namespace=default pod=app-1 reason_code=HEALTH_COLLECT_STARTED",
+		"level=info msg=\"开始收集资源指标\" component=health namespace=default pod=app-1 reason_code=HEALTH_COLLECT_STARTED",
+		"level=info msg=\"获取Pod信息\" component=health namespace=default pod=app-1 reason_code=HEALTH_GET_POD",
+		"level=info msg=\"重复日志已被采样丢弃\" component=health namespace=default pod=app-1 reason_code=HEALTH_COLLECT_STARTED sampled_msg=\"开始收集资源指标\" suppressed=3",
+		"level=info msg=\"开始收集资源指标\" component=health namespace=default pod=app-1 reason_code=HEALTH_COLLECT_STARTED",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("日志 =\n%s\n期望\n%s", strings.Join(lines, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestSamplingFlush(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := testConfig(config.LogFormatLogfmt)
+	cfg.LogTimestampFormat = "none"
+	cfg.LogCaller = false
+	cfg.LogSampleBurst = 1
+	cfg.LogSampleWindow = time.Minute
+	configure(cfg, &buf)
+	defer configure(testConfig(config.LogFormatText), &buf)
+	sampler := logrus.StandardLogger().Formatter.(*samplingFormatter)
+
+	log := GetLogger("health")
+	start := time.Now()
+	// 原因代码不同的同一条消息分别计数
+	for i := 0; i < 3; i++ {
+		log.WithTime(start).WithField(FieldReasonCode, "OVERLOADED_SHEDDING").Info("健康检查结果")
+		log.WithTime(start).WithField(FieldReasonCode, "POD_AVAILABILITY_LOW").Info("健康检查结果")
+	}
+
+	// 窗口未结束时不输出汇总，结束后由定时器输出，且不会重复输出
+	sampler.flush(start.Add(time.Second))
+	if strings.Contains(buf.String(), "suppressed") {
+		t.Errorf("窗口未结束时输出了汇总:\n%s", buf.String())
+	}
+	sampler.flush(start.Add(time.Minute))
+	sampler.flush(start.Add(2 * time.Minute))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("输出了%d行日志; 期望 4:\n%s", len(lines), buf.String())
+	}
+	summaries := strings.Join(lines[2:], "\n")
+	for _, reason := range []string{"OVERLOADED_SHEDDING", "POD_AVAILABILITY_LOW"} {
+		expected := "reason_code=" + reason + " sampled_msg=\"健康检查结果\" suppressed=2"
+		if !strings.Contains(summaries, expected) {
+			t.Errorf("汇总中缺少%q:\n%s", expected, summaries)
+		}
+	}
+}
+
+func TestReasonCodeFromCatalog(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := testConfig(config.LogFormatLogfmt)
+	cfg.LogTimestampFormat = "none"
+	cfg.LogCaller = false
+	configure(cfg, &buf)
+	i18n.SetLanguage(i18n.English)
+	defer i18n.SetLanguage(i18n.Chinese)
+
+	log := GetLogger("reload")
+	log.Warn(i18n.T("reload.failed"))
+	// 显式设置的原因代码和目录之外的消息保持不变
+	log.WithField(FieldReasonCode, "CUSTOM").Warn(i18n.T("reload.failed"))
+	log.Warn("done")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []string{
+		"level=warning msg=\"config reload failed, keeping the last valid configuration\" component=reload namespace=default pod=app-1 reason_code=RELOAD_FAILED",
+		"level=warning msg=\"config reload failed, keeping the last valid configuration\" component=reload namespace=default pod=app-1 reason_code=CUSTOM",
+		"level=warning msg=done component=reload namespace=default pod=app-1",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("日志 =\n%s\n期望\n%s", strings.Join(lines, "\n"), strings.Join(expected, "\n"))
+	}
+}
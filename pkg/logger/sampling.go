@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"metrics-sidecar/pkg/i18n"
+)
+
+// samplingFormatter 限制重复日志的数量: 每个窗口内同一模块、级别、原因代码和消息的日志只输出前burst条，
+// 其余丢弃并计数。被丢弃的日志在窗口结束后由定时器输出一条汇总，说明丢弃了多少条；
+// 窗口结束后该日志先于定时器再次出现时，在它之前输出汇总。
+// 格式化器在所有模块日志器间共用，因此自行加锁
+type samplingFormatter struct {
+	next   logrus.Formatter
+	burst  int
+	window time.Duration
+	done   chan struct{} // 关闭后停止定时输出汇总
+
+	mu        sync.Mutex
+	samples   map[sampleKey]*sample
+	lastSweep time.Time // 上次清理过期记录的时间
+}
+
+// sampleKey 判断日志是否重复的依据，不包括其他字段，字段不同的同一条日志视为重复。
+// 日志消息应为固定文本，变化的内容放在字段中，原因代码区分同一消息的不同情况
+type sampleKey struct {
+	component string
+	level     logrus.Level
+	reason    string
+	message   string
+}
+
+// sample 一条日志在当前窗口内的计数
+type sample struct {
+	start      time.Time     // 窗口开始时间
+	count      int           // 窗口内出现的次数
+	suppressed int           // 窗口内丢弃的次数
+	summary    *logrus.Entry // 第一次丢弃时生成的汇总日志，由定时器输出
+}
+
+// newSamplingFormatter 创建采样格式化器，未被丢弃的日志交给next格式化，并启动定时输出汇总的goroutine
+func newSamplingFormatter(next logrus.Formatter, burst int, window time.Duration) *samplingFormatter {
+	f := &samplingFormatter{
+		next:    next,
+		burst:   burst,
+		window:  window,
+		done:    make(chan struct{}),
+		samples: make(map[sampleKey]*sample),
+	}
+	go f.run()
+	return f
+}
+
+// run 每个窗口输出一次已结束窗口的汇总，直到stop
+func (f *samplingFormatter) run() {
+	ticker := time.NewTicker(f.window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.done:
+			return
+		case now := <-ticker.C:
+			f.flush(now)
+		}
+	}
+}
+
+// stop 停止定时输出汇总，重新配置日志时调用
+func (f *samplingFormatter) stop() {
+	close(f.done)
+}
+
+// flush 输出窗口已结束且有丢弃记录的汇总，并清理过期的记录
+func (f *samplingFormatter) flush(now time.Time) {
+	var summaries []*sample
+	f.mu.Lock()
+	for key, s := range f.samples {
+		if now.Sub(s.start) < f.window {
+			continue
+		}
+		if s.suppressed > 0 {
+			summaries = append(summaries, s)
+		}
+		delete(f.samples, key)
+	}
+	f.mu.Unlock()
+
+	// 汇总日志带有FieldSuppressed，不会再次被采样
+	for _, s := range summaries {
+		s.summary.Data[FieldSuppressed] = s.suppressed
+		s.summary.Log(s.summary.Level, s.summary.Message)
+	}
+}
+
+// Format 实现logrus.Formatter接口，丢弃的日志返回空内容
+func (f *samplingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	// 致命错误和汇总日志总是输出
+	if _, isSummary := entry.Data[FieldSuppressed]; isSummary || entry.Level <= logrus.FatalLevel {
+		return f.next.Format(entry)
+	}
+
+	component, _ := entry.Data[FieldComponent].(string)
+	reason, _ := entry.Data[FieldReasonCode].(string)
+	key := sampleKey{component: component, level: entry.Level, reason: reason, message: entry.Message}
+
+	f.mu.Lock()
+	f.sweep(entry.Time)
+	s := f.samples[key]
+	var previous *sample
+	if s == nil || entry.Time.Sub(s.start) >= f.window {
+		if s != nil && s.suppressed > 0 {
+			previous = s
+		}
+		s = &sample{start: entry.Time}
+		f.samples[key] = s
+	}
+	s.count++
+	drop := s.count > f.burst
+	if drop {
+		s.suppressed++
+		if s.summary == nil {
+			s.summary = summaryEntry(entry)
+		}
+	}
+	f.mu.Unlock()
+
+	if drop {
+		return nil, nil
+	}
+	line, err := f.next.Format(entry)
+	if err != nil || previous == nil {
+		return line, err
+	}
+	previous.summary.Data[FieldSuppressed] = previous.suppressed
+	previous.summary.Time = entry.Time
+	summary, err := f.next.Format(previous.summary)
+	if err != nil {
+		return line, nil
+	}
+	return append(summary, line...), nil
+}
+
+// sweep 每个窗口清理一次已过期且没有丢弃记录的日志，避免记录无限增长，调用方需持有f.mu
+func (f *samplingFormatter) sweep(now time.Time) {
+	if now.Sub(f.lastSweep) < f.window {
+		return
+	}
+	f.lastSweep = now
+	for key, s := range f.samples {
+		if s.suppressed == 0 && now.Sub(s.start) >= f.window {
+			delete(f.samples, key)
+		}
+	}
+}
+
+// summaryEntry 生成entry被丢弃时的汇总日志，只保留模块、原因代码和自动添加的字段。
+// entry在输出后会被logrus复用，因此复制需要的内容
+func summaryEntry(entry *logrus.Entry) *logrus.Entry {
+	data := logrus.Fields{
+		FieldSampledMessage: entry.Message,
+	}
+	for _, key := range []string{FieldComponent, FieldPod, FieldNamespace, FieldStatus, FieldReasonCode} {
+		if value, exists := entry.Data[key]; exists {
+			data[key] = value
+		}
+	}
+	return &logrus.Entry{
+		Logger:  entry.Logger,
+		Data:    data,
+		Level:   entry.Level,
+		Message: i18n.T("logger.sampled"),
+	}
+}
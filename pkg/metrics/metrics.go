@@ -2,12 +2,12 @@ package metrics
 
 import (
 	"context"
-	"fmt"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
 
 	"metrics-sidecar/pkg/config"
+	"metrics-sidecar/pkg/i18n"
 )
 
 // ContainerMetrics 包含容器的度量指标
@@ -18,10 +18,12 @@ type ContainerMetrics struct {
 	Ready    bool
 }
 
-// ContainerLimits 仅包含容器的资源限制
+// ContainerLimits 包含计算资源使用率所用的分母及其来源
 type ContainerLimits struct {
-	CPULimit int64 // 毫核
-	MemLimit int64 // MB
+	CPULimit int64  // 毫核
+	MemLimit int64  // MB
+	CPUBasis string // CPU分母的来源 (limit, request, node-allocatable, absolute)
+	MemBasis string // 内存分母的来源 (limit, request, node-allocatable, absolute)
 }
 
 // PodMetrics 包含Pod的度量指标
@@ -46,9 +48,13 @@ type ResourceMetrics struct {
 	ContainerName               string `json:"container_name"`
 	ContainerCPULimit           int64  `json:"container_cpu_limit"` // 毫核
 	ContainerMemLimit           int64  `json:"container_mem_limit"` // MB
+	ContainerCPUBasis           string `json:"container_cpu_basis"`
+	ContainerMemBasis           string `json:"container_mem_basis"`
 	ContainerReady              bool   `json:"container_ready"`
-	ContainerCPUUsage           int64  `json:"container_cpu_usage"` // 毫核
-	ContainerMemUsage           int64  `json:"container_mem_usage"` // MB
+	ContainerNotReadyReason     string `json:"container_not_ready_reason,omitempty"` // 应用就绪探针失败的原因
+	ContainerCPUUsage           int64  `json:"container_cpu_usage"`                  // 毫核
+	ContainerMemUsage           int64  `json:"container_mem_usage"`                  // MB
+	MetricsError                string `json:"metrics_error,omitempty"`              // 获取Pod度量指标失败的原因，为空表示指标可用
 }
 
 // MetricsCollector 用于收集容器的度量指标
@@ -69,7 +75,7 @@ func NewMetricsCollector(metricsClient *metricsclient.Clientset, cfg *config.Con
 func (m *MetricsCollector) GetPodMetrics(ctx context.Context) (*PodMetrics, error) {
 	podMetrics, err := m.MetricsClient.MetricsV1beta1().PodMetricses(m.Config.Namespace).Get(ctx, m.Config.PodName, metav1.GetOptions{})
 	if err != nil {
-		return nil, fmt.Errorf("获取Pod度量指标失败: %v", err)
+		return nil, i18n.Errorf("metrics.pod_metrics_failed", err)
 	}
 
 	result := &PodMetrics{
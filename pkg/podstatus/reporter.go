@@ -0,0 +1,175 @@
+package podstatus
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/k8s"
+	"metrics-sidecar/pkg/logger"
+)
+
+// ConditionNotOverloaded 反映当前Pod是否在卸载流量的自定义状态条件。
+// 在Pod的readinessGates中引用该条件后，卸载流量时Pod会变为未就绪
+const ConditionNotOverloaded corev1.PodConditionType = "metrics-sidecar.io/NotOverloaded"
+
+const (
+	// 更新失败后的重试间隔
+	retryInterval = 5 * time.Second
+	// 定期重新写入状态的间隔，用于纠正被外部修改的标签
+	resyncInterval = 5 * time.Minute
+)
+
+var (
+	// Pod状态上报的日志器
+	statusLog = logger.GetLogger("podstatus")
+)
+
+// State 需要反映到Pod上的状态
+type State struct {
+	Status   string // 健康检查状态，如HEALTHY
+	Shedding bool   // 是否在卸载流量
+	Message  string // 状态变化时的决策消息
+}
+
+// Reporter 将健康检查的决策结果写入当前Pod的状态条件和标签。
+// 决策在锁内同步通知，实际的API调用在Run的goroutine中异步完成
+type Reporter struct {
+	client    *k8s.Client
+	condition bool   // 是否写入ConditionNotOverloaded
+	labelKey  string // 写入状态的标签名，为空表示不写入
+	trigger   chan struct{}
+
+	mu      sync.Mutex
+	desired *State
+
+	// 以下字段仅在Run的goroutine中访问
+	applied           *State
+	lastTransition    metav1.Time // 状态条件最近一次在True和False之间切换的时间
+	conditionShedding *bool       // 最近一次写入状态条件时的卸载状态
+}
+
+// NewReporter 创建Pod状态上报器
+func NewReporter(client *k8s.Client, condition bool, labelKey string) *Reporter {
+	return &Reporter{
+		client:    client,
+		condition: condition,
+		labelKey:  labelKey,
+		trigger:   make(chan struct{}, 1),
+	}
+}
+
+// Report 记录最新状态，仅在状态变化时触发更新，不会阻塞
+func (r *Reporter) Report(status string, shedding bool, message string) {
+	r.mu.Lock()
+	changed := r.desired == nil || r.desired.Status != status || r.desired.Shedding != shedding
+	if changed {
+		r.desired = &State{Status: status, Shedding: shedding, Message: message}
+	}
+	r.mu.Unlock()
+
+	if changed {
+		select {
+		case r.trigger <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run 将状态写入Pod直到ctx结束，失败时定期重试
+func (r *Reporter) Run(ctx context.Context) {
+	retry := time.NewTimer(retryInterval)
+	retry.Stop()
+	resync := time.NewTicker(resyncInterval)
+	defer resync.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			retry.Stop()
+			return
+		case <-r.trigger:
+		case <-retry.C:
+		case <-resync.C:
+			r.applied = nil
+		}
+
+		r.mu.Lock()
+		desired := r.desired
+		r.mu.Unlock()
+		if desired == nil || (r.applied != nil && *r.applied == *desired) {
+			continue
+		}
+
+		if err := r.apply(ctx, desired); err != nil {
+			statusLog.WithError(err).Warn(i18n.T("podstatus.update_failed"))
+			retry.Reset(retryInterval)
+			continue
+		}
+		r.applied = desired
+	}
+}
+
+// apply 写入状态条件和标签
+func (r *Reporter) apply(ctx context.Context, state *State) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if r.condition {
+		if r.conditionShedding == nil || *r.conditionShedding != state.Shedding {
+			r.lastTransition = metav1.Now()
+			shedding := state.Shedding
+			r.conditionShedding = &shedding
+		}
+		if err := r.client.PatchPodCondition(ctx, BuildCondition(state, r.lastTransition)); err != nil {
+			return err
+		}
+	}
+
+	if r.labelKey != "" {
+		if err := r.client.PatchPodLabels(ctx, map[string]string{r.labelKey: state.Status}); err != nil {
+			return err
+		}
+	}
+
+	statusLog.WithFields(logrus.Fields{
+		"status":   state.Status,
+		"shedding": state.Shedding,
+	}).Info(i18n.T("podstatus.updated"))
+	return nil
+}
+
+// BuildCondition 根据状态构造ConditionNotOverloaded，卸载流量时为False
+func BuildCondition(state *State, lastTransition metav1.Time) corev1.PodCondition {
+	status := corev1.ConditionTrue
+	if state.Shedding {
+		status = corev1.ConditionFalse
+	}
+	return corev1.PodCondition{
+		Type:               ConditionNotOverloaded,
+		Status:             status,
+		Reason:             conditionReason(state.Status),
+		Message:            state.Message,
+		LastProbeTime:      metav1.Now(),
+		LastTransitionTime: lastTransition,
+	}
+}
+
+// conditionReason 将状态转换为Kubernetes惯用的驼峰格式，如RESOURCE_EXHAUSTED -> ResourceExhausted
+func conditionReason(status string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(strings.ToLower(status), "_") {
+		if word == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]))
+		b.WriteString(word[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package podstatus
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConditionReason(t *testing.T) {
+	tests := map[string]string{
+		"HEALTHY":                         "Healthy",
+		"RESOURCE_EXHAUSTED":              "ResourceExhausted",
+		"RESOURCE_OVERLOADED_BUT_KEEPING": "ResourceOverloadedButKeeping",
+	}
+	for status, expected := range tests {
+		if got := conditionReason(status); got != expected {
+			t.Errorf("conditionReason(%s) = %s; 期望 %s", status, got, expected)
+		}
+	}
+}
+
+func TestBuildCondition(t *testing.T) {
+	transition := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	shedding := BuildCondition(&State{Status: "RESOURCE_EXHAUSTED", Shedding: true, Message: "资源使用率过高"}, transition)
+	if shedding.Type != ConditionNotOverloaded || shedding.Status != corev1.ConditionFalse {
+		t.Errorf("卸载流量时的状态条件 = %s/%s; 期望 %s/False", shedding.Type, shedding.Status, ConditionNotOverloaded)
+	}
+	if shedding.Reason != "ResourceExhausted" || !shedding.LastTransitionTime.Equal(&transition) {
+		t.Errorf("状态条件 = %+v; 期望原因为ResourceExhausted并使用给定的切换时间", shedding)
+	}
+
+	keeping := BuildCondition(&State{Status: "RESOURCE_OVERLOADED_BUT_KEEPING"}, transition)
+	if keeping.Status != corev1.ConditionTrue {
+		t.Errorf("过载但保持服务时的状态条件 = %s; 期望 True", keeping.Status)
+	}
+}
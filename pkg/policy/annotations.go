@@ -0,0 +1,182 @@
+package policy
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/k8s"
+	"metrics-sidecar/pkg/logger"
+)
+
+var (
+	// 策略来源的日志器
+	policyLog = logger.GetLogger("policy")
+)
+
+// Pod注解，用于按工作负载调整阈值和策略
+const (
+	AnnotationPrefix                  = "metrics-sidecar.io/"
+	AnnotationCPUThreshold            = AnnotationPrefix + "cpu-threshold"             // CPU百分比阈值，如"80"或"80%"
+	AnnotationMemoryThreshold         = AnnotationPrefix + "memory-threshold"          // 内存百分比阈值
+	AnnotationCPUThresholdAbsolute    = AnnotationPrefix + "cpu-threshold-absolute"    // CPU绝对阈值，如"1800m"
+	AnnotationMemoryThresholdAbsolute = AnnotationPrefix + "memory-threshold-absolute" // 内存绝对阈值，如"3.5Gi"
+	AnnotationOverloadPolicy          = AnnotationPrefix + "overload-policy"           // 过载判定策略 (all, any)
+	AnnotationMinimumPodsPercent      = AnnotationPrefix + "minimum-pods-percent"      // 最小可用Pod百分比和随机退避阈值
+)
+
+// 由sidecar注入器读取和写入的注解，策略来源会忽略它们
+const (
+	AnnotationInject           = AnnotationPrefix + "inject"     // 设置为"true"时注入sidecar
+	AnnotationInjectContainer  = AnnotationPrefix + "container"  // 被监控的容器名称，默认为第一个容器
+	AnnotationInjectDeployment = AnnotationPrefix + "deployment" // 所属Deployment名称，默认根据ReplicaSet推断
+	AnnotationInjectStatus     = AnnotationPrefix + "injected"   // 注入器写入的注入状态
+	// 设置为"true"时通过readinessGates控制就绪状态，不改写主容器的就绪探针
+	AnnotationInjectReadinessGate = AnnotationPrefix + "readiness-gate"
+)
+
+// OverlayAnnotations Pod注解覆盖层的名称
+const OverlayAnnotations = "annotations"
+
+// ParseAnnotations 从Pod注解中解析配置覆盖，返回所有无效注解的问题描述。
+// 无效和未知的注解被跳过，返回的覆盖只包含有效的注解
+func ParseAnnotations(annotations map[string]string) (*Overrides, []string) {
+	overrides := &Overrides{}
+	var problems []string
+
+	parsePercent := func(key string, dst **float64) {
+		value, ok := annotations[key]
+		if !ok {
+			return
+		}
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "%"), 64)
+		if err != nil {
+			problems = append(problems, i18n.T("policy.annotation_percent", key, value))
+			return
+		}
+		*dst = &percent
+	}
+
+	parseQuantity := func(key string, dst **int64, convert func(resource.Quantity) int64) {
+		value, ok := annotations[key]
+		if !ok {
+			return
+		}
+		quantity, err := resource.ParseQuantity(strings.TrimSpace(value))
+		if err != nil {
+			problems = append(problems, i18n.T("policy.annotation_quantity", key, value, err))
+			return
+		}
+		converted := convert(quantity)
+		*dst = &converted
+	}
+
+	parsePercent(AnnotationCPUThreshold, &overrides.ResourceThresholdCPUPercent)
+	parsePercent(AnnotationMemoryThreshold, &overrides.ResourceThresholdMemoryPercent)
+	parsePercent(AnnotationMinimumPodsPercent, &overrides.MinimumPodsToKeepPercent)
+	parseQuantity(AnnotationCPUThresholdAbsolute, &overrides.ResourceThresholdCPUMillicores, func(q resource.Quantity) int64 {
+		return q.MilliValue()
+	})
+	parseQuantity(AnnotationMemoryThresholdAbsolute, &overrides.ResourceThresholdMemoryMB, func(q resource.Quantity) int64 {
+		return q.Value() / (1024 * 1024)
+	})
+
+	if value, ok := annotations[AnnotationOverloadPolicy]; ok {
+		policy := strings.ToLower(strings.TrimSpace(value))
+		overrides.OverloadPolicy = &policy
+	}
+
+	// 提示使用了前缀但无法识别的注解，通常是拼写错误
+	var unknown []string
+	for key := range annotations {
+		if strings.HasPrefix(key, AnnotationPrefix) && !isPolicyAnnotation(key) && !isInjectorAnnotation(key) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		problems = append(problems, i18n.T("policy.annotation_unknown", key))
+	}
+
+	return overrides, problems
+}
+
+// isPolicyAnnotation 判断是否为策略相关的注解
+func isPolicyAnnotation(key string) bool {
+	switch key {
+	case AnnotationCPUThreshold, AnnotationMemoryThreshold,
+		AnnotationCPUThresholdAbsolute, AnnotationMemoryThresholdAbsolute,
+		AnnotationOverloadPolicy, AnnotationMinimumPodsPercent:
+		return true
+	}
+	return false
+}
+
+// isInjectorAnnotation 判断是否为注入器使用的注解
+func isInjectorAnnotation(key string) bool {
+	switch key {
+	case AnnotationInject, AnnotationInjectContainer, AnnotationInjectDeployment, AnnotationInjectStatus,
+		AnnotationInjectReadinessGate:
+		return true
+	}
+	return false
+}
+
+// AnnotationSource 监听当前Pod的注解，将其作为覆盖层应用到分层配置上
+type AnnotationSource struct {
+	client *k8s.Client
+	layers *Layered
+	seen   map[string]string // 上一次处理过的策略注解，Pod的其他变化不会触发重新解析
+}
+
+// NewAnnotationSource 创建Pod注解策略来源
+func NewAnnotationSource(client *k8s.Client, layers *Layered) *AnnotationSource {
+	return &AnnotationSource{
+		client: client,
+		layers: layers,
+	}
+}
+
+// Run 监听Pod变化直到ctx结束
+func (s *AnnotationSource) Run(ctx context.Context) {
+	s.client.WatchPod(ctx, s.onPodUpdate)
+}
+
+// onPodUpdate 在Pod更新时重新解析注解，仅在策略注解发生变化时处理
+func (s *AnnotationSource) onPodUpdate(pod *corev1.Pod) {
+	annotations := make(map[string]string)
+	for key, value := range pod.Annotations {
+		if strings.HasPrefix(key, AnnotationPrefix) {
+			annotations[key] = value
+		}
+	}
+	if s.seen != nil && reflect.DeepEqual(annotations, s.seen) {
+		return
+	}
+	s.seen = annotations
+
+	overrides, problems := ParseAnnotations(annotations)
+
+	// 无效的注解只记录警告，其余有效的注解仍然生效
+	if len(problems) > 0 {
+		policyLog.WithField("problems", strings.Join(problems, "; ")).
+			Warn(i18n.T("policy.annotations_partially_invalid"))
+	}
+
+	if err := s.layers.SetOverrides(OverlayAnnotations, overrides); err != nil {
+		policyLog.WithError(err).Warn(i18n.T("policy.annotations_invalid"))
+		return
+	}
+
+	policyLog.WithFields(logrus.Fields{
+		"pod":       pod.Name,
+		"overrides": overrides.String(),
+	}).Info(i18n.T("policy.annotations_applied"))
+}
@@ -0,0 +1,102 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"metrics-sidecar/pkg/config"
+)
+
+// 基础配置，内存使用绝对阈值
+func baseConfig() *config.Config {
+	return &config.Config{
+		Namespace:                      "test-namespace",
+		DeploymentName:                 "test-deployment",
+		ContainerName:                  "test-container",
+		PodName:                        "test-pod",
+		ResourceThresholdMemoryPercent: 80.0,
+		ResourceThresholdCPUPercent:    80.0,
+		ResourceThresholdMemoryMB:      2048,
+		OverloadPolicy:                 config.OverloadPolicyAll,
+		MinimumPodsToKeepPercent:       50.0,
+		CPUBasis:                       config.BasisLimit,
+		MemoryBasis:                    config.BasisLimit,
+		EvaluationInterval:             10 * time.Second,
+		HttpPort:                       "8333",
+		LogLevel:                       "info",
+		LogFormat:                      config.LogFormatText,
+		LogTimestampFormat:             "2006-01-02 15:04:05.000",
+	}
+}
+
+func TestParseAnnotations(t *testing.T) {
+	overrides, problems := ParseAnnotations(map[string]string{
+		AnnotationCPUThreshold:         "70%",
+		AnnotationMemoryThreshold:      "75",
+		AnnotationCPUThresholdAbsolute: "1800m",
+		AnnotationOverloadPolicy:       "ANY",
+		AnnotationMinimumPodsPercent:   "30",
+		"app.kubernetes.io/name":       "ignored",
+	})
+	if len(problems) != 0 {
+		t.Fatalf("ParseAnnotations返回问题: %v", problems)
+	}
+
+	cfg := overrides.Apply(baseConfig())
+	if cfg.ResourceThresholdCPUPercent != 70 || cfg.ResourceThresholdCPUMillicores != 1800 {
+		t.Errorf("CPU阈值 = %.2f%%/%dm; 期望 70%%/1800m", cfg.ResourceThresholdCPUPercent, cfg.ResourceThresholdCPUMillicores)
+	}
+	// 注解只设置内存百分比阈值时，基础配置中的内存绝对阈值被清除
+	if cfg.ResourceThresholdMemoryPercent != 75 || cfg.ResourceThresholdMemoryMB != 0 {
+		t.Errorf("内存阈值 = %.2f%%/%dMB; 期望 75%%/0MB", cfg.ResourceThresholdMemoryPercent, cfg.ResourceThresholdMemoryMB)
+	}
+	if cfg.OverloadPolicy != config.OverloadPolicyAny || cfg.MinimumPodsToKeepPercent != 30 {
+		t.Errorf("策略 = %s/%.2f; 期望 any/30", cfg.OverloadPolicy, cfg.MinimumPodsToKeepPercent)
+	}
+
+	overrides, problems = ParseAnnotations(map[string]string{
+		AnnotationCPUThreshold:            "high",
+		AnnotationMemoryThresholdAbsolute: "lots",
+		AnnotationPrefix + "cpu-treshold": "80",
+		AnnotationMemoryThreshold:         "65",
+	})
+	if len(problems) != 3 {
+		t.Errorf("ParseAnnotations发现%d个问题; 期望 3: %v", len(problems), problems)
+	}
+	// 无效和未知的注解被跳过，有效的注解仍然返回
+	if overrides.String() != "memoryPercent=65.00" {
+		t.Errorf("覆盖 = %s; 期望只包含 memoryPercent=65.00", overrides)
+	}
+}
+
+func TestLayeredRejectsInvalidOverrides(t *testing.T) {
+	var applied *config.Config
+	layers := NewLayered(baseConfig(), func(effective *config.Config) {
+		applied = effective
+	}, OverlayAnnotations)
+
+	valid := 60.0
+	if err := layers.SetOverrides(OverlayAnnotations, &Overrides{ResourceThresholdCPUPercent: &valid}); err != nil {
+		t.Fatalf("SetOverrides返回错误: %v", err)
+	}
+	if applied == nil || applied.ResourceThresholdCPUPercent != 60 {
+		t.Fatalf("生效配置未应用覆盖: %+v", applied)
+	}
+
+	// 超出范围的覆盖被拒绝，保留上一次有效的覆盖
+	invalid := 150.0
+	if err := layers.SetOverrides(OverlayAnnotations, &Overrides{ResourceThresholdCPUPercent: &invalid}); err == nil {
+		t.Error("SetOverrides对无效覆盖应返回错误")
+	}
+	if got := layers.Effective().ResourceThresholdCPUPercent; got != 60 {
+		t.Errorf("ResourceThresholdCPUPercent = %.2f; 期望保留 60", got)
+	}
+
+	// 基础配置变化后覆盖层仍然生效
+	base := baseConfig()
+	base.ResourceThresholdMemoryPercent = 90
+	layers.SetConfig(base)
+	if applied.ResourceThresholdMemoryPercent != 90 || applied.ResourceThresholdCPUPercent != 60 {
+		t.Errorf("生效配置 = %.2f/%.2f; 期望内存90、CPU60", applied.ResourceThresholdMemoryPercent, applied.ResourceThresholdCPUPercent)
+	}
+}
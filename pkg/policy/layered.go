@@ -0,0 +1,80 @@
+package policy
+
+import (
+	"sync"
+
+	"metrics-sidecar/pkg/config"
+	"metrics-sidecar/pkg/i18n"
+)
+
+// Layered 按优先级组合基础配置（配置文件和环境变量）与多个覆盖层，
+// 任一层变化时重新计算生效配置并交给apply
+type Layered struct {
+	mu       sync.Mutex
+	base     *config.Config
+	order    []string // 覆盖层的优先级顺序，越靠后优先级越高
+	overlays map[string]*Overrides
+	apply    func(effective *config.Config)
+}
+
+// NewLayered 创建分层配置，order为覆盖层名称，按优先级从低到高排列
+func NewLayered(base *config.Config, apply func(effective *config.Config), order ...string) *Layered {
+	return &Layered{
+		base:     base,
+		order:    order,
+		overlays: make(map[string]*Overrides),
+		apply:    apply,
+	}
+}
+
+// CurrentConfig 返回基础配置，供配置文件热更新使用
+func (l *Layered) CurrentConfig() *config.Config {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.base
+}
+
+// SetConfig 替换基础配置并重新计算生效配置
+func (l *Layered) SetConfig(cfg *config.Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.base = cfg
+	l.apply(l.effective())
+}
+
+// SetOverrides 替换指定覆盖层，合并后的配置校验失败时拒绝本次更新并保留原有覆盖
+func (l *Layered) SetOverrides(name string, overrides *Overrides) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	previous, existed := l.overlays[name]
+	l.overlays[name] = overrides
+	effective := l.effective()
+	if err := effective.Validate(); err != nil {
+		if existed {
+			l.overlays[name] = previous
+		} else {
+			delete(l.overlays, name)
+		}
+		return i18n.Errorf("policy.layer_invalid", name, err)
+	}
+
+	l.apply(effective)
+	return nil
+}
+
+// Effective 返回当前生效的配置
+func (l *Layered) Effective() *config.Config {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.effective()
+}
+
+// effective 按优先级依次应用覆盖层，调用方需持有锁
+func (l *Layered) effective() *config.Config {
+	cfg := l.base
+	for _, name := range l.order {
+		cfg = l.overlays[name].Apply(cfg)
+	}
+	return cfg
+}
@@ -0,0 +1,83 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"metrics-sidecar/pkg/config"
+	"metrics-sidecar/pkg/i18n"
+)
+
+// Overrides 来自Pod注解等外部来源的配置覆盖，nil字段表示不覆盖
+type Overrides struct {
+	ResourceThresholdMemoryPercent *float64
+	ResourceThresholdCPUPercent    *float64
+	ResourceThresholdMemoryMB      *int64
+	ResourceThresholdCPUMillicores *int64
+	OverloadPolicy                 *string
+	MinimumPodsToKeepPercent       *float64
+}
+
+// IsEmpty 判断是否没有任何覆盖
+func (o *Overrides) IsEmpty() bool {
+	return o == nil || *o == Overrides{}
+}
+
+// Apply 返回应用了覆盖后的配置副本。
+// 只覆盖某个资源的百分比阈值时会清除该资源的绝对阈值，保证覆盖层的意图生效
+func (o *Overrides) Apply(c *config.Config) *config.Config {
+	updated := *c
+	if o == nil {
+		return &updated
+	}
+
+	if o.ResourceThresholdMemoryPercent != nil {
+		updated.ResourceThresholdMemoryPercent = *o.ResourceThresholdMemoryPercent
+		updated.ResourceThresholdMemoryMB = 0
+	}
+	if o.ResourceThresholdMemoryMB != nil {
+		updated.ResourceThresholdMemoryMB = *o.ResourceThresholdMemoryMB
+	}
+	if o.ResourceThresholdCPUPercent != nil {
+		updated.ResourceThresholdCPUPercent = *o.ResourceThresholdCPUPercent
+		updated.ResourceThresholdCPUMillicores = 0
+	}
+	if o.ResourceThresholdCPUMillicores != nil {
+		updated.ResourceThresholdCPUMillicores = *o.ResourceThresholdCPUMillicores
+	}
+	if o.OverloadPolicy != nil {
+		updated.OverloadPolicy = *o.OverloadPolicy
+	}
+	if o.MinimumPodsToKeepPercent != nil {
+		updated.MinimumPodsToKeepPercent = *o.MinimumPodsToKeepPercent
+	}
+	return &updated
+}
+
+// String 返回覆盖内容的可读描述，用于日志
+func (o *Overrides) String() string {
+	if o.IsEmpty() {
+		return i18n.T("common.none")
+	}
+
+	var parts []string
+	if o.ResourceThresholdMemoryPercent != nil {
+		parts = append(parts, fmt.Sprintf("memoryPercent=%.2f", *o.ResourceThresholdMemoryPercent))
+	}
+	if o.ResourceThresholdMemoryMB != nil {
+		parts = append(parts, fmt.Sprintf("memory=%dMB", *o.ResourceThresholdMemoryMB))
+	}
+	if o.ResourceThresholdCPUPercent != nil {
+		parts = append(parts, fmt.Sprintf("cpuPercent=%.2f", *o.ResourceThresholdCPUPercent))
+	}
+	if o.ResourceThresholdCPUMillicores != nil {
+		parts = append(parts, fmt.Sprintf("cpu=%dm", *o.ResourceThresholdCPUMillicores))
+	}
+	if o.OverloadPolicy != nil {
+		parts = append(parts, fmt.Sprintf("overload=%s", *o.OverloadPolicy))
+	}
+	if o.MinimumPodsToKeepPercent != nil {
+		parts = append(parts, fmt.Sprintf("minimumPodsToKeepPercent=%.2f", *o.MinimumPodsToKeepPercent))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,165 @@
+package policy
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"metrics-sidecar/pkg/i18n"
+)
+
+// SheddingPolicyGVR SheddingPolicy自定义资源的GroupVersionResource
+var SheddingPolicyGVR = schema.GroupVersionResource{
+	Group:    "metrics-sidecar.io",
+	Version:  "v1alpha1",
+	Resource: "sheddingpolicies",
+}
+
+// OverlaySheddingPolicy SheddingPolicy覆盖层的名称
+const OverlaySheddingPolicy = "sheddingpolicy"
+
+// SheddingPolicy 按标签选择器为一组Pod设置阈值和策略的自定义资源
+type SheddingPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SheddingPolicySpec   `json:"spec"`
+	Status SheddingPolicyStatus `json:"status,omitempty"`
+}
+
+// SheddingPolicySpec 策略内容，未设置的字段沿用配置文件和环境变量中的值
+type SheddingPolicySpec struct {
+	// 选择Pod的标签选择器，未设置时不匹配任何Pod，{}匹配命名空间内所有Pod
+	Selector   *metav1.LabelSelector     `json:"selector,omitempty"`
+	Thresholds *SheddingPolicyThresholds `json:"thresholds,omitempty"`
+	Policy     *SheddingPolicyRules      `json:"policy,omitempty"`
+}
+
+// SheddingPolicyThresholds 资源阈值，cpu和memory为Kubernetes数量语法的绝对阈值
+type SheddingPolicyThresholds struct {
+	MemoryPercent *float64 `json:"memoryPercent,omitempty"`
+	CPUPercent    *float64 `json:"cpuPercent,omitempty"`
+	Memory        string   `json:"memory,omitempty"`
+	CPU           string   `json:"cpu,omitempty"`
+}
+
+// SheddingPolicyRules 过载判定与随机退避策略
+type SheddingPolicyRules struct {
+	Overload                 string   `json:"overload,omitempty"`
+	MinimumPodsToKeepPercent *float64 `json:"minimumPodsToKeepPercent,omitempty"`
+}
+
+// SheddingPolicyStatus 由各个边车上报的策略状态，每个边车只写入自身Pod的条目，
+// 并在写入时清理失效的条目、按清理后的条目更新计数
+type SheddingPolicyStatus struct {
+	MatchedPods  int                         `json:"matchedPods"`
+	SheddingPods int                         `json:"sheddingPods"`
+	Pods         map[string]PodSheddingState `json:"pods,omitempty"`
+}
+
+// PodSheddingState 单个Pod最近一次健康检查的判定结果
+type PodSheddingState struct {
+	Status         string      `json:"status"`
+	Shedding       bool        `json:"shedding"`
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+}
+
+// SheddingPolicyFromUnstructured 将Dynamic客户端返回的对象转换为SheddingPolicy
+func SheddingPolicyFromUnstructured(obj *unstructured.Unstructured) (*SheddingPolicy, error) {
+	policy := &SheddingPolicy{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), policy); err != nil {
+		return nil, i18n.Errorf("policy.parse_failed", obj.GetName(), err)
+	}
+	return policy, nil
+}
+
+// Matches 判断策略是否选中了带有给定标签的Pod
+func (p *SheddingPolicy) Matches(podLabels map[string]string) (bool, error) {
+	if p.Spec.Selector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(p.Spec.Selector)
+	if err != nil {
+		return false, i18n.Errorf("policy.invalid_selector", p.Name, err)
+	}
+	return selector.Matches(labels.Set(podLabels)), nil
+}
+
+// Overrides 将策略内容转换为配置覆盖，返回所有无效字段的问题描述
+func (p *SheddingPolicy) Overrides() (*Overrides, []string) {
+	overrides := &Overrides{}
+	var problems []string
+
+	parseQuantity := func(field, value string, dst **int64, convert func(resource.Quantity) int64) {
+		if value == "" {
+			return
+		}
+		quantity, err := resource.ParseQuantity(strings.TrimSpace(value))
+		if err != nil {
+			problems = append(problems, i18n.T("common.invalid_quantity", field, value, err))
+			return
+		}
+		converted := convert(quantity)
+		*dst = &converted
+	}
+
+	if t := p.Spec.Thresholds; t != nil {
+		overrides.ResourceThresholdMemoryPercent = t.MemoryPercent
+		overrides.ResourceThresholdCPUPercent = t.CPUPercent
+		parseQuantity("spec.thresholds.memory", t.Memory, &overrides.ResourceThresholdMemoryMB, func(q resource.Quantity) int64 {
+			return q.Value() / (1024 * 1024)
+		})
+		parseQuantity("spec.thresholds.cpu", t.CPU, &overrides.ResourceThresholdCPUMillicores, func(q resource.Quantity) int64 {
+			return q.MilliValue()
+		})
+	}
+
+	if r := p.Spec.Policy; r != nil {
+		if r.Overload != "" {
+			policy := strings.ToLower(strings.TrimSpace(r.Overload))
+			overrides.OverloadPolicy = &policy
+		}
+		overrides.MinimumPodsToKeepPercent = r.MinimumPodsToKeepPercent
+	}
+
+	return overrides, problems
+}
+
+// SelectPolicy 从多个策略中选出作用于当前Pod的一个。
+// 多个策略同时匹配时选择最早创建的（创建时间相同时按名称），保证所有副本的选择一致。
+// 返回值matched为所有匹配的策略名称，problems为无法判断是否匹配的策略
+func SelectPolicy(policies []*SheddingPolicy, podLabels map[string]string) (selected *SheddingPolicy, matched []string, problems []string) {
+	var candidates []*SheddingPolicy
+	for _, p := range policies {
+		ok, err := p.Matches(podLabels)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if ok {
+			candidates = append(candidates, p)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		ti, tj := candidates[i].CreationTimestamp, candidates[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	for _, p := range candidates {
+		matched = append(matched, p.Name)
+	}
+	if len(candidates) > 0 {
+		selected = candidates[0]
+	}
+	return selected, matched, problems
+}
@@ -0,0 +1,409 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/k8s"
+)
+
+const (
+	// SheddingPolicy Informer的全量同步周期
+	sheddingPolicyResyncPeriod = 10 * time.Minute
+	// 退出时从策略状态中移除当前Pod的超时时间
+	statusRemoveTimeout = 5 * time.Second
+	// 判定结果未变化时也按该间隔刷新当前Pod条目的lastUpdateTime，使其他边车可以识别失效的条目
+	statusHeartbeatInterval = 5 * time.Minute
+	// 条目错过心跳超过该时间后检查其Pod是否仍然存在
+	statusHeartbeatTolerance = time.Minute
+	// 条目超过该时间未刷新时视为失效，无论Pod是否存在
+	statusStaleAfter = 3 * statusHeartbeatInterval
+)
+
+// SheddingPolicySource 监听命名空间中的SheddingPolicy，将匹配当前Pod的策略作为覆盖层应用，
+// 并把当前Pod的判定结果上报到策略的status.pods中。每个边车只写入自身的条目，
+// 同时删除被OOM杀死、驱逐或随节点丢失的Pod留下的失效条目，并更新matchedPods和sheddingPods
+type SheddingPolicySource struct {
+	client  *k8s.Client
+	layers  *Layered
+	trigger chan struct{}
+
+	mu        sync.Mutex
+	podLabels map[string]string // 当前Pod的标签，nil表示尚未获取
+	state     *PodSheddingState // 最近一次判定结果，nil表示尚未判定
+
+	// 以下字段仅在Run的goroutine中访问
+	appliedKey     string // 已应用策略的名称和generation，避免status变化时重复应用
+	matchKey       string // 上一次的匹配结果，仅在变化时输出日志
+	reportedPolicy string // 已上报过状态的策略名称
+}
+
+// NewSheddingPolicySource 创建SheddingPolicy策略来源
+func NewSheddingPolicySource(client *k8s.Client, layers *Layered) *SheddingPolicySource {
+	return &SheddingPolicySource{
+		client:  client,
+		layers:  layers,
+		trigger: make(chan struct{}, 1),
+	}
+}
+
+// ReportStatus 记录最近一次判定结果，由健康检查在判定后调用，不会阻塞
+func (s *SheddingPolicySource) ReportStatus(status string, shedding bool) {
+	s.mu.Lock()
+	changed := s.state == nil || s.state.Status != status || s.state.Shedding != shedding
+	if changed {
+		s.state = &PodSheddingState{
+			Status:         status,
+			Shedding:       shedding,
+			LastUpdateTime: metav1.Now().Rfc3339Copy(),
+		}
+	}
+	s.mu.Unlock()
+
+	if changed {
+		s.notify()
+	}
+}
+
+// Run 监听SheddingPolicy和当前Pod的变化直到ctx结束
+func (s *SheddingPolicySource) Run(ctx context.Context) {
+	namespace := s.client.Config.Namespace
+
+	// 未安装CRD或没有权限时，Informer会不断重试并刷屏，提前检查后放弃
+	if _, err := s.client.DynamicClient.Resource(SheddingPolicyGVR).Namespace(namespace).
+		List(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+		policyLog.WithError(err).Warn(i18n.T("policy.crd_unavailable"))
+		return
+	}
+
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+		s.client.DynamicClient, sheddingPolicyResyncPeriod, namespace, nil)
+	informer := factory.ForResource(SheddingPolicyGVR).Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(interface{}) { s.notify() },
+		// 各边车上报状态只改变status，不影响策略选择，跳过以免互相触发同步
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if specChanged(oldObj, newObj) {
+				s.notify()
+			}
+		},
+		DeleteFunc: func(interface{}) { s.notify() },
+	})
+
+	go s.client.WatchPod(ctx, s.onPodUpdate)
+
+	policyLog.WithField("namespace", namespace).Info(i18n.T("policy.watch_started"))
+	factory.Start(ctx.Done())
+	defer factory.Shutdown()
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return
+	}
+
+	store := informer.GetStore()
+	defer s.removeStatus(store)
+	heartbeat := time.NewTicker(statusHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		s.sync(ctx, store)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.trigger:
+		case <-heartbeat.C:
+			s.refreshState()
+		}
+	}
+}
+
+// refreshState 刷新最近一次判定结果的时间，下一次同步时写入策略状态作为心跳
+func (s *SheddingPolicySource) refreshState() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != nil {
+		refreshed := *s.state
+		refreshed.LastUpdateTime = metav1.Now().Rfc3339Copy()
+		s.state = &refreshed
+	}
+}
+
+// specChanged 判断策略的spec是否变化。启用status子资源后，只有spec的变化会增加generation
+func specChanged(oldObj, newObj interface{}) bool {
+	oldPolicy, ok := oldObj.(*unstructured.Unstructured)
+	if !ok {
+		return true
+	}
+	newPolicy, ok := newObj.(*unstructured.Unstructured)
+	if !ok {
+		return true
+	}
+	return oldPolicy.GetGeneration() != newPolicy.GetGeneration()
+}
+
+// notify 请求一次同步，已有未处理的请求时直接返回
+func (s *SheddingPolicySource) notify() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// onPodUpdate 记录当前Pod的标签，标签变化时重新选择策略
+func (s *SheddingPolicySource) onPodUpdate(pod *corev1.Pod) {
+	podLabels := make(map[string]string, len(pod.Labels))
+	for key, value := range pod.Labels {
+		podLabels[key] = value
+	}
+
+	s.mu.Lock()
+	changed := s.podLabels == nil || !reflect.DeepEqual(s.podLabels, podLabels)
+	s.podLabels = podLabels
+	s.mu.Unlock()
+
+	if changed {
+		s.notify()
+	}
+}
+
+// sync 重新选择匹配当前Pod的策略，应用其覆盖并上报状态
+func (s *SheddingPolicySource) sync(ctx context.Context, store cache.Store) {
+	s.mu.Lock()
+	podLabels, state := s.podLabels, s.state
+	s.mu.Unlock()
+	if podLabels == nil {
+		return
+	}
+
+	var policies []*SheddingPolicy
+	for _, obj := range store.List() {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		p, err := SheddingPolicyFromUnstructured(u)
+		if err != nil {
+			policyLog.WithError(err).Warn(i18n.T("policy.unparsable"))
+			continue
+		}
+		policies = append(policies, p)
+	}
+
+	selected, matched, problems := SelectPolicy(policies, podLabels)
+	if key := strings.Join(matched, ",") + "|" + strings.Join(problems, ";"); key != s.matchKey {
+		s.matchKey = key
+		if len(problems) > 0 {
+			policyLog.WithField("problems", strings.Join(problems, "; ")).Warn(i18n.T("policy.some_invalid"))
+		}
+		if len(matched) > 1 {
+			policyLog.WithFields(logrus.Fields{
+				"matched":  strings.Join(matched, ", "),
+				"selected": selected.Name,
+			}).Warn(i18n.T("policy.multiple_matched"))
+		}
+	}
+
+	s.applyPolicy(selected)
+	s.reportStatus(ctx, store, selected, state)
+}
+
+// applyPolicy 将选中的策略应用为覆盖层，策略内容未变化时跳过
+func (s *SheddingPolicySource) applyPolicy(selected *SheddingPolicy) {
+	key := ""
+	if selected != nil {
+		key = fmt.Sprintf("%s/%d", selected.Name, selected.Generation)
+	}
+	if key == s.appliedKey {
+		return
+	}
+	s.appliedKey = key
+
+	if selected == nil {
+		if err := s.layers.SetOverrides(OverlaySheddingPolicy, nil); err != nil {
+			policyLog.WithError(err).Warn(i18n.T("policy.remove_failed"))
+			return
+		}
+		policyLog.Info(i18n.T("policy.none_matched"))
+		return
+	}
+
+	log := policyLog.WithField("sheddingPolicy", selected.Name)
+	overrides, problems := selected.Overrides()
+	if len(problems) > 0 {
+		log.WithField("problems", strings.Join(problems, "; ")).
+			Warn(i18n.T("policy.invalid"))
+		return
+	}
+	if err := s.layers.SetOverrides(OverlaySheddingPolicy, overrides); err != nil {
+		log.WithError(err).Warn(i18n.T("policy.invalid"))
+		return
+	}
+	log.WithField("overrides", overrides.String()).Info(i18n.T("policy.applied"))
+}
+
+// reportStatus 将当前Pod的判定结果写入选中策略的status，并从不再匹配的策略中移除
+func (s *SheddingPolicySource) reportStatus(ctx context.Context, store cache.Store, selected *SheddingPolicy, state *PodSheddingState) {
+	podName := s.client.Config.PodName
+	if s.reportedPolicy != "" && (selected == nil || selected.Name != s.reportedPolicy) {
+		s.removeFrom(ctx, store, s.reportedPolicy)
+		s.reportedPolicy = ""
+	}
+
+	if selected == nil || state == nil {
+		return
+	}
+
+	stale := staleEntries(&selected.Status, podName, time.Now(), func(name string) (bool, error) {
+		return s.podExists(ctx, name)
+	})
+	patch, changed := statusPatch(&selected.Status, podName, state, stale)
+	if !changed {
+		s.reportedPolicy = selected.Name
+		return
+	}
+	if err := s.patchStatus(ctx, selected.Name, patch); err != nil {
+		policyLog.WithError(err).WithField("sheddingPolicy", selected.Name).Warn(i18n.T("policy.status_update_failed"))
+		return
+	}
+	s.reportedPolicy = selected.Name
+}
+
+// removeStatus 退出时从已上报的策略状态中移除当前Pod，此时ctx已结束，使用独立的超时
+func (s *SheddingPolicySource) removeStatus(store cache.Store) {
+	if s.reportedPolicy == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), statusRemoveTimeout)
+	defer cancel()
+	s.removeFrom(ctx, store, s.reportedPolicy)
+}
+
+// removeFrom 从策略状态中移除当前Pod的条目并更新计数，策略已删除时忽略
+func (s *SheddingPolicySource) removeFrom(ctx context.Context, store cache.Store, name string) {
+	current := &SheddingPolicyStatus{}
+	if obj, exists, _ := store.GetByKey(s.client.Config.Namespace + "/" + name); exists {
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			if p, err := SheddingPolicyFromUnstructured(u); err == nil {
+				current = &p.Status
+			}
+		}
+	}
+	patch, changed := statusPatch(current, s.client.Config.PodName, nil, nil)
+	if !changed {
+		return
+	}
+	if err := s.patchStatus(ctx, name, patch); err != nil && !apierrors.IsNotFound(err) {
+		policyLog.WithError(err).WithField("sheddingPolicy", name).Warn(i18n.T("policy.status_remove_failed"))
+	}
+}
+
+// podExists 判断命名空间中是否存在指定名称的Pod，无法确定时返回错误
+func (s *SheddingPolicySource) podExists(ctx context.Context, name string) (bool, error) {
+	_, err := s.client.KubeClient.CoreV1().Pods(s.client.Config.Namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		return true, nil
+	case apierrors.IsNotFound(err):
+		return false, nil
+	}
+	return false, err
+}
+
+// patchStatus 以merge patch方式更新策略的status子资源
+func (s *SheddingPolicySource) patchStatus(ctx context.Context, name string, patch map[string]interface{}) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.DynamicClient.Resource(SheddingPolicyGVR).Namespace(s.client.Config.Namespace).
+		Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{}, "status")
+	return err
+}
+
+// staleEntries 返回策略状态中其他Pod的失效条目：超过statusStaleAfter未刷新，
+// 或错过心跳且Pod已不存在。无法确定Pod是否存在时保留条目
+func staleEntries(current *SheddingPolicyStatus, podName string, now time.Time, podExists func(name string) (bool, error)) []string {
+	var stale []string
+	for name, entry := range current.Pods {
+		if name == podName {
+			continue
+		}
+		age := now.Sub(entry.LastUpdateTime.Time)
+		if age > statusStaleAfter {
+			stale = append(stale, name)
+			continue
+		}
+		if age > statusHeartbeatInterval+statusHeartbeatTolerance {
+			if exists, err := podExists(name); err == nil && !exists {
+				stale = append(stale, name)
+			}
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// statusPatch 计算将当前Pod的条目设置为state（nil表示删除）并删除stale中的条目所需的merge patch，
+// 计数按更新后的条目重新计算。patch只包含变化的条目，多个边车并发上报时不会覆盖彼此的条目。
+// 与现有status一致时changed为false
+func statusPatch(current *SheddingPolicyStatus, podName string, state *PodSheddingState, stale []string) (patch map[string]interface{}, changed bool) {
+	pods := make(map[string]interface{})
+	existing, exists := current.Pods[podName]
+	switch {
+	case state == nil:
+		if exists {
+			pods[podName] = nil
+		}
+	case !exists || existing.Status != state.Status || existing.Shedding != state.Shedding ||
+		!existing.LastUpdateTime.Equal(&state.LastUpdateTime):
+		pods[podName] = *state
+	}
+	for _, name := range stale {
+		if _, exists := current.Pods[name]; exists && name != podName {
+			pods[name] = nil
+		}
+	}
+
+	// 按更新后的条目计数
+	result := make(map[string]PodSheddingState, len(current.Pods)+1)
+	for name, entry := range current.Pods {
+		result[name] = entry
+	}
+	for name, value := range pods {
+		if entry, ok := value.(PodSheddingState); ok {
+			result[name] = entry
+		} else {
+			delete(result, name)
+		}
+	}
+	shedding := 0
+	for _, entry := range result {
+		if entry.Shedding {
+			shedding++
+		}
+	}
+
+	if len(pods) == 0 && current.MatchedPods == len(result) && current.SheddingPods == shedding {
+		return nil, false
+	}
+	status := map[string]interface{}{"matchedPods": len(result), "sheddingPods": shedding}
+	if len(pods) > 0 {
+		status["pods"] = pods
+	}
+	return map[string]interface{}{"status": status}, true
+}
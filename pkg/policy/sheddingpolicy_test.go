@@ -0,0 +1,204 @@
+package policy
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// 创建带选择器的策略
+func newPolicy(name string, created time.Time, matchLabels map[string]string) *SheddingPolicy {
+	return &SheddingPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: SheddingPolicySpec{
+			Selector: &metav1.LabelSelector{MatchLabels: matchLabels},
+		},
+	}
+}
+
+func TestSheddingPolicyFromUnstructured(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metrics-sidecar.io/v1alpha1",
+		"kind":       "SheddingPolicy",
+		"metadata":   map[string]interface{}{"name": "my-app"},
+		"spec": map[string]interface{}{
+			"selector":   map[string]interface{}{"matchLabels": map[string]interface{}{"app": "my-app"}},
+			"thresholds": map[string]interface{}{"cpuPercent": int64(85), "memory": "3.5Gi"},
+			"policy":     map[string]interface{}{"overload": "ANY", "minimumPodsToKeepPercent": 60.5},
+		},
+	}}
+
+	policy, err := SheddingPolicyFromUnstructured(obj)
+	if err != nil {
+		t.Fatalf("SheddingPolicyFromUnstructured失败: %v", err)
+	}
+
+	overrides, problems := policy.Overrides()
+	if len(problems) != 0 {
+		t.Fatalf("Overrides返回问题: %v", problems)
+	}
+
+	cfg := overrides.Apply(baseConfig())
+	if cfg.ResourceThresholdCPUPercent != 85 {
+		t.Errorf("ResourceThresholdCPUPercent = %.2f; 期望 85", cfg.ResourceThresholdCPUPercent)
+	}
+	if cfg.ResourceThresholdMemoryMB != 3584 {
+		t.Errorf("ResourceThresholdMemoryMB = %d; 期望 3584", cfg.ResourceThresholdMemoryMB)
+	}
+	if cfg.OverloadPolicy != "any" {
+		t.Errorf("OverloadPolicy = %s; 期望 any", cfg.OverloadPolicy)
+	}
+	if cfg.MinimumPodsToKeepPercent != 60.5 {
+		t.Errorf("MinimumPodsToKeepPercent = %.2f; 期望 60.5", cfg.MinimumPodsToKeepPercent)
+	}
+
+	policy.Spec.Thresholds.CPU = "lots"
+	if _, problems := policy.Overrides(); len(problems) != 1 {
+		t.Errorf("无效的cpu阈值返回%d个问题; 期望 1", len(problems))
+	}
+}
+
+func TestSelectPolicy(t *testing.T) {
+	now := time.Now()
+	podLabels := map[string]string{"app": "my-app", "tier": "web"}
+
+	policies := []*SheddingPolicy{
+		newPolicy("newer", now, map[string]string{"app": "my-app"}),
+		newPolicy("other-app", now.Add(-2*time.Hour), map[string]string{"app": "other"}),
+		newPolicy("b-older", now.Add(-time.Hour), map[string]string{"tier": "web"}),
+		newPolicy("a-older", now.Add(-time.Hour), map[string]string{"app": "my-app"}),
+		{ObjectMeta: metav1.ObjectMeta{Name: "no-selector"}},
+	}
+
+	selected, matched, problems := SelectPolicy(policies, podLabels)
+	if len(problems) != 0 {
+		t.Fatalf("SelectPolicy返回问题: %v", problems)
+	}
+	if selected == nil || selected.Name != "a-older" {
+		t.Fatalf("selected = %v; 期望 a-older", selected)
+	}
+	if len(matched) != 3 {
+		t.Errorf("matched = %v; 期望3个策略", matched)
+	}
+
+	if selected, _, _ := SelectPolicy(policies, map[string]string{"app": "unknown"}); selected != nil {
+		t.Errorf("selected = %s; 期望 nil", selected.Name)
+	}
+}
+
+func TestStatusPatch(t *testing.T) {
+	updated := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	state := PodSheddingState{Status: "ResourceExhausted", Shedding: true, LastUpdateTime: updated}
+
+	current := &SheddingPolicyStatus{
+		MatchedPods:  3,
+		SheddingPods: 1,
+		Pods: map[string]PodSheddingState{
+			"pod-a": {Status: "Healthy", LastUpdateTime: updated},
+			"pod-b": {Status: "ResourceExhausted", Shedding: true, LastUpdateTime: updated},
+			"pod-c": {Status: "Healthy", LastUpdateTime: updated},
+		},
+	}
+
+	// pod-a开始卸载流量并清理失效的pod-c，patch只包含变化的条目和更新后的计数
+	patch, changed := statusPatch(current, "pod-a", &state, []string{"pod-c"})
+	if !changed {
+		t.Fatal("changed = false; 期望 true")
+	}
+	status := patch["status"].(map[string]interface{})
+	if status["matchedPods"] != 2 || status["sheddingPods"] != 2 {
+		t.Errorf("matchedPods/sheddingPods = %v/%v; 期望 2/2", status["matchedPods"], status["sheddingPods"])
+	}
+	pods := status["pods"].(map[string]interface{})
+	if len(pods) != 2 || pods["pod-a"] != state || pods["pod-c"] != nil {
+		t.Errorf("pods = %v; 期望只包含pod-a的条目和删除pod-c", pods)
+	}
+	if _, exists := pods["pod-c"]; !exists {
+		t.Error("pods中缺少删除pod-c的条目")
+	}
+
+	// 与现有状态和计数一致时不需要更新
+	current = &SheddingPolicyStatus{MatchedPods: 1, SheddingPods: 1, Pods: map[string]PodSheddingState{"pod-a": state}}
+	if _, changed := statusPatch(current, "pod-a", &state, nil); changed {
+		t.Error("changed = true; 期望 false")
+	}
+
+	// 条目一致但计数过期时只更新计数
+	current.MatchedPods = 4
+	patch, changed = statusPatch(current, "pod-a", &state, nil)
+	status = patch["status"].(map[string]interface{})
+	if !changed || status["matchedPods"] != 1 || status["pods"] != nil {
+		t.Errorf("patch = %v; 期望只更新计数", patch)
+	}
+
+	// 移除当前Pod的条目
+	patch, _ = statusPatch(current, "pod-a", nil, nil)
+	status = patch["status"].(map[string]interface{})
+	if status["matchedPods"] != 0 || status["sheddingPods"] != 0 {
+		t.Errorf("移除后matchedPods/sheddingPods = %v/%v; 期望 0/0", status["matchedPods"], status["sheddingPods"])
+	}
+}
+
+func TestStaleEntries(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	entry := func(age time.Duration) PodSheddingState {
+		return PodSheddingState{Status: "Healthy", LastUpdateTime: metav1.NewTime(now.Add(-age))}
+	}
+	current := &SheddingPolicyStatus{Pods: map[string]PodSheddingState{
+		"self":      entry(time.Hour),
+		"fresh":     entry(time.Minute),
+		"expired":   entry(statusStaleAfter + time.Second),
+		"deleted":   entry(statusHeartbeatInterval + 2*statusHeartbeatTolerance),
+		"alive":     entry(statusHeartbeatInterval + 2*statusHeartbeatTolerance),
+		"api-error": entry(statusHeartbeatInterval + 2*statusHeartbeatTolerance),
+	}}
+
+	var checked []string
+	podExists := func(name string) (bool, error) {
+		checked = append(checked, name)
+		switch name {
+		case "deleted":
+			return false, nil
+		case "api-error":
+			return false, errors.New("apiserver不可用")
+		}
+		return true, nil
+	}
+
+	stale := staleEntries(current, "self", now, podExists)
+	if strings.Join(stale, ",") != "deleted,expired" {
+		t.Errorf("staleEntries() = %v; 期望 [deleted expired]", stale)
+	}
+	// 只为错过心跳的条目查询Pod
+	sort.Strings(checked)
+	if strings.Join(checked, ",") != "alive,api-error,deleted" {
+		t.Errorf("查询了%v; 期望只查询错过心跳的条目", checked)
+	}
+}
+
+func TestSpecChanged(t *testing.T) {
+	policy := func(generation int64, shedding bool) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{"pods": map[string]interface{}{
+				"pod-a": map[string]interface{}{"shedding": shedding},
+			}},
+		}}
+		obj.SetGeneration(generation)
+		return obj
+	}
+
+	if specChanged(policy(1, false), policy(1, true)) {
+		t.Error("只有status变化时specChanged = true; 期望 false")
+	}
+	if !specChanged(policy(1, false), policy(2, false)) {
+		t.Error("generation变化时specChanged = false; 期望 true")
+	}
+}
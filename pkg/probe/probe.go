@@ -0,0 +1,204 @@
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"metrics-sidecar/pkg/i18n"
+)
+
+// 未设置timeoutSeconds时的超时时间，与kubelet的默认值一致
+const defaultTimeout = time.Second
+
+// 探针请求的User-Agent
+const userAgent = "metrics-sidecar-probe"
+
+// Spec 应用原有的就绪探针定义，字段与Kubernetes Probe保持一致。
+// 仅支持httpGet和tcpSocket，端口必须是数字
+type Spec struct {
+	HTTPGet        *HTTPGetAction   `json:"httpGet,omitempty"`
+	TCPSocket      *TCPSocketAction `json:"tcpSocket,omitempty"`
+	TimeoutSeconds int              `json:"timeoutSeconds,omitempty"`
+
+	// 以下字段使从Kubernetes直接复制的探针定义也能通过校验，但不会生效：
+	// sidecar在每次健康检查时执行一次探针，周期和阈值由kubelet对sidecar的就绪探针决定
+	InitialDelaySeconds           int    `json:"initialDelaySeconds,omitempty"`
+	PeriodSeconds                 int    `json:"periodSeconds,omitempty"`
+	SuccessThreshold              int    `json:"successThreshold,omitempty"`
+	FailureThreshold              int    `json:"failureThreshold,omitempty"`
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+}
+
+// HTTPGetAction HTTP探测，状态码在200到399之间视为成功
+type HTTPGetAction struct {
+	Host        string       `json:"host,omitempty"`
+	Path        string       `json:"path,omitempty"`
+	Port        int          `json:"port"`
+	Scheme      string       `json:"scheme,omitempty"`
+	HTTPHeaders []HTTPHeader `json:"httpHeaders,omitempty"`
+}
+
+// HTTPHeader HTTP探测请求中的自定义请求头
+type HTTPHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// TCPSocketAction TCP探测，能建立连接即视为成功
+type TCPSocketAction struct {
+	Host string `json:"host,omitempty"`
+	Port int    `json:"port"`
+}
+
+// Validate 校验探针定义，返回所有问题
+func (s *Spec) Validate(name string) []string {
+	var problems []string
+
+	switch {
+	case s.HTTPGet == nil && s.TCPSocket == nil:
+		problems = append(problems, i18n.T("probe.handler_missing", name))
+	case s.HTTPGet != nil && s.TCPSocket != nil:
+		problems = append(problems, i18n.T("probe.handler_conflict", name))
+	}
+
+	if s.HTTPGet != nil {
+		problems = append(problems, validatePort(name+".httpGet.port", s.HTTPGet.Port)...)
+		switch strings.ToUpper(s.HTTPGet.Scheme) {
+		case "", "HTTP", "HTTPS":
+		default:
+			problems = append(problems, i18n.T("probe.invalid_scheme", name, s.HTTPGet.Scheme))
+		}
+	}
+	if s.TCPSocket != nil {
+		problems = append(problems, validatePort(name+".tcpSocket.port", s.TCPSocket.Port)...)
+	}
+	if s.TimeoutSeconds < 0 {
+		problems = append(problems, i18n.T("probe.negative_timeout", name))
+	}
+	return problems
+}
+
+// validatePort 校验端口范围
+func validatePort(name string, port int) []string {
+	if port <= 0 || port > 65535 {
+		return []string{i18n.T("probe.invalid_port", name, port)}
+	}
+	return nil
+}
+
+// String 返回探针的可读描述，用于日志
+func (s *Spec) String() string {
+	switch {
+	case s.HTTPGet != nil:
+		return s.httpURL()
+	case s.TCPSocket != nil:
+		return "tcp://" + net.JoinHostPort(hostOrLocal(s.TCPSocket.Host), strconv.Itoa(s.TCPSocket.Port))
+	}
+	return i18n.T("common.none")
+}
+
+// Prober 执行应用的就绪探针。sidecar与应用容器共享网络命名空间，
+// 未指定host时探测127.0.0.1
+type Prober struct {
+	client *http.Client
+}
+
+// NewProber 创建探针执行器
+func NewProber() *Prober {
+	return &Prober{
+		client: &http.Client{
+			Transport: &http.Transport{
+				// 与kubelet一致，HTTPS探测不校验证书
+				TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+				DisableKeepAlives: true,
+			},
+		},
+	}
+}
+
+// Check 执行一次探测，成功时返回nil
+func (p *Prober) Check(ctx context.Context, spec *Spec) error {
+	timeout := defaultTimeout
+	if spec.TimeoutSeconds > 0 {
+		timeout = time.Duration(spec.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch {
+	case spec.HTTPGet != nil:
+		return p.checkHTTP(ctx, spec)
+	case spec.TCPSocket != nil:
+		return p.checkTCP(ctx, spec.TCPSocket)
+	}
+	return i18n.Errorf("probe.no_handler")
+}
+
+// checkHTTP 执行HTTP探测
+func (p *Prober) checkHTTP(ctx context.Context, spec *Spec) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, spec.httpURL(), nil)
+	if err != nil {
+		return i18n.Errorf("probe.request_failed", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	for _, header := range spec.HTTPGet.HTTPHeaders {
+		if strings.EqualFold(header.Name, "Host") {
+			req.Host = header.Value
+			continue
+		}
+		req.Header.Add(header.Name, header.Value)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return i18n.Errorf("probe.http_failed", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return i18n.Errorf("probe.http_status", resp.StatusCode)
+	}
+	return nil
+}
+
+// checkTCP 执行TCP探测
+func (p *Prober) checkTCP(ctx context.Context, action *TCPSocketAction) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(hostOrLocal(action.Host), strconv.Itoa(action.Port)))
+	if err != nil {
+		return i18n.Errorf("probe.tcp_failed", err)
+	}
+	return conn.Close()
+}
+
+// httpURL 拼接HTTP探测的地址
+func (s *Spec) httpURL() string {
+	action := s.HTTPGet
+	scheme := strings.ToLower(action.Scheme)
+	if scheme == "" {
+		scheme = "http"
+	}
+	path := action.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	u := url.URL{
+		Scheme: scheme,
+		Host:   net.JoinHostPort(hostOrLocal(action.Host), strconv.Itoa(action.Port)),
+	}
+	return u.String() + path
+}
+
+// 未指定host时探测本机
+func hostOrLocal(host string) string {
+	if host == "" {
+		return "127.0.0.1"
+	}
+	return host
+}
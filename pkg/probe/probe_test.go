@@ -0,0 +1,92 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// 返回测试服务器监听的端口
+func serverPort(t *testing.T, addr string) int {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("解析地址失败: %v", err)
+	}
+	value, _ := strconv.Atoi(port)
+	return value
+}
+
+func TestCheckHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ready" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("X-Probe") != "sidecar" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	port := serverPort(t, server.Listener.Addr().String())
+
+	prober := NewProber()
+	spec := &Spec{HTTPGet: &HTTPGetAction{
+		Path:        "ready",
+		Port:        port,
+		HTTPHeaders: []HTTPHeader{{Name: "X-Probe", Value: "sidecar"}},
+	}}
+	if err := prober.Check(context.Background(), spec); err != nil {
+		t.Errorf("Check() = %v; 期望成功", err)
+	}
+
+	spec.HTTPGet.Path = "/missing"
+	if err := prober.Check(context.Background(), spec); err == nil {
+		t.Error("返回404时Check()应失败")
+	}
+}
+
+func TestCheckTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	port := serverPort(t, listener.Addr().String())
+
+	prober := NewProber()
+	spec := &Spec{TCPSocket: &TCPSocketAction{Port: port}}
+	if err := prober.Check(context.Background(), spec); err != nil {
+		t.Errorf("Check() = %v; 期望成功", err)
+	}
+
+	listener.Close()
+	if err := prober.Check(context.Background(), spec); err == nil {
+		t.Error("端口关闭后Check()应失败")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     Spec
+		problems int
+	}{
+		{"HTTP探针", Spec{HTTPGet: &HTTPGetAction{Path: "/ready", Port: 8080}}, 0},
+		{"TCP探针", Spec{TCPSocket: &TCPSocketAction{Port: 8080}}, 0},
+		{"未设置探测方式", Spec{}, 1},
+		{"同时设置两种方式", Spec{HTTPGet: &HTTPGetAction{Port: 80}, TCPSocket: &TCPSocketAction{Port: 80}}, 1},
+		{"无效端口和协议", Spec{HTTPGet: &HTTPGetAction{Port: 0, Scheme: "FTP"}}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if problems := tt.spec.Validate("appReadinessProbe"); len(problems) != tt.problems {
+				t.Errorf("Validate()返回%d个问题: %v; 期望 %d", len(problems), problems, tt.problems)
+			}
+		})
+	}
+}
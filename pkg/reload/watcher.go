@@ -0,0 +1,146 @@
+package reload
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+
+	"metrics-sidecar/pkg/config"
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/logger"
+)
+
+var (
+	// 配置热更新的日志器
+	reloadLog = logger.GetLogger("reload")
+)
+
+// 文件事件的合并窗口，ConfigMap更新时会在短时间内产生多个事件
+const debounceInterval = 500 * time.Millisecond
+
+// Target 接收热更新配置的对象
+type Target interface {
+	CurrentConfig() *config.Config
+	SetConfig(cfg *config.Config)
+}
+
+// Watcher 监听配置文件变化，校验通过后原子替换Target的配置
+type Watcher struct {
+	path       string
+	target     Target
+	lastData   []byte         // 上一次成功加载的文件内容，用于忽略内容未变化的事件
+	lastLoaded *config.Config // 上一次成功加载的配置，用于识别不支持热更新的变化
+	lastFile   *config.Config // 上一次成功加载时只应用配置文件的结果，用于识别被环境变量覆盖的修改
+}
+
+// NewWatcher 创建配置文件监听器
+func NewWatcher(path string, target Target) *Watcher {
+	data, _ := os.ReadFile(path)
+	loaded, err := config.Load(path)
+	if err != nil {
+		loaded = target.CurrentConfig()
+	}
+	return &Watcher{
+		path:       path,
+		target:     target,
+		lastData:   data,
+		lastLoaded: loaded,
+		lastFile:   config.LoadFile(path),
+	}
+}
+
+// Run 监听配置文件所在目录直到ctx结束。
+// 监听目录而不是文件本身，以便处理ConfigMap挂载时通过..data符号链接原子替换文件的情况
+func (w *Watcher) Run(ctx context.Context) error {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return i18n.Errorf("reload.watcher_failed", err)
+	}
+	defer fsWatcher.Close()
+
+	dir := filepath.Dir(w.path)
+	if err := fsWatcher.Add(dir); err != nil {
+		return i18n.Errorf("reload.watch_dir_failed", dir, err)
+	}
+	reloadLog.WithField("path", w.path).Info(i18n.T("reload.started"))
+
+	// 合并短时间内的多个事件，只在事件平息后重新加载一次
+	debounce := time.NewTimer(debounceInterval)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return nil
+			}
+			reloadLog.WithFields(logrus.Fields{
+				"file": event.Name,
+				"op":   event.Op.String(),
+			}).Debug(i18n.T("reload.changed"))
+			debounce.Reset(debounceInterval)
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return nil
+			}
+			reloadLog.WithError(err).Warn(i18n.T("reload.watch_error"))
+		case <-debounce.C:
+			if err := w.Reload(); err != nil {
+				reloadLog.WithError(err).Error(i18n.T("reload.failed"))
+			}
+		}
+	}
+}
+
+// Reload 重新加载配置文件，校验通过后替换可热更新的字段，校验失败时保留当前配置
+func (w *Watcher) Reload() error {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return i18n.Errorf("config.read_file_failed", err)
+	}
+	if bytes.Equal(data, w.lastData) {
+		return nil
+	}
+
+	loaded, err := config.Load(w.path)
+	if err != nil {
+		return err
+	}
+	previous, previousFile := w.lastLoaded, w.lastFile
+	fileOnly := config.LoadFile(w.path)
+	w.lastData = data
+	w.lastLoaded = loaded
+	w.lastFile = fileOnly
+
+	// 环境变量在配置文件之后应用，设置了环境变量的字段在配置文件中的修改不会生效
+	for _, change := range config.EnvPinned(previousFile, fileOnly) {
+		reloadLog.WithField("change", change).Warn(i18n.T("reload.env_pinned"))
+	}
+
+	// 需要重启才能生效的字段只记录警告
+	for _, change := range config.Diff(previous.WithReloadable(loaded), loaded) {
+		reloadLog.WithField("change", change).Warn(i18n.T("reload.restart_required"))
+	}
+
+	current := w.target.CurrentConfig()
+	updated := current.WithReloadable(loaded)
+	applied := config.Diff(current, updated)
+	if len(applied) == 0 {
+		reloadLog.Info(i18n.T("reload.unchanged"))
+		return nil
+	}
+
+	w.target.SetConfig(updated)
+	for _, change := range applied {
+		reloadLog.WithField("change", change).Info(i18n.T("reload.applied"))
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package reload
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"metrics-sidecar/pkg/config"
+)
+
+// fakeTarget 记录最近一次设置的配置
+type fakeTarget struct {
+	mu  sync.Mutex
+	cfg *config.Config
+}
+
+func (f *fakeTarget) CurrentConfig() *config.Config {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.cfg
+}
+
+func (f *fakeTarget) SetConfig(cfg *config.Config) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cfg = cfg
+}
+
+// 生成配置文件内容
+func configContent(namespace string, cpuPercent string) string {
+	return `
+kubernetes:
+  namespace: ` + namespace + `
+  deploymentName: test-deployment
+  containerName: test-container
+  podName: test-pod
+thresholds:
+  cpuPercent: ` + cpuPercent + `
+`
+}
+
+// 写入配置文件
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("写入配置文件失败: %v", err)
+	}
+}
+
+// 创建配置文件、初始配置和监听器
+func newTestWatcher(t *testing.T) (string, *fakeTarget, *Watcher) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, configContent("test-namespace", "80"))
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("加载初始配置失败: %v", err)
+	}
+	target := &fakeTarget{cfg: cfg}
+	return path, target, NewWatcher(path, target)
+}
+
+func TestReload(t *testing.T) {
+	path, target, watcher := newTestWatcher(t)
+
+	// 有效的阈值变化会被应用
+	writeFile(t, path, configContent("test-namespace", "65"))
+	if err := watcher.Reload(); err != nil {
+		t.Fatalf("Reload()返回错误: %v", err)
+	}
+	if got := target.CurrentConfig().ResourceThresholdCPUPercent; got != 65 {
+		t.Errorf("ResourceThresholdCPUPercent = %f; 期望 65", got)
+	}
+
+	// 无效的配置被拒绝，保留上一次有效的配置
+	writeFile(t, path, configContent("test-namespace", "150"))
+	if err := watcher.Reload(); err == nil {
+		t.Error("Reload()对无效配置应返回错误")
+	}
+	if got := target.CurrentConfig().ResourceThresholdCPUPercent; got != 65 {
+		t.Errorf("ResourceThresholdCPUPercent = %f; 期望保留 65", got)
+	}
+
+	// 不支持热更新的字段不会被应用
+	writeFile(t, path, configContent("other-namespace", "65"))
+	if err := watcher.Reload(); err != nil {
+		t.Fatalf("Reload()返回错误: %v", err)
+	}
+	if got := target.CurrentConfig().Namespace; got != "test-namespace" {
+		t.Errorf("Namespace = %s; 期望保留 test-namespace", got)
+	}
+}
+
+func TestReloadEnvPinned(t *testing.T) {
+	t.Setenv("RESOURCE_THRESHOLD_CPU_PERCENT", "75")
+	path, target, watcher := newTestWatcher(t)
+
+	// 环境变量优先，配置文件中的修改不生效
+	writeFile(t, path, configContent("test-namespace", "65"))
+	if err := watcher.Reload(); err != nil {
+		t.Fatalf("Reload()返回错误: %v", err)
+	}
+	if got := target.CurrentConfig().ResourceThresholdCPUPercent; got != 75 {
+		t.Errorf("ResourceThresholdCPUPercent = %f; 期望保留环境变量的 75", got)
+	}
+
+	pinned := config.EnvPinned(config.LoadFile(path), config.LoadFile(path))
+	if len(pinned) != 0 {
+		t.Errorf("EnvPinned(相同配置) = %v; 期望为空", pinned)
+	}
+	old := config.LoadFile(path)
+	writeFile(t, path, configContent("test-namespace", "70"))
+	pinned = config.EnvPinned(old, config.LoadFile(path))
+	if len(pinned) != 1 || pinned[0] != "ResourceThresholdCPUPercent: 65 -> 70 (RESOURCE_THRESHOLD_CPU_PERCENT)" {
+		t.Errorf("EnvPinned = %v; 期望列出被RESOURCE_THRESHOLD_CPU_PERCENT覆盖的修改", pinned)
+	}
+}
+
+func TestRunAppliesFileChanges(t *testing.T) {
+	path, target, watcher := newTestWatcher(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.Run(ctx)
+
+	// 等待监听器启动后再修改文件
+	time.Sleep(100 * time.Millisecond)
+	writeFile(t, path, configContent("test-namespace", "70"))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if target.CurrentConfig().ResourceThresholdCPUPercent == 70 {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Errorf("ResourceThresholdCPUPercent = %f; 期望文件变化后热更新为 70", target.CurrentConfig().ResourceThresholdCPUPercent)
+}
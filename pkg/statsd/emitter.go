@@ -0,0 +1,241 @@
+package statsd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"metrics-sidecar/pkg/handlers"
+	"metrics-sidecar/pkg/i18n"
+	"metrics-sidecar/pkg/logger"
+)
+
+// 单个UDP包的最大长度，避免在常见的1500字节MTU下分片
+const maxPacketSize = 1432
+
+var (
+	// StatsD发送器的日志器
+	statsdLog = logger.GetLogger("statsd")
+)
+
+// Emitter 按间隔通过UDP以DogStatsD格式发送最近一次决策的ResourceMetrics快照和决策计数。
+// 决策在锁内同步通知，发送在Run的goroutine中完成
+type Emitter struct {
+	addr     string
+	prefix   string
+	interval time.Duration
+	tags     []string // 附加到所有指标的标签
+
+	mu          sync.Mutex
+	last        *handlers.Decision // 最近一次决策，尚未决策时为nil
+	decisions   map[string]int64   // 上次发送以来按状态统计的决策次数
+	randomDraws map[string]int64   // 上次发送以来按结果统计的随机退避次数
+}
+
+// NewEmitter 创建StatsD发送器，prefix会加在所有指标名称之前
+func NewEmitter(addr, prefix string, interval time.Duration, tags []string) *Emitter {
+	return &Emitter{
+		addr:        addr,
+		prefix:      prefix,
+		interval:    interval,
+		tags:        tags,
+		decisions:   make(map[string]int64),
+		randomDraws: make(map[string]int64),
+	}
+}
+
+// Listener 返回记录最近一次决策并累计决策次数的监听器
+func (e *Emitter) Listener() handlers.DecisionListener {
+	return func(_, current *handlers.Decision) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		e.last = current
+		e.decisions[current.Status]++
+		if current.RandomValue != nil {
+			result := "keep"
+			if current.Shedding() {
+				result = "shed"
+			}
+			e.randomDraws[result]++
+		}
+	}
+}
+
+// Run 按间隔发送指标，直到ctx结束后发送最后一次
+func (e *Emitter) Run(ctx context.Context) error {
+	conn, err := net.Dial("udp", e.addr)
+	if err != nil {
+		return i18n.Errorf("statsd.dial_failed", err)
+	}
+	defer conn.Close()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	statsdLog.WithField("addr", e.addr).Info(i18n.T("statsd.started"))
+	for {
+		select {
+		case <-ctx.Done():
+			e.flush(conn)
+			return nil
+		case <-ticker.C:
+			e.flush(conn)
+		}
+	}
+}
+
+// flush 发送一次指标，发送失败时丢弃本次的计数，避免接收端恢复后一次发送大量累积值
+func (e *Emitter) flush(conn net.Conn) {
+	for _, packet := range packets(e.lines()) {
+		if _, err := conn.Write(packet); err != nil {
+			statsdLog.WithError(err).Warn(i18n.T("statsd.send_failed"))
+			return
+		}
+	}
+}
+
+// lines 生成本次要发送的DogStatsD行，并清零决策计数
+func (e *Emitter) lines() []string {
+	e.mu.Lock()
+	decision := e.last
+	decisions, randomDraws := e.decisions, e.randomDraws
+	e.decisions, e.randomDraws = make(map[string]int64), make(map[string]int64)
+	e.mu.Unlock()
+
+	var lines []string
+	for _, status := range sortedKeys(decisions) {
+		lines = append(lines, e.line("decisions", decisions[status], "c", "status:"+status))
+	}
+	for _, result := range sortedKeys(randomDraws) {
+		lines = append(lines, e.line("random_draws", randomDraws[result], "c", "result:"+result))
+	}
+	if decision == nil {
+		return lines
+	}
+
+	m := decision.Metrics
+	cpuBasis, memBasis := "basis:"+m.ContainerCPUBasis, "basis:"+m.ContainerMemBasis
+	lines = append(lines,
+		e.line("container.cpu.limit_millicores", m.ContainerCPULimit, "g", cpuBasis),
+		e.line("container.memory.limit_mb", m.ContainerMemLimit, "g", memBasis),
+	)
+	// 获取Pod度量指标失败时使用量为0，不发送以免被误认为空闲
+	if m.MetricsError == "" {
+		lines = append(lines,
+			e.line("container.cpu.usage_millicores", m.ContainerCPUUsage, "g"),
+			e.line("container.cpu.percent", percent(m.ContainerCPUUsage, m.ContainerCPULimit), "g", cpuBasis),
+			e.line("container.memory.usage_mb", m.ContainerMemUsage, "g"),
+			e.line("container.memory.percent", percent(m.ContainerMemUsage, m.ContainerMemLimit), "g", memBasis),
+		)
+	}
+	lines = append(lines,
+		e.line("container.ready", boolValue(m.ContainerReady), "g"),
+		e.line("deployment.replicas", m.DeploymentReplicas, "g"),
+		e.line("deployment.available_replicas", m.DeploymentAvailableReplicas, "g"),
+	)
+	if m.DeploymentReplicas > 0 {
+		lines = append(lines, e.line("deployment.availability_percent",
+			percent(int64(m.DeploymentAvailableReplicas), int64(m.DeploymentReplicas)), "g"))
+	}
+
+	// 每次发送时当前状态为1，其他状态为0
+	for _, status := range handlers.AllStatuses {
+		lines = append(lines, e.line("decision.state", boolValue(status == decision.Status), "g", "status:"+status))
+	}
+	lines = append(lines,
+		e.line("decision.weight", decision.Weight, "g"),
+		e.line("decision.shedding", boolValue(decision.Shedding()), "g"),
+	)
+	return lines
+}
+
+// line 生成一行DogStatsD指标，如prefix.name:1|g|#tag:value
+func (e *Emitter) line(name string, value interface{}, metricType string, tags ...string) string {
+	var b strings.Builder
+	b.WriteString(e.prefix)
+	b.WriteString(name)
+	b.WriteByte(':')
+	switch v := value.(type) {
+	case float64:
+		b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		fmt.Fprint(&b, v)
+	}
+	b.WriteByte('|')
+	b.WriteString(metricType)
+
+	tags = append(append([]string(nil), e.tags...), tags...)
+	if len(tags) > 0 {
+		b.WriteString("|#")
+		b.WriteString(strings.Join(tags, ","))
+	}
+	return b.String()
+}
+
+// packets 将多行指标合并为不超过maxPacketSize的UDP包，行之间以换行分隔
+func packets(lines []string) [][]byte {
+	var result [][]byte
+	var current []byte
+	for _, line := range lines {
+		if len(current) > 0 && len(current)+1+len(line) > maxPacketSize {
+			result = append(result, current)
+			current = nil
+		}
+		if len(current) > 0 {
+			current = append(current, '\n')
+		}
+		current = append(current, line...)
+	}
+	if len(current) > 0 {
+		result = append(result, current)
+	}
+	return result
+}
+
+// WorkloadTags 返回描述工作负载的标签，使用Datadog的Kubernetes标签名，空值会被省略
+func WorkloadTags(namespace, deployment, pod, container string) []string {
+	var tags []string
+	for _, tag := range []struct{ key, value string }{
+		{"kube_namespace", namespace},
+		{"kube_deployment", deployment},
+		{"pod_name", pod},
+		{"kube_container_name", container},
+	} {
+		if tag.value != "" {
+			tags = append(tags, tag.key+":"+tag.value)
+		}
+	}
+	return tags
+}
+
+// percent 计算使用量占分母的百分比，分母无效时为0
+func percent(usage, limit int64) float64 {
+	if limit <= 0 {
+		return 0
+	}
+	return float64(usage) / float64(limit) * 100
+}
+
+// boolValue 将布尔值转换为0或1
+func boolValue(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// sortedKeys 按名称排序返回计数的键，使输出顺序稳定
+func sortedKeys(counts map[string]int64) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,109 @@
+package statsd
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"metrics-sidecar/pkg/handlers"
+	"metrics-sidecar/pkg/metrics"
+)
+
+func TestLines(t *testing.T) {
+	e := NewEmitter("127.0.0.1:8125", "sidecar.", time.Second, []string{"env:test"})
+	listener := e.Listener()
+
+	resourceMetrics := &metrics.ResourceMetrics{
+		DeploymentReplicas:          4,
+		DeploymentAvailableReplicas: 3,
+		ContainerReady:              true,
+		ContainerCPULimit:           2000,
+		ContainerCPUUsage:           1500,
+		ContainerCPUBasis:           "limit",
+		ContainerMemLimit:           1024,
+		ContainerMemUsage:           512,
+		ContainerMemBasis:           "request",
+	}
+	randomValue := 90.0
+	listener(nil, &handlers.Decision{Status: handlers.StatusHealthy, Weight: 100, Metrics: resourceMetrics})
+	listener(nil, &handlers.Decision{Status: handlers.StatusResourceExhausted, RandomValue: &randomValue, Metrics: resourceMetrics})
+	listener(nil, &handlers.Decision{Status: handlers.StatusResourceExhausted, Metrics: resourceMetrics})
+
+	lines := strings.Join(e.lines(), "\n")
+	expected := []string{
+		"sidecar.decisions:1|c|#env:test,status:HEALTHY",
+		"sidecar.decisions:2|c|#env:test,status:RESOURCE_EXHAUSTED",
+		"sidecar.random_draws:1|c|#env:test,result:shed",
+		"sidecar.container.cpu.usage_millicores:1500|g|#env:test",
+		"sidecar.container.cpu.limit_millicores:2000|g|#env:test,basis:limit",
+		"sidecar.container.cpu.percent:75|g|#env:test,basis:limit",
+		"sidecar.container.memory.percent:50|g|#env:test,basis:request",
+		"sidecar.container.ready:1|g|#env:test",
+		"sidecar.deployment.availability_percent:75|g|#env:test",
+		"sidecar.decision.state:1|g|#env:test,status:RESOURCE_EXHAUSTED",
+		"sidecar.decision.state:0|g|#env:test,status:HEALTHY",
+		"sidecar.decision.weight:0|g|#env:test",
+		"sidecar.decision.shedding:1|g|#env:test",
+	}
+	for _, line := range expected {
+		if !strings.Contains(lines+"\n", line+"\n") {
+			t.Errorf("缺少 %s:\n%s", line, lines)
+		}
+	}
+
+	// 计数发送后清零，仪表继续发送最近一次决策
+	lines = strings.Join(e.lines(), "\n")
+	if strings.Contains(lines, "sidecar.decisions:") {
+		t.Errorf("第二次发送不应包含已发送的计数:\n%s", lines)
+	}
+	if !strings.Contains(lines, "sidecar.decision.shedding:1|g") {
+		t.Errorf("第二次发送缺少仪表:\n%s", lines)
+	}
+}
+
+func TestPackets(t *testing.T) {
+	line := strings.Repeat("x", 500)
+	result := packets([]string{line, line, line})
+	if len(result) != 2 {
+		t.Fatalf("packets返回%d个包; 期望 2", len(result))
+	}
+	if string(result[0]) != line+"\n"+line {
+		t.Errorf("第一个包 = %q; 期望两行以换行分隔", result[0])
+	}
+	for _, packet := range result {
+		if len(packet) > maxPacketSize {
+			t.Errorf("包长度%d超过%d", len(packet), maxPacketSize)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听UDP失败: %v", err)
+	}
+	defer conn.Close()
+
+	e := NewEmitter(conn.LocalAddr().String(), "", time.Hour, WorkloadTags("default", "app", "app-1", "app"))
+	e.Listener()(nil, &handlers.Decision{Status: handlers.StatusHealthy, Weight: 100, Metrics: &metrics.ResourceMetrics{}})
+
+	// 发送间隔很长，结束时发送最后一次
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := e.Run(ctx); err != nil {
+		t.Fatalf("Run返回错误: %v", err)
+	}
+
+	buf := make([]byte, maxPacketSize)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("未收到指标: %v", err)
+	}
+	expected := "decisions:1|c|#kube_namespace:default,kube_deployment:app,pod_name:app-1,kube_container_name:app,status:HEALTHY"
+	if first := strings.Split(string(buf[:n]), "\n")[0]; first != expected {
+		t.Errorf("第一行 = %q; 期望 %q", first, expected)
+	}
+}
@@ -0,0 +1,201 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	clientmetrics "k8s.io/client-go/tools/metrics"
+
+	"metrics-sidecar/pkg/i18n"
+)
+
+// KubeClientMetrics Kubernetes API调用的客户端指标，包括GetDeploymentInfo、GetPodInfo、
+// GetPodMetrics等所有通过client-go发出的请求
+type KubeClientMetrics struct {
+	requestDuration *prometheus.HistogramVec
+	requests        *prometheus.CounterVec
+	requestErrors   *prometheus.CounterVec
+	rateLimiterWait *prometheus.HistogramVec
+	retries         *prometheus.CounterVec
+}
+
+// NewKubeClientMetrics 创建Kubernetes API调用指标并注册到registry
+func NewKubeClientMetrics(registry prometheus.Registerer) *KubeClientMetrics {
+	m := &KubeClientMetrics{
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "kube_client",
+			Name:      "request_duration_seconds",
+			Help:      i18n.T("metric.kube_request_duration"),
+			Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		}, []string{"verb", "resource"}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "kube_client",
+			Name:      "requests_total",
+			Help:      i18n.T("metric.kube_requests"),
+		}, []string{"verb", "resource", "code"}),
+		requestErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "kube_client",
+			Name:      "request_errors_total",
+			Help:      i18n.T("metric.kube_request_errors"),
+		}, []string{"verb", "resource", "code"}),
+		rateLimiterWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "kube_client",
+			Name:      "rate_limiter_wait_seconds",
+			Help:      i18n.T("metric.kube_rate_limiter_wait"),
+			Buckets:   []float64{0.001, 0.005, 0.025, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		}, []string{"verb", "resource"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "kube_client",
+			Name:      "request_retries_total",
+			Help:      i18n.T("metric.kube_retries"),
+		}, []string{"code", "method"}),
+	}
+	registry.MustRegister(m.requestDuration, m.requests, m.requestErrors, m.rateLimiterWait, m.retries)
+	return m
+}
+
+// RegisterClientGoHooks 通过client-go的指标钩子记录限流等待时间和重试次数。
+// client-go只接受第一次注册，进程内应只调用一次
+func (m *KubeClientMetrics) RegisterClientGoHooks() {
+	clientmetrics.Register(clientmetrics.RegisterOpts{
+		RateLimiterLatency: rateLimiterLatency{m},
+		RequestRetry:       requestRetry{m},
+	})
+}
+
+// WrapTransport 包装rest.Config的RoundTripper，记录每个请求的耗时和结果
+func (m *KubeClientMetrics) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &instrumentedTransport{next: rt, metrics: m}
+}
+
+// instrumentedTransport 记录请求指标的RoundTripper
+type instrumentedTransport struct {
+	next    http.RoundTripper
+	metrics *KubeClientMetrics
+}
+
+// RoundTrip 实现http.RoundTripper接口
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	verb, resource := RequestVerb(req.Method, req.URL), RequestResource(req.URL.Path)
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	// watch是长连接，RoundTrip在收到响应头后即返回，耗时没有参考意义
+	if verb != "watch" {
+		t.metrics.requestDuration.WithLabelValues(verb, resource).Observe(time.Since(start).Seconds())
+	}
+
+	code := "<error>"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	t.metrics.requests.WithLabelValues(verb, resource, code).Inc()
+	if err != nil || resp.StatusCode >= http.StatusBadRequest {
+		t.metrics.requestErrors.WithLabelValues(verb, resource, code).Inc()
+	}
+	return resp, err
+}
+
+// rateLimiterLatency 实现client-go的LatencyMetric，记录限流等待时间
+type rateLimiterLatency struct {
+	metrics *KubeClientMetrics
+}
+
+// Observe 实现clientmetrics.LatencyMetric接口
+func (r rateLimiterLatency) Observe(_ context.Context, verb string, u url.URL, latency time.Duration) {
+	r.metrics.rateLimiterWait.WithLabelValues(strings.ToLower(verb), RequestResource(u.Path)).Observe(latency.Seconds())
+}
+
+// requestRetry 实现client-go的RetryMetric，记录重试次数
+type requestRetry struct {
+	metrics *KubeClientMetrics
+}
+
+// IncrementRetry 实现clientmetrics.RetryMetric接口
+func (r requestRetry) IncrementRetry(_ context.Context, code, method, _ string) {
+	r.metrics.retries.WithLabelValues(code, method).Inc()
+}
+
+// RequestVerb 按Kubernetes的习惯将HTTP方法转换为动词，如带名称的GET为get，不带名称为list
+func RequestVerb(method string, u *url.URL) string {
+	switch method {
+	case http.MethodGet:
+		if u.Query().Get("watch") == "true" {
+			return "watch"
+		}
+		if _, name, _ := parsePath(u.Path); name == "" {
+			return "list"
+		}
+		return "get"
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut:
+		return "update"
+	case http.MethodPatch:
+		return "patch"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return strings.ToLower(method)
+	}
+}
+
+// RequestResource 从API路径中解析资源，非核心组的资源带上组名以区分同名资源，如
+// /api/v1/namespaces/default/pods/foo -> pods，
+// /apis/metrics.k8s.io/v1beta1/namespaces/default/pods/foo -> pods.metrics.k8s.io，
+// /api/v1/namespaces/default/pods/foo/status -> pods/status。无法解析时返回空字符串
+func RequestResource(path string) string {
+	resource, _, group := parsePath(path)
+	if resource == "" {
+		return ""
+	}
+	if group != "" {
+		// 子资源放在组名之后，如deployments.apps/status
+		if base, sub, found := strings.Cut(resource, "/"); found {
+			return base + "." + group + "/" + sub
+		}
+		return resource + "." + group
+	}
+	return resource
+}
+
+// parsePath 解析API路径，返回资源（含子资源）、对象名称和API组
+func parsePath(path string) (resource, name, group string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) >= 2 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 3 && parts[0] == "apis":
+		group = parts[1]
+		parts = parts[3:]
+	default:
+		return "", "", ""
+	}
+
+	// 命名空间内的资源，只有/namespaces/{name}时资源就是namespaces本身
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+	if len(parts) == 0 {
+		return "", "", group
+	}
+
+	resource = parts[0]
+	if len(parts) >= 2 {
+		name = parts[1]
+	}
+	if len(parts) >= 3 {
+		resource += "/" + parts[2]
+	}
+	return resource, name, group
+}
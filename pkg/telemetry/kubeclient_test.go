@@ -0,0 +1,88 @@
+package telemetry
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRequestResource(t *testing.T) {
+	tests := map[string]string{
+		"/api/v1/namespaces/default/pods/app-1":                       "pods",
+		"/api/v1/namespaces/default/pods/app-1/status":                "pods/status",
+		"/apis/apps/v1/namespaces/default/deployments/app":            "deployments.apps",
+		"/apis/metrics.k8s.io/v1beta1/namespaces/default/pods/app-1":  "pods.metrics.k8s.io",
+		"/apis/metrics.k8s.io/v1beta1/nodes":                          "nodes.metrics.k8s.io",
+		"/api/v1/nodes/node-1":                                        "nodes",
+		"/api/v1/namespaces/default":                                  "namespaces",
+		"/apis/discovery.k8s.io/v1/namespaces/default/endpointslices": "endpointslices.discovery.k8s.io",
+		"/version": "",
+	}
+	for path, expected := range tests {
+		if got := RequestResource(path); got != expected {
+			t.Errorf("RequestResource(%s) = %q; 期望 %q", path, got, expected)
+		}
+	}
+}
+
+func TestRequestVerb(t *testing.T) {
+	tests := []struct {
+		method, url, expected string
+	}{
+		{http.MethodGet, "/api/v1/namespaces/default/pods/app-1", "get"},
+		{http.MethodGet, "/api/v1/namespaces/default/pods?labelSelector=app", "list"},
+		{http.MethodGet, "/api/v1/namespaces/default/pods?watch=true", "watch"},
+		{http.MethodPatch, "/api/v1/namespaces/default/pods/app-1/status", "patch"},
+		{http.MethodPost, "/api/v1/namespaces/default/events", "create"},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		if got := RequestVerb(tt.method, u); got != tt.expected {
+			t.Errorf("RequestVerb(%s %s) = %s; 期望 %s", tt.method, tt.url, got, tt.expected)
+		}
+	}
+}
+
+// roundTripFunc 用函数实现http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestInstrumentedTransport(t *testing.T) {
+	m := NewKubeClientMetrics(prometheus.NewRegistry())
+	rt := m.WrapTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/apis/apps/v1/namespaces/default/deployments/app" {
+			return &http.Response{StatusCode: http.StatusNotFound}, nil
+		}
+		if req.URL.Path == "/api/v1/namespaces/default/pods/app-1" {
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		}
+		return nil, errors.New("connection refused")
+	}))
+
+	for _, path := range []string{
+		"/api/v1/namespaces/default/pods/app-1",
+		"/apis/apps/v1/namespaces/default/deployments/app",
+		"/apis/metrics.k8s.io/v1beta1/nodes",
+	} {
+		rt.RoundTrip(httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("get", "pods", "200")); got != 1 {
+		t.Errorf("成功请求计数 = %v; 期望 1", got)
+	}
+	if got := testutil.ToFloat64(m.requestErrors.WithLabelValues("get", "deployments.apps", "404")); got != 1 {
+		t.Errorf("404错误计数 = %v; 期望 1", got)
+	}
+	if got := testutil.ToFloat64(m.requestErrors.WithLabelValues("list", "nodes.metrics.k8s.io", "<error>")); got != 1 {
+		t.Errorf("网络错误计数 = %v; 期望 1", got)
+	}
+	if got := testutil.CollectAndCount(m.requestDuration); got != 3 {
+		t.Errorf("耗时直方图序列数 = %d; 期望 3", got)
+	}
+}
@@ -0,0 +1,54 @@
+package telemetry
+
+import (
+	"net/url"
+	"path"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+
+	"metrics-sidecar/pkg/i18n"
+)
+
+// ServiceName 上报给OpenTelemetry的服务名
+const ServiceName = "metrics-sidecar"
+
+// ResourceAttributes 描述sidecar所在工作负载的资源属性，遵循Kubernetes语义约定
+type ResourceAttributes struct {
+	Namespace  string
+	Deployment string
+	Pod        string
+	Container  string // 被监控的目标容器
+}
+
+// NewResource 创建带服务名和Kubernetes资源属性的OpenTelemetry资源，空值的属性会被省略
+func NewResource(attrs ResourceAttributes) *resource.Resource {
+	kvs := []attribute.KeyValue{semconv.ServiceName(ServiceName)}
+	if attrs.Namespace != "" {
+		kvs = append(kvs, semconv.K8SNamespaceName(attrs.Namespace))
+	}
+	if attrs.Deployment != "" {
+		kvs = append(kvs, semconv.K8SDeploymentName(attrs.Deployment))
+	}
+	if attrs.Pod != "" {
+		kvs = append(kvs, semconv.K8SPodName(attrs.Pod))
+	}
+	if attrs.Container != "" {
+		kvs = append(kvs, semconv.K8SContainerName(attrs.Container))
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, kvs...)
+}
+
+// otlpEndpoint 将http(s)://host:port[/prefix]形式的接收端地址拆分为OTLP/HTTP导出器的选项，
+// signal为信号路径，如v1/traces
+func otlpEndpoint(endpoint, signal string) (host, urlPath string, insecure bool, err error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", "", false, i18n.Errorf("telemetry.endpoint_invalid", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", "", false, i18n.Errorf("telemetry.endpoint_scheme", endpoint)
+	}
+	return u.Host, path.Join("/", u.Path, signal), u.Scheme == "http", nil
+}
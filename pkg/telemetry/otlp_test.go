@@ -0,0 +1,75 @@
+package telemetry
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+// collectorStub 进程内的OTLP/HTTP接收端，按路径保存收到的请求体
+type collectorStub struct {
+	mu     sync.Mutex
+	bodies map[string][][]byte
+}
+
+func (c *collectorStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	if c.bodies == nil {
+		c.bodies = make(map[string][][]byte)
+	}
+	c.bodies[r.URL.Path] = append(c.bodies[r.URL.Path], body)
+	c.mu.Unlock()
+
+	// 空的protobuf响应表示全部接收
+	w.Header().Set("Content-Type", "application/x-protobuf")
+}
+
+// received 返回该路径收到的所有请求体
+func (c *collectorStub) received(path string) [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bodies[path]
+}
+
+// resourceAttribute 返回资源中字符串属性的值
+func resourceAttribute(res *resourcepb.Resource, key string) string {
+	for _, kv := range res.Attributes {
+		if kv.Key == key {
+			return kv.Value.GetStringValue()
+		}
+	}
+	return ""
+}
+
+func TestOTLPEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint, host, path string
+		insecure             bool
+	}{
+		{"http://otel-collector:4318", "otel-collector:4318", "/v1/traces", true},
+		{"https://otel.example.com/otlp/", "otel.example.com", "/otlp/v1/traces", false},
+	}
+	for _, tt := range tests {
+		host, path, insecure, err := otlpEndpoint(tt.endpoint, "v1/traces")
+		if err != nil {
+			t.Errorf("otlpEndpoint(%s)返回错误: %v", tt.endpoint, err)
+			continue
+		}
+		if host != tt.host || path != tt.path || insecure != tt.insecure {
+			t.Errorf("otlpEndpoint(%s) = %s, %s, %v; 期望 %s, %s, %v", tt.endpoint, host, path, insecure, tt.host, tt.path, tt.insecure)
+		}
+	}
+
+	if _, _, _, err := otlpEndpoint("otel-collector:4318", "v1/traces"); err == nil {
+		t.Error("otlpEndpoint(otel-collector:4318)应返回错误")
+	}
+}
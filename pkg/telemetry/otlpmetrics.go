@@ -0,0 +1,38 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"metrics-sidecar/pkg/i18n"
+)
+
+// SetupMetrics 创建按interval通过OTLP/HTTP推送指标的MeterProvider，
+// 供没有Prometheus、只有OpenTelemetry Collector的集群使用。退出前应调用Shutdown推送最后一次
+func SetupMetrics(ctx context.Context, endpoint string, interval time.Duration, res *resource.Resource) (*sdkmetric.MeterProvider, error) {
+	host, urlPath, insecure, err := otlpEndpoint(endpoint, "v1/metrics")
+	if err != nil {
+		return nil, err
+	}
+
+	options := []otlpmetrichttp.Option{
+		otlpmetrichttp.WithEndpoint(host),
+		otlpmetrichttp.WithURLPath(urlPath),
+	}
+	if insecure {
+		options = append(options, otlpmetrichttp.WithInsecure())
+	}
+	exporter, err := otlpmetrichttp.New(ctx, options...)
+	if err != nil {
+		return nil, i18n.Errorf("telemetry.metric_exporter_failed", err)
+	}
+
+	return sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(interval))),
+		sdkmetric.WithResource(res),
+	), nil
+}
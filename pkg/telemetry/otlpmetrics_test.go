@@ -0,0 +1,68 @@
+package telemetry
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSetupMetrics(t *testing.T) {
+	stub := &collectorStub{}
+	collector := httptest.NewServer(stub)
+	defer collector.Close()
+
+	res := NewResource(ResourceAttributes{Namespace: "default", Deployment: "app", Pod: "app-1", Container: "app"})
+	provider, err := SetupMetrics(context.Background(), collector.URL, time.Hour, res)
+	if err != nil {
+		t.Fatalf("SetupMetrics返回错误: %v", err)
+	}
+
+	gauge, err := provider.Meter("test").Int64ObservableGauge("metrics_sidecar.container.ready")
+	if err != nil {
+		t.Fatalf("创建仪表失败: %v", err)
+	}
+	if _, err := provider.Meter("test").RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(gauge, 1)
+		return nil
+	}, gauge); err != nil {
+		t.Fatalf("注册回调失败: %v", err)
+	}
+
+	// 推送间隔很长，关闭时推送最后一次
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("推送指标失败: %v", err)
+	}
+
+	bodies := stub.received("/v1/metrics")
+	if len(bodies) != 1 {
+		t.Fatalf("收到%d次推送; 期望 1", len(bodies))
+	}
+	request := &collectormetrics.ExportMetricsServiceRequest{}
+	if err := proto.Unmarshal(bodies[0], request); err != nil {
+		t.Fatalf("解析推送的指标失败: %v", err)
+	}
+
+	resourceMetrics := request.ResourceMetrics[0]
+	expected := map[string]string{
+		"service.name":        ServiceName,
+		"k8s.namespace.name":  "default",
+		"k8s.deployment.name": "app",
+		"k8s.pod.name":        "app-1",
+		"k8s.container.name":  "app",
+	}
+	for key, value := range expected {
+		if got := resourceAttribute(resourceMetrics.Resource, key); got != value {
+			t.Errorf("资源属性%s = %q; 期望 %q", key, got, value)
+		}
+	}
+
+	m := resourceMetrics.ScopeMetrics[0].Metrics[0]
+	if m.Name != "metrics_sidecar.container.ready" || m.GetGauge().DataPoints[0].GetAsInt() != 1 {
+		t.Errorf("推送的指标 = %v; 期望 metrics_sidecar.container.ready=1", m)
+	}
+}
@@ -0,0 +1,83 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"metrics-sidecar/pkg/i18n"
+)
+
+// 追踪HTTP请求的instrumentation名称
+const httpTracerName = "metrics-sidecar/pkg/telemetry"
+
+// SetupTracing 创建通过OTLP/HTTP导出span的TracerProvider并设为全局，同时启用W3C Trace Context传播。
+// 上游请求已采样时始终采样，否则按sampleRatio采样。返回的函数用于退出前导出剩余的span
+func SetupTracing(ctx context.Context, endpoint string, sampleRatio float64, res *resource.Resource) (func(context.Context) error, error) {
+	host, urlPath, insecure, err := otlpEndpoint(endpoint, "v1/traces")
+	if err != nil {
+		return nil, err
+	}
+
+	options := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(host),
+		otlptracehttp.WithURLPath(urlPath),
+	}
+	if insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, i18n.Errorf("telemetry.trace_exporter_failed", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// TraceHandler 为每个请求创建一个server span，作为该请求中Kubernetes API调用和决策span的父span。
+// 未启用追踪时全局TracerProvider为空实现，开销可以忽略
+func TraceHandler(route string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := otel.Tracer(httpTracerName).Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(r.URL.Path),
+			))
+		defer span.End()
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler.ServeHTTP(recorder, r.WithContext(ctx))
+
+		// 卸载流量时/healthz按设计返回503，不视为span错误，收集指标失败由处理器记录
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+	})
+}
+
+// statusRecorder 记录处理器写入的状态码
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader 记录状态码后写入
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
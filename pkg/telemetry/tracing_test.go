@@ -0,0 +1,84 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTraceHandler(t *testing.T) {
+	stub := &collectorStub{}
+	collector := httptest.NewServer(stub)
+	defer collector.Close()
+
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	res := NewResource(ResourceAttributes{Namespace: "default", Deployment: "app", Pod: "app-1", Container: "app"})
+	shutdown, err := SetupTracing(context.Background(), collector.URL+"/otlp", 1, res)
+	if err != nil {
+		t.Fatalf("SetupTracing返回错误: %v", err)
+	}
+
+	// 处理器中的子span应挂在请求的server span下
+	handler := TraceHandler("/healthz", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, span := otel.Tracer("test").Start(r.Context(), "collectResourceMetrics")
+		span.End()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("导出span失败: %v", err)
+	}
+
+	spans := make(map[string]*tracepb.Span)
+	var requests []*collectortrace.ExportTraceServiceRequest
+	for _, body := range stub.received("/otlp/v1/traces") {
+		request := &collectortrace.ExportTraceServiceRequest{}
+		if err := proto.Unmarshal(body, request); err != nil {
+			t.Fatalf("解析导出的span失败: %v", err)
+		}
+		requests = append(requests, request)
+		for _, resourceSpans := range request.ResourceSpans {
+			for _, scopeSpans := range resourceSpans.ScopeSpans {
+				for _, span := range scopeSpans.Spans {
+					spans[span.Name] = span
+				}
+			}
+		}
+	}
+	root, child := spans["GET /healthz"], spans["collectResourceMetrics"]
+	if root == nil || child == nil {
+		t.Fatalf("收到的span = %v; 期望包含GET /healthz和collectResourceMetrics", spans)
+	}
+	if root.Kind != tracepb.Span_SPAN_KIND_SERVER {
+		t.Errorf("根span类型 = %v; 期望 SERVER", root.Kind)
+	}
+	if len(root.ParentSpanId) != 0 {
+		t.Errorf("根span的父span = %x; 期望为空", root.ParentSpanId)
+	}
+	if !bytes.Equal(child.ParentSpanId, root.SpanId) || !bytes.Equal(child.TraceId, root.TraceId) {
+		t.Error("collectResourceMetrics应为GET /healthz的子span")
+	}
+
+	attrs := make(map[string]int64)
+	for _, kv := range root.Attributes {
+		attrs[kv.Key] = kv.Value.GetIntValue()
+	}
+	if attrs["http.response.status_code"] != http.StatusServiceUnavailable {
+		t.Errorf("http.response.status_code = %d; 期望 503", attrs["http.response.status_code"])
+	}
+
+	if pod := resourceAttribute(requests[0].ResourceSpans[0].Resource, "k8s.pod.name"); pod != "app-1" {
+		t.Errorf("资源属性k8s.pod.name = %q; 期望 app-1", pod)
+	}
+}